@@ -31,14 +31,27 @@ import (
 func (c *Context) Applications(rw web.ResponseWriter, req *web.Request) {
 	applications := []models.Application{}
 
-	dataList, err := c.repository.GetListOfData(c.getApplicationKey(), models.Application{})
+	projection, err := getFieldProjection(req, models.Application{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	filter := commonHttp.CreateItemFilter(req)
+
+	// name filter is applied in memory, so Name has to be read even if it is not returned
+	readProjection := projection
+	if filter.Name != "" {
+		readProjection = nil
+	}
+
+	dataList, err := c.getListOfDataWithProjection(c.getApplicationKey(), models.Application{}, readProjection)
 	if err != nil {
 		err = fmt.Errorf("application list retrieval failed: %v", err)
 		commonHttp.HandleError(rw, err)
 		return
 	}
 
-	filter := commonHttp.CreateItemFilter(req)
 	applications, err = utils.ApplyApplicationFilter(dataList, filter)
 	if err != nil {
 		err = fmt.Errorf("application filter failed: %v", err)
@@ -46,7 +59,29 @@ func (c *Context) Applications(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	commonHttp.WriteJsonOrError(rw, applications, http.StatusOK, err)
+	writeProjectedJsonOrError(rw, projection, applications, http.StatusOK, err)
+}
+
+func (c *Context) getApplications() ([]models.Application, error) {
+	result := []models.Application{}
+	entities, err := c.repository.GetListOfData(c.getApplicationKey(), models.Application{})
+	if err != nil {
+		err = fmt.Errorf("applications retrieval failed: %v", err)
+		logger.Warning(err)
+		return []models.Application{}, err
+	}
+
+	for _, entity := range entities {
+		application, ok := entity.(models.Application)
+		if !ok {
+			err = fmt.Errorf("type assertion for application failed: object from database: %v", entity)
+			logger.Error(err)
+			return []models.Application{}, err
+		}
+		result = append(result, application)
+	}
+
+	return result, nil
 }
 
 func (c *Context) getApplication(id string) (models.Application, error) {
@@ -70,8 +105,20 @@ func (c *Context) getApplication(id string) (models.Application, error) {
 func (c *Context) GetApplication(rw web.ResponseWriter, req *web.Request) {
 	applicationId := req.PathParams["applicationId"]
 
-	app, err := c.getApplication(applicationId)
-	commonHttp.WriteJsonOrError(rw, app, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Application{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if projection == nil {
+		app, err := c.getApplication(applicationId)
+		commonHttp.WriteJsonOrError(rw, app, http.StatusOK, err)
+		return
+	}
+
+	app, err := c.getDataWithProjection(c.buildApplicationKey(applicationId), models.Application{}, projection)
+	writeProjectedJsonOrError(rw, projection, app, http.StatusOK, err)
 }
 
 func (c *Context) AddApplication(rw web.ResponseWriter, req *web.Request) {
@@ -89,6 +136,11 @@ func (c *Context) AddApplication(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if status, err := c.validateTemplateReference(rw, reqApplication.TemplateId); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
 	exists, err := c.repository.IsExistByName(reqApplication.Name, models.Application{}, c.getApplicationKey())
 	if err != nil {
 		commonHttp.Respond500(rw, err)
@@ -99,6 +151,11 @@ func (c *Context) AddApplication(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqApplication)
+		return
+	}
+
 	if reqApplication.Id, err = c.reserveID(c.getApplicationKey()); err != nil {
 		commonHttp.Respond500(rw, err)
 		return
@@ -110,12 +167,24 @@ func (c *Context) AddApplication(rw web.ResponseWriter, req *web.Request) {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypeApplication, reqApplication.Id, "", reqApplication)
 
 	application, err := c.repository.GetData(c.buildApplicationKey(reqApplication.Id), models.Application{})
+	if err == nil {
+		c.recordRevision(models.EntityTypeApplication, reqApplication.Id, "", application)
+	}
 	commonHttp.WriteJsonOrError(rw, application, http.StatusCreated, err)
 }
 
 func (c *Context) PatchApplication(rw web.ResponseWriter, req *web.Request) {
+	c.updateApplication(rw, req, c.readPatches)
+}
+
+func (c *Context) PutApplication(rw web.ResponseWriter, req *web.Request) {
+	c.updateApplication(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updateApplication(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	applicationId := req.PathParams["applicationId"]
 	application, err := c.repository.GetData(c.buildApplicationKey(applicationId), models.Application{})
 	if err != nil {
@@ -123,8 +192,7 @@ func (c *Context) PatchApplication(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, application)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
@@ -136,20 +204,78 @@ func (c *Context) PatchApplication(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if status, err := c.assureNoNewDependencyCycleByPatches(application, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if status, err := c.validateTemplateReferenceByPatches(rw, application, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, application, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordUpdateAudit(models.EntityTypeApplication, applicationId, "", application, patches)
 
 	application, err = c.repository.GetData(c.buildApplicationKey(applicationId), models.Application{})
+	if err == nil {
+		c.recordRevision(models.EntityTypeApplication, applicationId, "", application)
+	}
 	commonHttp.WriteJsonOrError(rw, application, http.StatusOK, err)
 }
 
 func (c *Context) DeleteApplication(rw web.ResponseWriter, req *web.Request) {
 	applicationId := req.PathParams["applicationId"]
-	err := c.repository.DeleteData(c.buildApplicationKey(applicationId))
-	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
+
+	if isCascade(req) {
+		c.cascadeDelete(rw, req, models.EntityTypeApplication, applicationId)
+		return
+	}
+
+	if status, err := c.assureApplicationIsNotUsed(applicationId); err != nil {
+		err := fmt.Errorf("cannot remove application %q: %v", applicationId, err)
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.buildApplicationKey(applicationId), models.Application{})
+		return
+	}
+
+	c.deleteOrMoveToTrash(rw, req, "applications", applicationId)
+}
+
+// Application has no State - state of its application instance is monitored instead
+func (c *Context) MonitorApplicationsStates(rw web.ResponseWriter, req *web.Request) {
+	c.monitorInstancesStatesOfClass(rw, req, models.InstanceTypeApplication, "")
+}
+
+func (c *Context) MonitorSpecificApplicationState(rw web.ResponseWriter, req *web.Request) {
+	applicationId := req.PathParams["applicationId"]
+	if _, err := c.repository.GetData(c.buildApplicationKey(applicationId), models.Application{}); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	c.monitorInstancesStatesOfClass(rw, req, models.InstanceTypeApplication, applicationId)
+}
+
+func (c *Context) MonitorApplicationsChanges(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificChange(rw, req, c.getApplicationKey())
+}
+
+func (c *Context) MonitorSpecificApplicationChange(rw web.ResponseWriter, req *web.Request) {
+	applicationId := req.PathParams["applicationId"]
+	c.monitorSpecificChange(rw, req, c.buildApplicationKey(applicationId))
 }
 
 func (c *Context) getApplicationKey() string {
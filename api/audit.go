@@ -0,0 +1,161 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	auditRetentionEnv     = "AUDIT_RETENTION"
+	defaultAuditRetention = 90 * 24 * time.Hour
+)
+
+// Audit returns entries of organization audit log, the oldest first
+func (c *Context) Audit(rw web.ResponseWriter, req *web.Request) {
+	filter, err := getAuditFilter(req)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	entries, err := c.repository.GetAuditEntries(c.getAuditKey(), filter)
+	if err != nil {
+		if !commonHttp.IsNotFoundError(err) {
+			commonHttp.HandleError(rw, err)
+			return
+		}
+		entries = []models.AuditEntry{}
+	}
+	commonHttp.WriteJson(rw, entries, http.StatusOK)
+}
+
+func getAuditFilter(req *web.Request) (models.AuditFilter, error) {
+	query := req.URL.Query()
+	filter := models.AuditFilter{EntityId: query.Get("entityId"), User: query.Get("user")}
+
+	if entityType := query.Get("entityType"); entityType != "" {
+		expectedType, err := models.ParseEntityType(entityType)
+		if err != nil {
+			return filter, err
+		}
+		filter.EntityType = expectedType
+	}
+
+	var err error
+	if filter.Since, err = parseAuditTime(query.Get("since")); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseAuditTime(query.Get("until")); err != nil {
+		return filter, err
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			return filter, fmt.Errorf("limit %q must match positive integer", value)
+		}
+	}
+	return filter, nil
+}
+
+// parseAuditTime converts RFC 3339 time to unix nanoseconds, empty value means no limit
+func parseAuditTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must match RFC 3339 format, e.g. 2017-01-02T15:04:05Z", value)
+	}
+	return parsed.UnixNano(), nil
+}
+
+func (c *Context) recordCreateAudit(entityType models.EntityType, id, parentId string, entity interface{}) {
+	c.recordAudit(models.ChangeEventTypeCreated, entityType, id, parentId, c.mapper.Username, nil, entity)
+}
+
+// recordUpdateAudit records changes made by patches which were already applied to the entity in etcd,
+// user given in patches takes precedence over the one of request
+func (c *Context) recordUpdateAudit(entityType models.EntityType, id, parentId string, entity interface{}, patches []models.Patch) {
+	patched, err := c.mapper.PreviewPatches(entity, patches)
+	if err != nil {
+		logger.Errorf("cannot resolve audit changes of %s %q: %v", entityType, id, err)
+		return
+	}
+
+	user := c.mapper.Username
+	for _, patch := range patches {
+		if patch.Username != "" {
+			user = patch.Username
+		}
+	}
+	c.recordAudit(models.ChangeEventTypeUpdated, entityType, id, parentId, user, entity, patched)
+}
+
+// recordDeleteAudit records removal of entity - entity can be nil if it was not retrieved before removal
+func (c *Context) recordDeleteAudit(entityType models.EntityType, id, parentId string, entity interface{}) {
+	c.recordAudit(models.ChangeEventTypeDeleted, entityType, id, parentId, c.mapper.Username, entity, nil)
+}
+
+// recordAudit appends change to audit log of organization. Change is already written at this point,
+// so failure is only logged.
+func (c *Context) recordAudit(action models.ChangeEventType, entityType models.EntityType, id, parentId, user string,
+	before, after interface{}) {
+
+	changes, err := models.DiffEntities(before, after)
+	if err != nil {
+		logger.Errorf("cannot resolve audit changes of %s %q: %v", entityType, id, err)
+		return
+	}
+
+	entry := models.AuditEntry{
+		Timestamp:  time.Now().UnixNano(),
+		User:       user,
+		OnBehalfOf: c.actingUser,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   id,
+		ParentId:   parentId,
+		Changes:    changes,
+	}
+	if err := c.repository.AddAuditEntry(c.getAuditKey(), entry, getAuditRetention()); err != nil {
+		logger.Errorf("cannot add %s of %s %q to audit log: %v", action, entityType, id, err)
+	}
+}
+
+func getAuditRetention() time.Duration {
+	retention, err := time.ParseDuration(os.Getenv(auditRetentionEnv))
+	if err != nil || retention <= 0 {
+		return defaultAuditRetention
+	}
+	return retention
+}
+
+func (c *Context) getAuditKey() string {
+	return data.GetEntityKey(c.organization, data.Audit)
+}
@@ -0,0 +1,171 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestAudit(t *testing.T) {
+	Convey("Testing audit log", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareStrictMocksAndClient(t)
+
+		Convey("When audit log is requested with filter, matching entries should be returned", func() {
+			since := time.Date(2017, 1, 2, 15, 4, 5, 6, time.UTC).UnixNano()
+			filter := models.AuditFilter{EntityType: models.EntityTypeInstance, EntityId: sampleID1, User: "admin", Since: since, Limit: 10}
+			entries := []models.AuditEntry{{
+				Timestamp: since + 1, User: "admin", Action: models.ChangeEventTypeDeleted,
+				EntityType: models.EntityTypeInstance, EntityId: sampleID1, Changes: []models.FieldChange{},
+			}}
+			mocks.repositoryMock.EXPECT().GetAuditEntries(context.getAuditKey(), filter).Return(entries, nil)
+
+			result, status, err := catalogClient.GetAuditLog(filter)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldResemble, entries)
+		})
+
+		Convey("When audit log is empty, empty list should be returned", func() {
+			mocks.repositoryMock.EXPECT().GetAuditEntries(context.getAuditKey(), models.AuditFilter{}).Return(
+				nil, errors.New("Key not found"))
+
+			result, status, err := catalogClient.GetAuditLog(models.AuditFilter{})
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldBeEmpty)
+		})
+
+		Convey("When time range is not RFC 3339 time, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/audit?since=yesterday", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "RFC 3339")
+		})
+
+		Convey("When limit is not positive integer, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/audit?limit=0", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "positive integer")
+		})
+
+		Convey("When instance is patched, changed fields should be recorded with secrets redacted", func() {
+			instance := models.Instance{Id: sampleID1, Name: sampleName1, Metadata: []models.Metadata{{Id: "DB_PASSWORD", Value: "old"}}}
+			var entry models.AuditEntry
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().AddAuditEntry(context.getAuditKey(), gomock.Any(), defaultAuditRetention).Do(
+					func(key string, added models.AuditEntry, ttl time.Duration) { entry = added }).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+			)
+
+			body := []byte(`[{"op":"Add","field":"metadata","value":{"key":"DB_PASSWORD","value":"new"},"username":"admin"}]`)
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/instances/"+sampleID1, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(entry.Action, ShouldEqual, models.ChangeEventTypeUpdated)
+			So(entry.EntityType, ShouldEqual, models.EntityTypeInstance)
+			So(entry.EntityId, ShouldEqual, sampleID1)
+			So(entry.User, ShouldEqual, "admin")
+			So(entry.Changes, ShouldHaveLength, 1)
+			So(entry.Changes[0].Field, ShouldEqual, "metadata")
+			So(string(entry.Changes[0].OldValue), ShouldNotContainSubstring, "old")
+			So(string(entry.Changes[0].NewValue), ShouldNotContainSubstring, "new")
+			So(string(entry.Changes[0].NewValue), ShouldContainSubstring, models.RedactedValue)
+		})
+
+		Convey("When template is removed permanently on behalf of other user, deletion should be recorded", func() {
+			var entry models.AuditEntry
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(
+					models.Template{Id: sampleID1, State: models.TemplateStateReady}, nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().AddAuditEntry(context.getAuditKey(), gomock.Any(), defaultAuditRetention).Do(
+					func(key string, added models.AuditEntry, ttl time.Duration) { entry = added }).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteStateHistory(context.buildStateHistoryKey(models.EntityTypeTemplate, sampleID1)).Return(nil),
+			)
+
+			header := http.Header{}
+			header.Set(models.ActingUserHeader, "admin")
+			rr := sendAuthorizedRequestWithHeaders(context, "DELETE", "/api/v1/templates/"+sampleID1+"?purge=true", nil, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+			So(entry.Action, ShouldEqual, models.ChangeEventTypeDeleted)
+			So(entry.EntityType, ShouldEqual, models.EntityTypeTemplate)
+			So(entry.User, ShouldEqual, testUser)
+			So(entry.OnBehalfOf, ShouldEqual, "admin")
+			So(string(getFieldChange(entry, "state").OldValue), ShouldEqual, `"READY"`)
+		})
+
+		Convey("When instance is removed, its old values should be recorded", func() {
+			var entry models.AuditEntry
+			instance := models.Instance{Id: sampleID1, Name: sampleName1, State: models.InstanceStateStopped}
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{}, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildInstanceKey(sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().AddAuditEntry(context.getAuditKey(), gomock.Any(), defaultAuditRetention).Do(
+					func(key string, added models.AuditEntry, ttl time.Duration) { entry = added }).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteStateHistory(context.buildStateHistoryKey(models.EntityTypeInstance, sampleID1)).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/instances/"+sampleID1, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+			So(entry.Action, ShouldEqual, models.ChangeEventTypeDeleted)
+			So(string(getFieldChange(entry, "name").OldValue), ShouldEqual, `"`+sampleName1+`"`)
+		})
+
+		Convey("When removed instance does not exist, response status should be NotFound and nothing should be recorded", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(nil, errors.New("Key not found"))
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/instances/"+sampleID1, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+// getFieldChange returns change of given field - FieldChange holds raw JSON, so it can not be compared as a whole
+func getFieldChange(entry models.AuditEntry, field string) models.FieldChange {
+	for _, change := range entry.Changes {
+		if change.Field == field {
+			return change
+		}
+	}
+	return models.FieldChange{}
+}
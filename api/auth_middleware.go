@@ -21,6 +21,7 @@ import (
 
 	"github.com/gocraft/web"
 
+	"github.com/trustedanalytics-ng/tap-catalog/models"
 	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 )
 
@@ -31,5 +32,6 @@ func (c *Context) BasicAuthorizeMiddleware(rw web.ResponseWriter, req *web.Reque
 		return
 	}
 	c.mapper.Username = username
+	c.actingUser = req.Header.Get(models.ActingUserHeader)
 	next(rw, req)
 }
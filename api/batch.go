@@ -0,0 +1,338 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const batchApiPrefix = "/api/v1"
+
+// batchResource describes collection addressed by batch operation path - it is needed to find created ids
+// and to snapshot objects, so that all-or-nothing batch can be rolled back
+type batchResource struct {
+	dirKey     string
+	model      interface{}
+	entityType models.EntityType
+	parentId   string
+}
+
+type executedBatchOperation struct {
+	resultIndex int
+	operation   models.BatchOperation
+	resource    batchResource
+	key         string
+	snapshot    interface{}
+}
+
+// Batch executes ordered list of operations through regular API handlers, so every operation is validated
+// the same way as a single request. In ALL_OR_NOTHING mode first failure rolls back already executed operations.
+// Dry run is passed to every operation - ids of objects created in dry run are empty, so references to them
+// can not be resolved.
+func (c *Context) Batch(rw web.ResponseWriter, req *web.Request) {
+	batch := models.BatchRequest{}
+	if err := commonHttp.ReadJson(req, &batch); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if err := batch.ValidateBatchRequest(); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	for i, operation := range batch.Operations {
+		if _, _, err := c.getBatchResource(operation); err != nil {
+			commonHttp.Respond400(rw, fmt.Errorf("operation %d: %v", i, err))
+			return
+		}
+	}
+
+	router := SetupRouter(*c)
+	response := models.BatchResponse{Mode: batch.Mode, Success: true, Results: []models.BatchOperationResult{}}
+	createdIds := map[string]string{}
+	executed := []executedBatchOperation{}
+
+	for i, operation := range batch.Operations {
+		if !response.Success && batch.Mode == models.BatchModeAllOrNothing {
+			response.Results = append(response.Results, models.BatchOperationResult{
+				Ref:    operation.Ref,
+				Status: http.StatusFailedDependency,
+				Error:  "operation not executed: previous operation failed",
+			})
+			continue
+		}
+
+		result, executedOperation := c.executeBatchOperation(router, req, operation, createdIds, batch.Mode)
+		response.Results = append(response.Results, result)
+		if result.Error != "" {
+			response.Success = false
+			if batch.Mode == models.BatchModeAllOrNothing && !isDryRun(req) {
+				c.rollbackBatch(executed, response.Results)
+			}
+			logger.Warningf("batch operation %d (%s %s) failed: %s", i, operation.Op, operation.Path, result.Error)
+			continue
+		}
+
+		executedOperation.resultIndex = i
+		executed = append(executed, executedOperation)
+		if operation.Ref != "" {
+			createdIds[operation.Ref] = result.Id
+		}
+	}
+	commonHttp.WriteJson(rw, response, http.StatusOK)
+}
+
+func (c *Context) executeBatchOperation(router *web.Router, req *web.Request, operation models.BatchOperation,
+	createdIds map[string]string, mode models.BatchMode) (models.BatchOperationResult, executedBatchOperation) {
+
+	result := models.BatchOperationResult{Ref: operation.Ref}
+	executed := executedBatchOperation{}
+
+	path, err := resolveBatchReferences(operation.Path, createdIds)
+	if err == nil {
+		operation.Path = path
+		var body string
+		body, err = resolveBatchReferences(string(operation.Body), createdIds)
+		operation.Body = json.RawMessage(body)
+	}
+	if err != nil {
+		result.Status = http.StatusBadRequest
+		result.Error = err.Error()
+		return result, executed
+	}
+
+	resource, id, _ := c.getBatchResource(operation)
+	executed = executedBatchOperation{operation: operation, resource: resource}
+	if id != "" {
+		executed.key = c.mapper.ToKey(resource.dirKey, id)
+		if mode == models.BatchModeAllOrNothing {
+			if executed.snapshot, err = c.repository.GetData(executed.key, resource.model); err != nil {
+				result.Status = getHttpStatusOrStatusError(http.StatusOK, err)
+				result.Error = err.Error()
+				return result, executed
+			}
+		}
+	}
+
+	status, body := dispatchBatchOperation(router, req, operation)
+	result.Status = status
+	if status >= http.StatusBadRequest {
+		message := commonHttp.MessageResponse{}
+		if json.Unmarshal(body, &message) != nil || message.Message == "" {
+			message.Message = strings.TrimSpace(string(body))
+		}
+		result.Error = message.Message
+		if result.Error == "" {
+			result.Error = http.StatusText(status)
+		}
+		return result, executed
+	}
+
+	if len(body) > 0 {
+		result.Body = json.RawMessage(body)
+	}
+	result.Id = id
+	if operation.Op == models.BatchOperationCreate {
+		result.Id = getCreatedEntityId(body, resource.model)
+		executed.key = c.mapper.ToKey(resource.dirKey, result.Id)
+	}
+	return result, executed
+}
+
+func dispatchBatchOperation(router *web.Router, req *web.Request, operation models.BatchOperation) (int, []byte) {
+	method := map[models.BatchOperationType]string{
+		models.BatchOperationCreate: "POST",
+		models.BatchOperationPatch:  "PATCH",
+		models.BatchOperationDelete: "DELETE",
+	}[operation.Op]
+
+	operationPath := batchApiPrefix + operation.Path
+	if isDryRun(req) {
+		operationPath += "?" + dryRunQueryParam + "=true"
+	}
+
+	operationReq, err := http.NewRequest(method, operationPath, bytes.NewReader(operation.Body))
+	if err != nil {
+		return http.StatusBadRequest, []byte(err.Error())
+	}
+	operationReq.Header.Set("Authorization", req.Header.Get("Authorization"))
+	operationReq.Header.Set(models.ActingUserHeader, req.Header.Get(models.ActingUserHeader))
+	operationReq.Header.Set("Content-Type", "application/json")
+
+	recorder := newBatchResponseRecorder()
+	router.ServeHTTP(recorder, operationReq)
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	return recorder.status, recorder.body.Bytes()
+}
+
+// rollbackBatch reverts executed operations in reverse order: created objects are purged,
+// patched and deleted ones are restored from snapshots taken before the operation. Reverts go through the same
+// helpers as regular deletes and trash restores, so they are recorded in audit log and revisions.
+func (c *Context) rollbackBatch(executed []executedBatchOperation, results []models.BatchOperationResult) {
+	for i := len(executed) - 1; i >= 0; i-- {
+		operation := executed[i]
+		if err := c.rollbackBatchOperation(operation); err != nil {
+			logger.Errorf("cannot rollback batch operation %s %s: %v", operation.operation.Op, operation.operation.Path, err)
+			continue
+		}
+		results[operation.resultIndex].RolledBack = true
+	}
+}
+
+func (c *Context) rollbackBatchOperation(operation executedBatchOperation) error {
+	resource := operation.resource
+	id := path.Base(operation.key)
+
+	if operation.operation.Op == models.BatchOperationDelete {
+		if _, err := c.restoreEntity(resource.dirKey, resource.model, resource.entityType, id, resource.parentId,
+			operation.snapshot); err != nil {
+			return err
+		}
+		c.recordCreateAudit(resource.entityType, id, resource.parentId, operation.snapshot)
+		c.removeFromTrash(resource.dirKey, id)
+		return nil
+	}
+
+	// object as left by the operation is the old value of the revert
+	current, err := c.repository.GetData(operation.key, resource.model)
+	if err != nil {
+		return err
+	}
+	if operation.operation.Op == models.BatchOperationCreate {
+		return c.purgeEntity(operation.key, resource.entityType, id, resource.parentId, current)
+	}
+
+	if err := c.repository.DeleteData(operation.key); err != nil {
+		return err
+	}
+	if _, err := c.restoreEntity(resource.dirKey, resource.model, resource.entityType, id, resource.parentId,
+		operation.snapshot); err != nil {
+		return err
+	}
+	c.recordAudit(models.ChangeEventTypeUpdated, resource.entityType, id, resource.parentId, c.mapper.Username,
+		current, operation.snapshot)
+	return nil
+}
+
+// getBatchResource returns collection of the operation path and id of the object for PATCH and DELETE operations
+func (c *Context) getBatchResource(operation models.BatchOperation) (batchResource, string, error) {
+	segments := strings.Split(strings.Trim(operation.Path, "/"), "/")
+	id := ""
+	if operation.Op != models.BatchOperationCreate {
+		if len(segments) < 2 {
+			return batchResource{}, "", fmt.Errorf("path %q must match object path", operation.Path)
+		}
+		id = segments[len(segments)-1]
+		segments = segments[:len(segments)-1]
+	}
+
+	switch {
+	case len(segments) == 1 && segments[0] == "services":
+		return batchResource{dirKey: c.getServiceKey(), model: models.Service{}, entityType: models.EntityTypeService}, id, nil
+	case len(segments) == 3 && segments[0] == "services" && segments[2] == "plans":
+		return batchResource{dirKey: c.getServicePlansDir(segments[1]), model: models.ServicePlan{},
+			entityType: models.EntityTypePlan, parentId: segments[1]}, id, nil
+	case len(segments) == 1 && segments[0] == "instances",
+		len(segments) == 3 && (segments[0] == "services" || segments[0] == "applications") && segments[2] == "instances":
+		return batchResource{dirKey: c.getInstanceKey(), model: models.Instance{}, entityType: models.EntityTypeInstance}, id, nil
+	case len(segments) == 1 && segments[0] == "applications":
+		return batchResource{dirKey: c.getApplicationKey(), model: models.Application{}, entityType: models.EntityTypeApplication}, id, nil
+	case len(segments) == 1 && segments[0] == "templates":
+		return batchResource{dirKey: c.getTemplateKey(), model: models.Template{}, entityType: models.EntityTypeTemplate}, id, nil
+	case len(segments) == 1 && segments[0] == "images":
+		return batchResource{dirKey: c.getImagesKey(), model: models.Image{}, entityType: models.EntityTypeImage}, id, nil
+	}
+	return batchResource{}, "", fmt.Errorf("%s operation is not supported for path %q", operation.Op, operation.Path)
+}
+
+// resolveBatchReferences replaces ${ref} placeholders with ids created by earlier operations.
+// Text which has to contain "${" literally escapes it as "$${"
+func resolveBatchReferences(value string, createdIds map[string]string) (string, error) {
+	resolved, rest := "", value
+	for {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			return resolved + rest, nil
+		}
+		if start > 0 && rest[start-1] == '$' {
+			resolved += rest[:start-1] + "${"
+			rest = rest[start+2:]
+			continue
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return value, fmt.Errorf("reference in %q is not closed", value)
+		}
+
+		ref := rest[start+2 : start+end]
+		id, ok := createdIds[ref]
+		if !ok {
+			return value, fmt.Errorf("reference %q must match ref of earlier successful operation", ref)
+		}
+		resolved += rest[:start] + id
+		rest = rest[start+end+1:]
+	}
+}
+
+func getCreatedEntityId(body []byte, model interface{}) string {
+	entity := reflect.New(reflect.TypeOf(model))
+	if err := json.Unmarshal(body, entity.Interface()); err != nil {
+		logger.Warningf("cannot read id of created %T: %v", model, err)
+		return ""
+	}
+	return entity.Elem().FieldByName("Id").String()
+}
+
+type batchResponseRecorder struct {
+	header http.Header
+	status int
+	body   *bytes.Buffer
+}
+
+func newBatchResponseRecorder() *batchResponseRecorder {
+	return &batchResponseRecorder{header: http.Header{}, body: &bytes.Buffer{}}
+}
+
+func (r *batchResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchResponseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *batchResponseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
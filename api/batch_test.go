@@ -0,0 +1,190 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestBatch(t *testing.T) {
+	Convey("Testing Batch", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+		template := models.Template{Id: sampleID1, State: models.TemplateStateInProgress}
+
+		batch := models.BatchRequest{
+			Operations: []models.BatchOperation{
+				{Ref: "template", Op: models.BatchOperationCreate, Path: "/templates", Body: []byte(`{}`)},
+				{Op: models.BatchOperationDelete, Path: "/templates/${template}"},
+			},
+		}
+
+		Convey("When all operations succeed, results should contain created id", func() {
+			batch.Mode = models.BatchModeBestEffort
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().CreateDir(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(gomock.Any(), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1)), gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+			)
+
+			response, status, err := catalogClient.Batch(batch)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(response.Success, ShouldBeTrue)
+			So(response.Results, ShouldHaveLength, 2)
+			So(response.Results[0].Status, ShouldEqual, http.StatusCreated)
+			So(response.Results[0].Id, ShouldEqual, sampleID1)
+			So(response.Results[1].Status, ShouldEqual, http.StatusNoContent)
+		})
+
+		Convey("When operation fails in all-or-nothing mode, executed operations should be rolled back", func() {
+			batch.Mode = models.BatchModeAllOrNothing
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().CreateDir(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(gomock.Any(), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1)), gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(errors.New("connection refused")),
+				mocks.repositoryMock.EXPECT().DeleteTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1))).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+			)
+
+			response, status, err := catalogClient.Batch(batch)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(response.Success, ShouldBeFalse)
+			So(response.Results[0].RolledBack, ShouldBeTrue)
+			So(response.Results[1].Status, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("When operation after delete fails in all-or-nothing mode, deleted object should be restored", func() {
+			batch.Mode = models.BatchModeAllOrNothing
+			batch.Operations = []models.BatchOperation{
+				{Op: models.BatchOperationDelete, Path: "/templates/" + sampleID1},
+				{Op: models.BatchOperationDelete, Path: "/templates/" + sampleID2},
+			}
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1)), gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID2), models.Template{}).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().GetTrashEntry(context.buildTrashKey(data.Templates, sampleID1)).Return(
+					context.buildTrashKey(data.Templates, sampleID1)+"/1", models.TrashEntry{}, nil),
+				mocks.repositoryMock.EXPECT().DeleteTrashEntry(context.buildTrashKey(data.Templates, sampleID1)+"/1").Return(nil),
+			)
+
+			response, status, err := catalogClient.Batch(batch)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(response.Success, ShouldBeFalse)
+			So(response.Results[0].RolledBack, ShouldBeTrue)
+			So(response.Results[1].Status, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When patched object cannot be removed during rollback, it should not be recreated", func() {
+			operation := executedBatchOperation{
+				operation: models.BatchOperation{Op: models.BatchOperationPatch, Path: "/templates/" + sampleID1},
+				resource:  batchResource{dirKey: context.getTemplateKey(), model: models.Template{}, entityType: models.EntityTypeTemplate},
+				key:       context.buildTemplateKey(sampleID1),
+				snapshot:  template,
+			}
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(errors.New("connection refused")),
+			)
+
+			So(context.rollbackBatchOperation(operation), ShouldNotBeNil)
+		})
+
+		Convey("When reference is unknown, response status is 400", func() {
+			batch.Operations[1].Path = "/templates/${unknown}"
+			batch.Operations = batch.Operations[1:]
+
+			response, status, err := catalogClient.Batch(batch)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(response.Success, ShouldBeFalse)
+			So(response.Results[0].Status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When path is not supported, response status is 400", func() {
+			batch.Operations[0].Path = "/batch"
+
+			_, status, err := catalogClient.Batch(batch)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestResolveBatchReferences(t *testing.T) {
+	Convey("Testing resolveBatchReferences", t, func() {
+		createdIds := map[string]string{"app": sampleID1}
+
+		Convey("When value refers to created id, it should be replaced", func() {
+			resolved, err := resolveBatchReferences("/applications/${app}/instances", createdIds)
+
+			So(err, ShouldBeNil)
+			So(resolved, ShouldEqual, "/applications/"+sampleID1+"/instances")
+		})
+
+		Convey("When value contains escaped placeholder, it should be kept literally", func() {
+			resolved, err := resolveBatchReferences(`{"id":"${app}","command":"echo $${HOME}"}`, createdIds)
+
+			So(err, ShouldBeNil)
+			So(resolved, ShouldEqual, `{"id":"`+sampleID1+`","command":"echo ${HOME}"}`)
+		})
+
+		Convey("When value contains not escaped unknown placeholder, error should be returned", func() {
+			_, err := resolveBatchReferences(`{"command":"echo ${HOME}"}`, createdIds)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "must match")
+		})
+	})
+}
@@ -0,0 +1,214 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func (c *Context) AddInstanceBinding(rw web.ResponseWriter, req *web.Request) {
+	instanceID := req.PathParams["instanceId"]
+
+	instance, err := c.getInstance(instanceID)
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	binding := models.InstanceBindings{}
+	if err = commonHttp.ReadJson(req, &binding); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if err = binding.ValidateInstanceBindingStruct(); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if findBinding(instance, binding.Id) >= 0 {
+		commonHttp.Respond409(rw, fmt.Errorf("binding of instance %q to %q already exists", instanceID, binding.Id))
+		return
+	}
+
+	if status, err := c.validateBindingTarget(instanceID, binding.Id); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	boundInstance := instance
+	boundInstance.Bindings = append(append([]models.InstanceBindings{}, instance.Bindings...), binding)
+	if status, err := c.assureNoNewDependencyCycle(boundInstance); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	c.patchInstanceBindings(rw, req, instance, models.OperationAdd, binding, http.StatusCreated)
+}
+
+func (c *Context) DeleteInstanceBinding(rw web.ResponseWriter, req *web.Request) {
+	instanceID := req.PathParams["instanceId"]
+	targetID := req.PathParams["targetId"]
+
+	instance, err := c.getInstance(instanceID)
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	index := findBinding(instance, targetID)
+	if index < 0 {
+		commonHttp.Respond404(rw, fmt.Errorf("binding of instance %q to %q not found", instanceID, targetID))
+		return
+	}
+
+	c.patchInstanceBindings(rw, req, instance, models.OperationDelete, instance.Bindings[index], http.StatusNoContent)
+}
+
+// GetInstanceBoundBy returns instances which have binding to given instance
+func (c *Context) GetInstanceBoundBy(rw web.ResponseWriter, req *web.Request) {
+	instanceID := req.PathParams["instanceId"]
+
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if _, err = c.getInstance(instanceID); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	instances, err := c.getInstances()
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	result := []models.Instance{}
+	for _, instance := range instances {
+		if findBinding(instance, instanceID) >= 0 {
+			result = append(result, instance)
+		}
+	}
+	commonHttp.WriteJson(rw, projection.Apply(result), http.StatusOK)
+}
+
+// validateBindingTarget checks that bound instance exists and, for offering instances, that the offering is bindable
+func (c *Context) validateBindingTarget(instanceID, targetID string) (int, error) {
+	if instanceID == targetID {
+		return http.StatusBadRequest, fmt.Errorf("instance %q can not be bound to itself", instanceID)
+	}
+
+	target, err := c.getInstance(targetID)
+	if err != nil {
+		if commonHttp.IsNotFoundError(err) {
+			return http.StatusBadRequest, fmt.Errorf("bound instance %q does not exist", targetID)
+		}
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+
+	if target.Type == models.InstanceTypeService || target.Type == models.InstanceTypeServiceBroker {
+		service, err := c.getService(target.ClassId)
+		if err != nil {
+			return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+		}
+		if !service.Bindable {
+			return http.StatusBadRequest, fmt.Errorf("offering %q of instance %q is not bindable", service.Name, target.Name)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// validateBindingPatches applies binding rules to generic instance patches adding bindings.
+// Field name is case insensitive for mapper, so it is normalized the same way before comparison.
+func (c *Context) validateBindingPatches(instanceID string, patches []models.Patch) (int, error) {
+	for _, patch := range patches {
+		if patch.Operation != models.OperationAdd || patch.Field == nil || strings.Title(*patch.Field) != data.Bindings || patch.Value == nil {
+			continue
+		}
+
+		binding := models.InstanceBindings{}
+		if err := json.Unmarshal(*patch.Value, &binding); err != nil {
+			return http.StatusBadRequest, err
+		}
+		if err := binding.ValidateInstanceBindingStruct(); err != nil {
+			return http.StatusBadRequest, err
+		}
+		if status, err := c.validateBindingTarget(instanceID, binding.Id); err != nil {
+			return status, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func (c *Context) patchInstanceBindings(rw web.ResponseWriter, req *web.Request, instance models.Instance,
+	operation models.PatchOperation, binding models.InstanceBindings, status int) {
+
+	value, err := json.Marshal(binding)
+	if err != nil {
+		commonHttp.Respond500(rw, err)
+		return
+	}
+
+	field := data.Bindings
+	rawValue := json.RawMessage(value)
+	patches := []models.Patch{{Operation: operation, Field: &field, Value: &rawValue, Username: c.mapper.Username}}
+
+	patchedValues, err := c.mapper.ToKeyValueByPatches(c.buildInstanceKey(instance.Id), models.Instance{}, patches)
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, instance, patches)
+		return
+	}
+
+	if err = c.repository.ApplyPatchedValues(patchedValues); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	c.recordUpdateAudit(models.EntityTypeInstance, instance.Id, "", instance, patches)
+
+	if status == http.StatusNoContent {
+		commonHttp.WriteJson(rw, "", status)
+		return
+	}
+
+	instanceInt, err := c.repository.GetData(c.buildInstanceKey(instance.Id), models.Instance{})
+	commonHttp.WriteJsonOrError(rw, instanceInt, status, err)
+}
+
+func findBinding(instance models.Instance, targetID string) int {
+	for i, binding := range instance.Bindings {
+		if binding.Id == targetID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestInstanceBindings(t *testing.T) {
+	Convey("Testing instance bindings subresource", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+		instance := models.Instance{Id: sampleID1, Name: sampleName1, Type: models.InstanceTypeApplication}
+		target := models.Instance{Id: sampleID2, Name: sampleName2, Type: models.InstanceTypeService, ClassId: serviceId}
+		binding := models.InstanceBindings{Id: sampleID2, Data: map[string]string{"KEY": "value"}}
+
+		Convey("When target offering is bindable, binding should be added", func() {
+			boundInstance := instance
+			boundInstance.Bindings = []models.InstanceBindings{binding}
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{instance, target}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID2), models.Instance{}).Return(target, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(serviceId), models.Service{}).Return(models.Service{Bindable: true}, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(boundInstance, nil),
+			)
+
+			result, status, err := catalogClient.AddInstanceBinding(sampleID1, binding)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusCreated)
+			So(result.Bindings, ShouldResemble, boundInstance.Bindings)
+		})
+
+		Convey("When target offering is not bindable, response status should be BadRequest", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID2), models.Instance{}).Return(target, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(serviceId), models.Service{}).Return(models.Service{Bindable: false}, nil),
+			)
+
+			_, status, err := catalogClient.AddInstanceBinding(sampleID1, binding)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			So(err.Error(), ShouldContainSubstring, "is not bindable")
+		})
+
+		Convey("When target does not exist, response status should be BadRequest", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID2), models.Instance{}).Return(nil, errors.New("Key not found")),
+			)
+
+			_, status, err := catalogClient.AddInstanceBinding(sampleID1, binding)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When instance is bound to itself, response status should be BadRequest", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil)
+
+			_, status, err := catalogClient.AddInstanceBinding(sampleID1, models.InstanceBindings{Id: sampleID1})
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			So(err.Error(), ShouldContainSubstring, "can not be bound to itself")
+		})
+
+		Convey("When instance is bound to itself by patch with lowercase field name, response status should be BadRequest", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil)
+			field := "bindings"
+			value := json.RawMessage(`{"id":"` + sampleID1 + `"}`)
+
+			_, status, err := catalogClient.UpdateInstance(sampleID1, []models.Patch{{Operation: models.OperationAdd, Field: &field, Value: &value}})
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			So(err.Error(), ShouldContainSubstring, "can not be bound to itself")
+		})
+
+		Convey("When binding does not exist, delete should return NotFound", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil)
+
+			status, err := catalogClient.DeleteInstanceBinding(sampleID1, sampleID2)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When binding exists, it should be deleted", func() {
+			instance.Bindings = []models.InstanceBindings{binding}
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(instance, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+			)
+
+			status, err := catalogClient.DeleteInstanceBinding(sampleID1, sampleID2)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusNoContent)
+		})
+
+		Convey("When bound-by is requested, instances bound to given one should be returned", func() {
+			instance.Bindings = []models.InstanceBindings{binding}
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID2), models.Instance{}).Return(target, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{instance, target}, nil),
+			)
+
+			result, status, err := catalogClient.GetInstanceBoundBy(sampleID2)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldHaveLength, 1)
+			So(result[0].Id, ShouldEqual, sampleID1)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,385 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	cascadeQueryParam = "cascade"
+	cascadeExecute    = "true"
+	cascadePreview    = "preview"
+)
+
+var cascadeResourceNames = map[models.EntityType]string{
+	models.EntityTypeApplication: "applications",
+	models.EntityTypeService:     "services",
+	models.EntityTypeTemplate:    "templates",
+	models.EntityTypeImage:       "images",
+}
+
+func isCascade(req *web.Request) bool {
+	mode := req.URL.Query().Get(cascadeQueryParam)
+	return mode != "" && mode != "false"
+}
+
+// cascadeDelete removes object together with everything what depends on it. In preview mode
+// nothing is removed - steps which would be taken are returned instead
+func (c *Context) cascadeDelete(rw web.ResponseWriter, req *web.Request, entityType models.EntityType, id string) {
+	mode := req.URL.Query().Get(cascadeQueryParam)
+	if mode != cascadeExecute && mode != cascadePreview {
+		commonHttp.Respond400(rw, fmt.Errorf("%s parameter has to be one of: %s, %s", cascadeQueryParam, cascadeExecute, cascadePreview))
+		return
+	}
+
+	deletion, err := c.newCascadeDeletion()
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	if err = deletion.addRoot(entityType, id); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	items, err := deletion.plan()
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	if isPurge(req) {
+		markAsPurged(items)
+	}
+
+	if mode == cascadePreview || isDryRun(req) {
+		commonHttp.WriteJson(rw, items, http.StatusOK)
+		return
+	}
+
+	for i, item := range items {
+		if err = c.removeCascadeItem(item); err != nil {
+			err = fmt.Errorf("cascade delete stopped on %s of %s %q after %d of %d steps: %v",
+				item.Action, item.EntityType, item.Id, i, len(items), err)
+			commonHttp.GenericRespond(getHttpStatusOrStatusError(http.StatusInternalServerError, err), rw, err)
+			return
+		}
+	}
+	commonHttp.WriteJson(rw, items, http.StatusOK)
+}
+
+func (c *Context) removeCascadeItem(item models.CascadeDeleteItem) error {
+	if item.Action == models.CascadeActionUnbind {
+		return c.unbindCascadeItem(item)
+	}
+
+	if item.EntityType == models.EntityTypeInstance {
+		instance, err := c.repository.GetData(c.buildInstanceKey(item.Id), models.Instance{})
+		if err != nil {
+			return err
+		}
+		return c.purgeEntity(c.buildInstanceKey(item.Id), models.EntityTypeInstance, item.Id, "", instance)
+	}
+	return c.deleteEntity(cascadeResourceNames[item.EntityType], item.Id, item.Purge)
+}
+
+func markAsPurged(items []models.CascadeDeleteItem) {
+	for i := range items {
+		if items[i].Action == models.CascadeActionDelete {
+			items[i].Purge = true
+		}
+	}
+}
+
+// unbindCascadeItem removes single binding of the instance. Instance after the change is the loaded one without
+// the binding, so that audit log shows only the binding as changed.
+func (c *Context) unbindCascadeItem(item models.CascadeDeleteItem) error {
+	instanceInt, err := c.repository.GetData(c.buildInstanceKey(item.Id), models.Instance{})
+	if err != nil {
+		return err
+	}
+	instance, ok := instanceInt.(models.Instance)
+	if !ok {
+		return fmt.Errorf("type assertion for instance %q failed: object from database: %v", item.Id, instanceInt)
+	}
+
+	bindingsKey := c.mapper.ToKey(c.buildInstanceKey(item.Id), data.Bindings)
+	if err := c.repository.DeleteData(c.mapper.ToKey(bindingsKey, item.BindingId)); err != nil {
+		return err
+	}
+
+	unbound := instance
+	unbound.Bindings = []models.InstanceBindings{}
+	for _, binding := range instance.Bindings {
+		if binding.Id != item.BindingId {
+			unbound.Bindings = append(unbound.Bindings, binding)
+		}
+	}
+	c.recordAudit(models.ChangeEventTypeUpdated, models.EntityTypeInstance, item.Id, "", c.mapper.Username, instance, unbound)
+	return nil
+}
+
+// cascadeDeletion collects objects which depend on deleted one. Dependents are visited first,
+// so that every object is removed before the objects it refers to
+type cascadeDeletion struct {
+	c            *Context
+	instances    []models.Instance
+	services     []models.Service
+	applications []models.Application
+
+	visited         map[models.EntityType]map[string]bool
+	instanceItems   []models.CascadeDeleteItem
+	offeringItems   []models.CascadeDeleteItem
+	templateItems   []models.CascadeDeleteItem
+	imageCandidates []string
+}
+
+func (c *Context) newCascadeDeletion() (*cascadeDeletion, error) {
+	instances, err := c.getInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := c.getServices()
+	if err != nil {
+		return nil, err
+	}
+
+	applications, err := c.getApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cascadeDeletion{
+		c:            c,
+		instances:    instances,
+		services:     services,
+		applications: applications,
+		visited:      make(map[models.EntityType]map[string]bool),
+	}, nil
+}
+
+func (d *cascadeDeletion) addRoot(entityType models.EntityType, id string) error {
+	switch entityType {
+	case models.EntityTypeApplication:
+		application, err := d.c.getApplication(id)
+		if err != nil {
+			return err
+		}
+		d.addApplication(application)
+	case models.EntityTypeService:
+		service, err := d.c.getService(id)
+		if err != nil {
+			return err
+		}
+		d.addService(service)
+	case models.EntityTypeTemplate:
+		templateInt, err := d.c.repository.GetData(d.c.buildTemplateKey(id), models.Template{})
+		if err != nil {
+			return err
+		}
+		template, ok := templateInt.(models.Template)
+		if !ok {
+			return fmt.Errorf("type assertion for template %q failed: object from database: %v", id, templateInt)
+		}
+		d.addTemplate(template)
+	default:
+		return fmt.Errorf("cascade delete is not supported for %s", entityType)
+	}
+	return nil
+}
+
+func (d *cascadeDeletion) visit(entityType models.EntityType, id string) bool {
+	if d.visited[entityType] == nil {
+		d.visited[entityType] = make(map[string]bool)
+	}
+	if d.visited[entityType][id] {
+		return false
+	}
+	d.visited[entityType][id] = true
+	return true
+}
+
+func (d *cascadeDeletion) isVisited(entityType models.EntityType, id string) bool {
+	return d.visited[entityType][id]
+}
+
+func (d *cascadeDeletion) addTemplate(template models.Template) {
+	if !d.visit(models.EntityTypeTemplate, template.Id) {
+		return
+	}
+
+	for _, service := range d.services {
+		if service.TemplateId == template.Id {
+			d.addService(service)
+		}
+	}
+	for _, application := range d.applications {
+		if application.TemplateId == template.Id {
+			d.addApplication(application)
+		}
+	}
+	d.templateItems = append(d.templateItems, newCascadeDeleteItem(models.EntityTypeTemplate, template.Id, ""))
+}
+
+func (d *cascadeDeletion) addService(service models.Service) {
+	if !d.visit(models.EntityTypeService, service.Id) {
+		return
+	}
+
+	for _, dependent := range d.services {
+		if dependent.Id != service.Id && isServiceDependentOn(dependent, service.Id) {
+			d.addService(dependent)
+		}
+	}
+	d.addInstancesOfClass(service.Id)
+	d.offeringItems = append(d.offeringItems, newCascadeDeleteItem(models.EntityTypeService, service.Id, service.Name))
+	d.imageCandidates = append(d.imageCandidates, models.ConstructImageIdForUserOffering(service.Id))
+}
+
+func (d *cascadeDeletion) addApplication(application models.Application) {
+	if !d.visit(models.EntityTypeApplication, application.Id) {
+		return
+	}
+
+	d.addInstancesOfClass(application.Id)
+	d.offeringItems = append(d.offeringItems, newCascadeDeleteItem(models.EntityTypeApplication, application.Id, application.Name))
+	if application.ImageId != "" {
+		d.imageCandidates = append(d.imageCandidates, application.ImageId)
+	}
+}
+
+// addInstancesOfClass marks instances as purged - there is no trash for instances, so they are always removed permanently
+func (d *cascadeDeletion) addInstancesOfClass(classId string) {
+	for _, instance := range d.instances {
+		if instance.ClassId == classId && d.visit(models.EntityTypeInstance, instance.Id) {
+			item := newCascadeDeleteItem(models.EntityTypeInstance, instance.Id, instance.Name)
+			item.Purge = true
+			d.instanceItems = append(d.instanceItems, item)
+		}
+	}
+}
+
+// plan returns steps in order: bindings to removed instances are dropped from instances which stay,
+// then instances, offerings, images no longer referenced by anything and templates are removed
+func (d *cascadeDeletion) plan() ([]models.CascadeDeleteItem, error) {
+	imageItems, err := d.getUnreferencedImageItems()
+	if err != nil {
+		return nil, err
+	}
+
+	items := d.getUnbindItems()
+	items = append(items, d.instanceItems...)
+	items = append(items, d.offeringItems...)
+	items = append(items, imageItems...)
+	items = append(items, d.templateItems...)
+	return items, nil
+}
+
+func (d *cascadeDeletion) getUnbindItems() []models.CascadeDeleteItem {
+	items := []models.CascadeDeleteItem{}
+	for _, instance := range d.instances {
+		if d.isVisited(models.EntityTypeInstance, instance.Id) {
+			continue
+		}
+		for _, binding := range instance.Bindings {
+			if d.isVisited(models.EntityTypeInstance, binding.Id) {
+				item := newCascadeDeleteItem(models.EntityTypeInstance, instance.Id, instance.Name)
+				item.Action = models.CascadeActionUnbind
+				item.BindingId = binding.Id
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// getUnreferencedImageItems uses the same references discovery as GetImageCheckRefs - image is removed
+// only if every application and service using it is removed as well
+func (d *cascadeDeletion) getUnreferencedImageItems() ([]models.CascadeDeleteItem, error) {
+	items := []models.CascadeDeleteItem{}
+	for _, imageId := range d.imageCandidates {
+		if d.isVisited(models.EntityTypeImage, imageId) {
+			continue
+		}
+
+		applicationRefs, err := d.c.applicationImageRefs(imageId)
+		if err != nil {
+			return nil, err
+		}
+		serviceRefs, err := d.c.servicesImageRefs(imageId)
+		if err != nil {
+			return nil, err
+		}
+		if !d.areAllRemoved(applicationRefs, serviceRefs) {
+			logger.Infof("image %q is still referenced, it will not be removed by cascade delete", imageId)
+			continue
+		}
+
+		if _, err := d.c.repository.GetData(d.c.buildImagesKey(imageId), models.Image{}); err != nil {
+			if commonHttp.IsNotFoundError(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		d.visit(models.EntityTypeImage, imageId)
+		items = append(items, newCascadeDeleteItem(models.EntityTypeImage, imageId, ""))
+	}
+	return items, nil
+}
+
+func (d *cascadeDeletion) areAllRemoved(applications []models.Application, services []models.Service) bool {
+	for _, application := range applications {
+		if !d.isVisited(models.EntityTypeApplication, application.Id) {
+			return false
+		}
+	}
+	for _, service := range services {
+		if !d.isVisited(models.EntityTypeService, service.Id) {
+			return false
+		}
+	}
+	return true
+}
+
+func isServiceDependentOn(service models.Service, serviceId string) bool {
+	for _, plan := range service.Plans {
+		for _, dependency := range plan.Dependencies {
+			if dependency.ServiceId == serviceId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newCascadeDeleteItem(entityType models.EntityType, id, name string) models.CascadeDeleteItem {
+	return models.CascadeDeleteItem{
+		Action:     models.CascadeActionDelete,
+		EntityType: entityType,
+		Id:         id,
+		Name:       name,
+	}
+}
@@ -0,0 +1,162 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestCascadeDelete(t *testing.T) {
+	Convey("Testing cascade delete", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+
+		template := models.Template{Id: "template-1", State: models.TemplateStateReady}
+		service := models.Service{Id: "service-1", Name: "service", TemplateId: template.Id}
+		dependentService := models.Service{Id: "service-2", Name: "dependent", Plans: []models.ServicePlan{
+			{Dependencies: []models.ServiceDependency{{ServiceId: service.Id}}},
+		}}
+		application := models.Application{Id: "application-1", Name: "application", ImageId: "image-1", TemplateId: template.Id}
+		serviceInstance := models.Instance{Id: "instance-1", Name: "service-instance", ClassId: service.Id}
+		dependentInstance := models.Instance{Id: "instance-2", Name: "dependent-instance", ClassId: dependentService.Id}
+		boundInstance := models.Instance{Id: "instance-3", Name: "bound-instance", ClassId: "other",
+			Bindings: []models.InstanceBindings{{Id: serviceInstance.Id}}}
+
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(
+			[]interface{}{serviceInstance, dependentInstance, boundInstance}, nil).AnyTimes()
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(
+			[]interface{}{service, dependentService}, nil).AnyTimes()
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return(
+			[]interface{}{application}, nil).AnyTimes()
+		mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(models.ConstructImageIdForUserOffering(service.Id)), models.Image{}).Return(
+			nil, errors.New("Key not found")).AnyTimes()
+		mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(models.ConstructImageIdForUserOffering(dependentService.Id)), models.Image{}).Return(
+			nil, errors.New("Key not found")).AnyTimes()
+
+		Convey("When Template is deleted in preview mode, all dependents should be listed in removal order", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(template.Id), models.Template{}).Return(template, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(application.ImageId), models.Image{}).Return(models.Image{Id: application.ImageId}, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/templates/"+template.Id+"?cascade=preview", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			items := []models.CascadeDeleteItem{}
+			So(json.Unmarshal(rr.Body.Bytes(), &items), ShouldBeNil)
+			So(items, ShouldResemble, []models.CascadeDeleteItem{
+				{Action: models.CascadeActionUnbind, EntityType: models.EntityTypeInstance, Id: boundInstance.Id, Name: boundInstance.Name, BindingId: serviceInstance.Id},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeInstance, Id: dependentInstance.Id, Name: dependentInstance.Name, Purge: true},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeInstance, Id: serviceInstance.Id, Name: serviceInstance.Name, Purge: true},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeService, Id: dependentService.Id, Name: dependentService.Name},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeService, Id: service.Id, Name: service.Name},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeApplication, Id: application.Id, Name: application.Name},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeImage, Id: application.ImageId},
+				{Action: models.CascadeActionDelete, EntityType: models.EntityTypeTemplate, Id: template.Id},
+			})
+		})
+
+		Convey("When Service is deleted with purge in preview mode, every removed object should be listed as purged", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(service.Id), models.Service{}).Return(service, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+service.Id+"?cascade=preview&purge=true", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			items := []models.CascadeDeleteItem{}
+			So(json.Unmarshal(rr.Body.Bytes(), &items), ShouldBeNil)
+			So(items, ShouldHaveLength, 5)
+			So(items[0].Action, ShouldEqual, models.CascadeActionUnbind)
+			So(items[0].Purge, ShouldBeFalse)
+			for _, item := range items[1:] {
+				So(item.Purge, ShouldBeTrue)
+			}
+		})
+
+		Convey("When Service is deleted with cascade, instances should be purged and offerings moved to trash", func() {
+			bindingKey := context.mapper.ToKey(context.mapper.ToKey(context.buildInstanceKey(boundInstance.Id), data.Bindings), serviceInstance.Id)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(service.Id), models.Service{}).Return(service, nil).Times(2)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(dependentService.Id), models.Service{}).Return(dependentService, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(dependentInstance.Id), models.Instance{}).Return(dependentInstance, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(serviceInstance.Id), models.Instance{}).Return(serviceInstance, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(boundInstance.Id), models.Instance{}).Return(boundInstance, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().DeleteData(bindingKey).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildInstanceKey(dependentInstance.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildInstanceKey(serviceInstance.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Services, dependentService.Id)), gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(dependentService.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Services, service.Id)), gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(service.Id)).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+service.Id+"?cascade=true", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			items := []models.CascadeDeleteItem{}
+			So(json.Unmarshal(rr.Body.Bytes(), &items), ShouldBeNil)
+			So(items[1].Purge, ShouldBeTrue)
+			So(items[3].Purge, ShouldBeFalse)
+		})
+
+		Convey("When Service is deleted with cascade, dependents should be removed before it", func() {
+			bindingKey := context.mapper.ToKey(context.mapper.ToKey(context.buildInstanceKey(boundInstance.Id), data.Bindings), serviceInstance.Id)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(service.Id), models.Service{}).Return(service, nil).Times(2)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(dependentService.Id), models.Service{}).Return(dependentService, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(dependentInstance.Id), models.Instance{}).Return(dependentInstance, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(serviceInstance.Id), models.Instance{}).Return(serviceInstance, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(boundInstance.Id), models.Instance{}).Return(boundInstance, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().DeleteData(bindingKey).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildInstanceKey(dependentInstance.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildInstanceKey(serviceInstance.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(dependentService.Id)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(service.Id)).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+service.Id+"?cascade=true&purge=true", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When image is used by application which is not removed, it should be kept", func() {
+			otherApplication := models.Application{Id: "application-2", ImageId: application.ImageId}
+			mocks.repositoryMock.EXPECT().GetData(context.buildApplicationKey(application.Id), models.Application{}).Return(application, nil)
+			deletion, err := context.newCascadeDeletion()
+			So(err, ShouldBeNil)
+			So(deletion.addRoot(models.EntityTypeApplication, application.Id), ShouldBeNil)
+
+			So(deletion.areAllRemoved([]models.Application{application, otherApplication}, nil), ShouldBeFalse)
+			So(deletion.areAllRemoved([]models.Application{application}, nil), ShouldBeTrue)
+		})
+
+		Convey("When cascade parameter has unknown value, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/applications/"+application.Id+"?cascade=all", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -18,6 +18,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gocraft/web"
@@ -36,15 +37,24 @@ const (
 var logger, _ = commonLogger.InitLogger("api")
 
 type Context struct {
-	mapper       data.DataMapper
-	repository   data.RepositoryApi
-	organization string
+	mapper        data.DataMapper
+	repository    data.RepositoryApi
+	organization  string
+	stateMachines map[models.EntityType]models.StateMachine
+	// actingUser is taken from X-Acting-User header of request, it is recorded next to the authenticated user
+	actingUser string
 }
 
 func NewContext(r data.RepositoryApi, org string) (Context, error) {
+	stateMachines, err := loadStateMachines(os.Getenv(stateMachinesFileEnv))
+	if err != nil {
+		return Context{}, err
+	}
+
 	ctx := Context{
-		repository:   r,
-		organization: org,
+		repository:    r,
+		organization:  org,
+		stateMachines: stateMachines,
 	}
 	return ctx, ctx.initDB(org)
 }
@@ -62,12 +72,18 @@ func (c *Context) enterState(e *fsm.Event) {
 }
 
 func (c *Context) getStateChange(patches []models.Patch) (string, error) {
+	_, value, _ := c.findStatePatch(patches)
+	return value, nil
+}
+
+// findStatePatch returns the first patch which changes state, together with the new state
+func (c *Context) findStatePatch(patches []models.Patch) (models.Patch, string, bool) {
 	for _, patch := range patches {
-		if value, err := c.getFieldValue(patch, "state"); err == nil {
-			return value, nil
+		if value, err := c.getFieldValue(patch, "state"); err == nil && value != "" {
+			return patch, value, true
 		}
 	}
-	return "", nil
+	return models.Patch{}, "", false
 }
 
 func (c *Context) getFieldValue(patch models.Patch, field string) (string, error) {
@@ -87,20 +103,27 @@ func (c *Context) allowStateChange(newState string, stateMachine *fsm.FSM) error
 	return nil
 }
 
-type FsmFunc func() *fsm.FSM
-
-func (c *Context) handleFsm(rw web.ResponseWriter, req *web.Request, patches []models.Patch, fsmFunc FsmFunc) error {
+// handleFsm responds with error if state change requested by patches is not allowed by state machine of entity type
+func (c *Context) handleFsm(rw web.ResponseWriter, req *web.Request, patches []models.Patch, entityType models.EntityType, currentState string) error {
 	newState, err := c.getStateChange(patches)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return err
 	}
-	fsm := fsmFunc()
-	err = c.allowStateChange(newState, fsm)
+
+	stateMachine, ok := c.getStateMachine(entityType)
+	if !ok {
+		err = fmt.Errorf("state machine of %s not found", entityType)
+		commonHttp.Respond500(rw, err)
+		return err
+	}
+
+	err = c.allowStateChange(newState, c.newFSM(stateMachine, currentState))
 	if err != nil {
-		if fsm.Current() == newState {
+		if currentState == newState {
 			commonHttp.Respond409(rw, err)
 		} else {
+			err = fmt.Errorf("%v, allowed next states: %v", err, stateMachine.NextStates(currentState))
 			commonHttp.Respond400(rw, err)
 		}
 		return err
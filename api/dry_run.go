@@ -0,0 +1,54 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const dryRunQueryParam = "dryRun"
+
+// isDryRun tells if mutating request should be validated only - handlers check it right before first write
+// to etcd and respond with would-be result instead
+func isDryRun(req *web.Request) bool {
+	return req.URL.Query().Get(dryRunQueryParam) == "true"
+}
+
+// respondDryRunCreate writes the entity as it would be created. Id is not reserved in dry run, so it is left empty.
+func (c *Context) respondDryRunCreate(rw web.ResponseWriter, entity interface{}) {
+	preview := c.mapper.PreviewCreate(entity)
+	commonHttp.WriteJson(rw, preview, http.StatusOK)
+}
+
+func (c *Context) respondDryRunPatch(rw web.ResponseWriter, entity interface{}, patches []models.Patch) {
+	preview, err := c.mapper.PreviewPatches(entity, patches)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+	commonHttp.WriteJson(rw, preview, http.StatusOK)
+}
+
+// respondDryRunDelete writes object which would be removed
+func (c *Context) respondDryRunDelete(rw web.ResponseWriter, key string, model interface{}) {
+	entity, err := c.repository.GetData(key, model)
+	commonHttp.WriteJsonOrError(rw, entity, http.StatusOK, err)
+}
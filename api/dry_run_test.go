@@ -0,0 +1,107 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestDryRun(t *testing.T) {
+	Convey("Testing requests with dryRun parameter", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+
+		Convey("When Service is proper, would-be Service should be returned and nothing should be written", func() {
+			sampleService := getSampleServices()[0]
+			sampleService.Id = ""
+			body, _ := json.Marshal(sampleService)
+
+			mocks.repositoryMock.EXPECT().IsExistByName(sampleService.Name, models.Service{}, context.getServiceKey()).Return(false, nil)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/services?dryRun=true", body, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			service := models.Service{}
+			So(json.Unmarshal(rr.Body.Bytes(), &service), ShouldBeNil)
+			So(service.Id, ShouldBeEmpty)
+			So(service.Name, ShouldEqual, sampleService.Name)
+			So(service.State, ShouldEqual, models.ServiceStateDeploying)
+		})
+
+		Convey("When Service name is already used, response status should be Conflict", func() {
+			sampleService := getSampleServices()[0]
+			sampleService.Id = ""
+			body, _ := json.Marshal(sampleService)
+
+			mocks.repositoryMock.EXPECT().IsExistByName(sampleService.Name, models.Service{}, context.getServiceKey()).Return(true, nil)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/services?dryRun=true", body, t)
+			So(rr.Code, ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("When Template is patched, patched Template should be returned and nothing should be written", func() {
+			template := models.Template{Id: sampleID1, State: models.TemplateStateInProgress}
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/templates/"+sampleID1+"?dryRun=true",
+				[]byte(`[{"op":"Update","field":"state","value":"READY","username":"admin"}]`), t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			patched := models.Template{}
+			So(json.Unmarshal(rr.Body.Bytes(), &patched), ShouldBeNil)
+			So(patched.Id, ShouldEqual, sampleID1)
+			So(patched.State, ShouldEqual, models.TemplateStateReady)
+			So(patched.AuditTrail.LastUpdateBy, ShouldEqual, "admin")
+		})
+
+		Convey("When Template state change is not allowed, response status should be BadRequest", func() {
+			template := models.Template{Id: sampleID1, State: models.TemplateStateReady}
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/templates/"+sampleID1+"?dryRun=true",
+				[]byte(`[{"op":"Update","field":"state","value":"IN_PROGRESS"}]`), t)
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When offering can be deleted, it should be returned and nothing should be removed", func() {
+			sampleServices := getSampleServices()
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(getSampleInstancesAsListOfInterfaces(getSampleInstances()), nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(getSampleServicesAsListOfInterfaces(sampleServices), nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(sampleServices[0], nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+sampleID1+"?dryRun=true", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When there exist an instance of offering, response status should be Forbidden", func() {
+			sampleInstances := getSampleInstances()
+			sampleInstances[0].ClassId = sampleID1
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(getSampleInstancesAsListOfInterfaces(sampleInstances), nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+sampleID1+"?dryRun=true", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,175 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gocraft/web"
+	"golang.org/x/net/context"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	lastEventIdHeader = "Last-Event-ID"
+
+	eventsHeartbeatIntervalEnv     = "EVENTS_HEARTBEAT_INTERVAL"
+	defaultEventsHeartbeatInterval = 15 * time.Second
+	eventsRetryMilliseconds        = 3000
+)
+
+// stateEntityTypes are entity types which have State field - only their changes are sent as events
+var stateEntityTypes = []models.EntityType{
+	models.EntityTypeImage,
+	models.EntityTypeInstance,
+	models.EntityTypeService,
+	models.EntityTypeTemplate,
+}
+
+// Events streams state changes as Server-Sent Events. Event id is equal to etcd index of the change,
+// so client can resume the stream by sending it back in Last-Event-ID header.
+func (c *Context) Events(rw web.ResponseWriter, req *web.Request) {
+	filter, err := getEventsFilter(req)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	afterIndex, err := getEventsStartIndex(req)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	fmt.Fprintf(rw, "retry: %d\n\n", eventsRetryMilliseconds)
+	rw.Flush()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan models.StateChangeEvent)
+	watchResult := make(chan error, 1)
+	go func() {
+		watchResult <- c.repository.WatchStateChanges(ctx, c.organization, afterIndex, events)
+	}()
+
+	heartbeat := time.NewTicker(getEventsHeartbeatInterval())
+	defer heartbeat.Stop()
+
+	closed := rw.CloseNotify()
+	for {
+		select {
+		case event := <-events:
+			if !filter.Matches(event.EntityType, event.Id) {
+				continue
+			}
+			if err := writeServerSentEvent(rw, strconv.FormatUint(event.Index, 10), models.EventTypeState, event); err != nil {
+				logger.Warningf("cannot write event %v: %v", event, err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(rw, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			rw.Flush()
+		case err := <-watchResult:
+			if err != nil {
+				writeServerSentEvent(rw, "", models.EventTypeError, commonHttp.MessageResponse{Message: err.Error()})
+			}
+			return
+		case <-closed:
+			return
+		}
+	}
+}
+
+func writeServerSentEvent(rw web.ResponseWriter, id, eventType string, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err = fmt.Fprintf(rw, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", eventType, payloadBytes); err != nil {
+		return err
+	}
+	rw.Flush()
+	return nil
+}
+
+func getEventsFilter(req *web.Request) (models.EventsFilter, error) {
+	filter := models.EventsFilter{Id: commonHttp.GetQueryParameterCaseInsensitive(req, "id")}
+	for _, value := range getQueryParameterAsList(req, "entityType") {
+		entityType, err := models.ParseEntityType(value)
+		if err != nil {
+			return filter, err
+		}
+		if !hasState(entityType) {
+			return filter, fmt.Errorf("entity type %q has no state - entity type must match one of: %v", value, stateEntityTypes)
+		}
+		filter.EntityTypes = append(filter.EntityTypes, entityType)
+	}
+	return filter, nil
+}
+
+func hasState(entityType models.EntityType) bool {
+	for _, stateEntityType := range stateEntityTypes {
+		if entityType == stateEntityType {
+			return true
+		}
+	}
+	return false
+}
+
+// Last-Event-ID header takes precedence over afterIndex query parameter. Without both, only new changes are sent.
+func getEventsStartIndex(req *web.Request) (uint64, error) {
+	value := req.Header.Get(lastEventIdHeader)
+	if value == "" {
+		value = req.URL.Query().Get("afterIndex")
+	}
+	if value == "" {
+		return models.WatchFromNow, nil
+	}
+
+	index, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("event index %q must match unsigned integer: %v", value, err)
+	}
+	return index, nil
+}
+
+func getEventsHeartbeatInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(eventsHeartbeatIntervalEnv))
+	if err != nil || interval <= 0 {
+		return defaultEventsHeartbeatInterval
+	}
+	return interval
+}
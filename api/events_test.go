@@ -0,0 +1,134 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const eventsTimeout = 5 * time.Second
+
+func TestEvents(t *testing.T) {
+	eventsPath := "/api/v1/events"
+
+	Convey("Testing Events", t, func() {
+		mockCtrl, context, _, _ := prepareMocksAndClient(t)
+
+		Convey("When entity type has no state, response status is 400", func() {
+			for _, entityType := range []models.EntityType{models.EntityTypePlan, models.EntityTypeApplication} {
+				response := sendAuthorizedRequest(context, "GET", eventsPath+"?entityType="+string(entityType), nil, t)
+
+				So(response.Code, ShouldEqual, http.StatusBadRequest)
+				So(response.Body.String(), ShouldContainSubstring, "has no state")
+			}
+		})
+
+		Convey("When entity type is unknown, response status is 400", func() {
+			response := sendAuthorizedRequest(context, "GET", eventsPath+"?entityType=UNKNOWN", nil, t)
+
+			So(response.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When event index is not a number, response status is 400", func() {
+			response := sendAuthorizedRequest(context, "GET", eventsPath+"?afterIndex=abc", nil, t)
+
+			So(response.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestWatchEvents(t *testing.T) {
+	serviceEvent := models.StateChangeEvent{EntityType: models.EntityTypeService, Id: serviceId, State: "READY", Index: 7}
+	instanceEvent := models.StateChangeEvent{EntityType: models.EntityTypeInstance, Id: instanceId, State: "RUNNING", Index: 8}
+
+	Convey("Testing WatchEvents", t, func() {
+		mockCtrl, _, mocks, catalogClient := prepareMocksAndClient(t)
+		stop := make(chan struct{})
+
+		Convey("Only events matching the filter should be delivered", func() {
+			afterIndex := uint64(5)
+			mocks.repositoryMock.EXPECT().WatchStateChanges(gomock.Any(), gomock.Any(), afterIndex, gomock.Any()).
+				Do(func(ctx context.Context, org string, afterIndex uint64, events chan<- models.StateChangeEvent) {
+					events <- serviceEvent
+					events <- instanceEvent
+					<-ctx.Done()
+				}).Return(nil)
+
+			filter := models.EventsFilter{EntityTypes: []models.EntityType{models.EntityTypeInstance}}
+			events, errs := catalogClient.WatchEvents(filter, afterIndex, stop)
+
+			select {
+			case event := <-events:
+				So(event, ShouldResemble, instanceEvent)
+			case err := <-errs:
+				t.Fatal("unexpected events stream error: ", err)
+			case <-time.After(eventsTimeout):
+				t.Fatal("event was not delivered")
+			}
+
+			close(stop)
+			_, open := <-events
+			So(open, ShouldBeFalse)
+		})
+
+		Convey("When watch fails, error should be delivered and stream should not be resumed", func() {
+			mocks.repositoryMock.EXPECT().WatchStateChanges(gomock.Any(), gomock.Any(), models.WatchFromNow, gomock.Any()).
+				Return(errors.New("index cleared"))
+
+			events, errs := catalogClient.WatchEvents(models.EventsFilter{}, models.WatchFromNow, stop)
+
+			select {
+			case err := <-errs:
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "index cleared")
+			case <-time.After(eventsTimeout):
+				t.Fatal("error was not delivered")
+			}
+			_, open := <-events
+			So(open, ShouldBeFalse)
+		})
+
+		Convey("When filter is invalid, error should be delivered", func() {
+			filter := models.EventsFilter{EntityTypes: []models.EntityType{models.EntityTypePlan}}
+			_, errs := catalogClient.WatchEvents(filter, models.WatchFromNow, stop)
+
+			select {
+			case err := <-errs:
+				So(err.Error(), ShouldContainSubstring, "400")
+			case <-time.After(eventsTimeout):
+				t.Fatal("error was not delivered")
+			}
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
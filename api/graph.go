@@ -0,0 +1,205 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+// dependencyFields are fields which define edges of dependency graph
+var dependencyFields = []string{"Bindings", "InstanceDependencies", "Plans", "Dependencies"}
+
+func (c *Context) Graph(rw web.ResponseWriter, req *web.Request) {
+	graph, err := c.getDependencyGraph()
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	if id := req.URL.Query().Get("id"); id != "" {
+		entityType, err := models.ParseEntityType(req.URL.Query().Get("entityType"))
+		if err != nil {
+			commonHttp.Respond400(rw, err)
+			return
+		}
+
+		root := models.GraphNodeRef{Type: entityType, Id: id}
+		if !graph.HasNode(root) {
+			commonHttp.Respond404(rw, fmt.Errorf("%s not found in dependency graph", root))
+			return
+		}
+		graph = graph.Rooted(root)
+	}
+	commonHttp.WriteJson(rw, graph, http.StatusOK)
+}
+
+func (c *Context) getDependencyGraph() (*models.DependencyGraph, error) {
+	applications, instances, services, err := c.getDependencyGraphEntities()
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDependencyGraph(applications, instances, services), nil
+}
+
+func (c *Context) getDependencyGraphEntities() ([]models.Application, []models.Instance, []models.Service, error) {
+	applications, err := c.getApplications()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	instances, err := c.getInstances()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	services, err := c.getServices()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return applications, instances, services, nil
+}
+
+// assureNoNewDependencyCycle rejects changed entity if it would become part of dependency cycle it is not part of already
+func (c *Context) assureNoNewDependencyCycle(changed interface{}) (int, error) {
+	applications, instances, services, err := c.getDependencyGraphEntities()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	before := models.NewDependencyGraph(applications, instances, services)
+
+	var node models.GraphNodeRef
+	switch entity := changed.(type) {
+	case models.Application:
+		node = models.GraphNodeRef{Type: models.EntityTypeApplication, Id: entity.Id}
+		applications = append(removeApplication(applications, entity.Id), entity)
+	case models.Instance:
+		node = models.GraphNodeRef{Type: models.EntityTypeInstance, Id: entity.Id}
+		instances = append(removeInstance(instances, entity.Id), entity)
+	case models.Service:
+		node = models.GraphNodeRef{Type: models.EntityTypeService, Id: entity.Id}
+		services = append(removeService(services, entity.Id), entity)
+	default:
+		return http.StatusInternalServerError, fmt.Errorf("type %T is not part of dependency graph", changed)
+	}
+
+	after := models.NewDependencyGraph(applications, instances, services)
+	if cycle := after.CycleOf(node); cycle != nil && before.CycleOf(node) == nil {
+		path := []string{}
+		for _, ref := range append(cycle, cycle[0]) {
+			path = append(path, ref.String())
+		}
+		return http.StatusBadRequest, fmt.Errorf("change would introduce dependency cycle: %s", strings.Join(path, " -> "))
+	}
+	return http.StatusOK, nil
+}
+
+// assureNoNewDependencyCycleByPatches checks entity with patches applied, if any of them changes dependencies
+func (c *Context) assureNoNewDependencyCycleByPatches(entity interface{}, patches []models.Patch) (int, error) {
+	if !changesDependencies(patches) {
+		return http.StatusOK, nil
+	}
+
+	patched, err := c.mapper.PreviewPatches(entity, patches)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	return c.assureNoNewDependencyCycle(patched)
+}
+
+func changesDependencies(patches []models.Patch) bool {
+	for _, patch := range patches {
+		if patch.Field == nil {
+			continue
+		}
+		for _, field := range dependencyFields {
+			if strings.Title(*patch.Field) == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func removeApplication(applications []models.Application, id string) []models.Application {
+	result := []models.Application{}
+	for _, application := range applications {
+		if application.Id != id {
+			result = append(result, application)
+		}
+	}
+	return result
+}
+
+func removeInstance(instances []models.Instance, id string) []models.Instance {
+	result := []models.Instance{}
+	for _, instance := range instances {
+		if instance.Id != id {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
+
+func removeService(services []models.Service, id string) []models.Service {
+	result := []models.Service{}
+	for _, service := range services {
+		if service.Id != id {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+func (c *Context) assureNoNewDependencyCycleByPlanPatches(serviceID string, plan interface{}, patches []models.Patch) (int, error) {
+	if !changesDependencies(patches) {
+		return http.StatusOK, nil
+	}
+
+	patched, err := c.mapper.PreviewPatches(plan, patches)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	patchedPlan, ok := patched.(models.ServicePlan)
+	if !ok {
+		return http.StatusInternalServerError, fmt.Errorf("type assertion for plan failed: %v", patched)
+	}
+	return c.assureNoNewPlanDependencyCycle(serviceID, patchedPlan)
+}
+
+// assureNoNewPlanDependencyCycle checks offering with given plan replaced or, if it is a new plan, added
+func (c *Context) assureNoNewPlanDependencyCycle(serviceID string, plan models.ServicePlan) (int, error) {
+	service, err := c.getService(serviceID)
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+
+	plans := []models.ServicePlan{}
+	for _, existing := range service.Plans {
+		if plan.Id == "" || existing.Id != plan.Id {
+			plans = append(plans, existing)
+		}
+	}
+	service.Plans = append(plans, plan)
+	return c.assureNoNewDependencyCycle(service)
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestGraph(t *testing.T) {
+	Convey("Testing dependency graph", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+		application := models.Application{Id: sampleID1, Name: sampleName1, InstanceDependencies: []models.InstanceDependency{{Id: sampleID2}}}
+		appInstance := models.Instance{Id: sampleID1, Name: sampleName1, Type: models.InstanceTypeApplication, ClassId: sampleID1}
+		dependency := models.Instance{Id: sampleID2, Name: sampleName2, Type: models.InstanceTypeApplication, ClassId: "other"}
+
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{application}, nil)
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{appInstance, dependency}, nil)
+		mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+
+		Convey("When graph is rooted at application instance, its dependencies should be returned in start order", func() {
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/graph?entityType=instance&id="+sampleID1, nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			graph := models.DependencyGraph{}
+			So(json.Unmarshal(rr.Body.Bytes(), &graph), ShouldBeNil)
+			So(graph.Nodes, ShouldHaveLength, 3)
+			So(graph.StartOrder, ShouldResemble, []models.GraphNodeRef{
+				{Type: models.EntityTypeInstance, Id: sampleID2},
+				{Type: models.EntityTypeApplication, Id: sampleID1},
+				{Type: models.EntityTypeInstance, Id: sampleID1},
+			})
+		})
+
+		Convey("When binding would introduce cycle, response status should be BadRequest", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID2), models.Instance{}).Return(dependency, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildInstanceKey(sampleID1), models.Instance{}).Return(appInstance, nil)
+
+			_, status, err := catalogClient.AddInstanceBinding(sampleID2, models.InstanceBindings{Id: sampleID1})
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+			So(err.Error(), ShouldContainSubstring, "would introduce dependency cycle")
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
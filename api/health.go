@@ -16,15 +16,108 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"os"
+	"runtime"
+	"time"
 
 	"github.com/gocraft/web"
 
+	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
 	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 )
 
+const (
+	healthCheckTimeoutEnv     = "HEALTH_CHECK_TIMEOUT"
+	defaultHealthCheckTimeout = 2 * time.Second
+)
+
+// Build information is set by linker, e.g. -ldflags "-X github.com/trustedanalytics-ng/tap-catalog/api.Version=0.8.0"
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
+
 func (c *Context) GetCatalogHealth(rw web.ResponseWriter, req *web.Request) {
 	_, err := c.repository.GetListOfData(c.getServiceKey(), models.Service{})
 	commonHttp.WriteJsonOrError(rw, "", http.StatusOK, err)
 }
+
+// GetLiveness only tells that process is able to serve requests - it does not touch etcd, so that etcd outage
+// does not cause restarts of catalog
+func (c *Context) GetLiveness(rw web.ResponseWriter, req *web.Request) {
+	commonHttp.WriteJson(rw, "", http.StatusOK)
+}
+
+func (c *Context) GetReadiness(rw web.ResponseWriter, req *web.Request) {
+	missingDirs, err := c.repository.GetMissingDirs(c.organization, getHealthCheckTimeout())
+	if err != nil {
+		readiness := models.Readiness{Message: fmt.Sprintf("etcd is not reachable: %v", err)}
+		commonHttp.WriteJson(rw, readiness, http.StatusServiceUnavailable)
+		return
+	}
+	if len(missingDirs) > 0 {
+		readiness := models.Readiness{Message: "directory layout is not complete", MissingDirs: missingDirs}
+		commonHttp.WriteJson(rw, readiness, http.StatusServiceUnavailable)
+		return
+	}
+	commonHttp.WriteJson(rw, models.Readiness{Ready: true}, http.StatusOK)
+}
+
+// GetHealthDetails always responds with OK - problems found are reported by healthy flag and errors of response
+func (c *Context) GetHealthDetails(rw web.ResponseWriter, req *web.Request) {
+	details := models.HealthDetails{
+		Members:     []models.EtcdMemberHealth{},
+		OpenWatches: c.repository.GetOpenWatchesCount(),
+		Build:       getBuildInfo(),
+	}
+
+	members, err := c.repository.GetClusterHealth(getHealthCheckTimeout())
+	if err != nil {
+		details.Errors = append(details.Errors, fmt.Sprintf("cannot get etcd members health: %v", err))
+	} else {
+		details.Members = members
+	}
+	for _, member := range members {
+		if !member.Healthy {
+			details.Errors = append(details.Errors, fmt.Sprintf("etcd member %q is not healthy: %s", member.Name, member.Error))
+		}
+	}
+
+	details.RoundTrip, err = c.repository.MeasureRoundTrip(c.getHealthProbeKey(), getHealthCheckTimeout())
+	if err != nil {
+		details.Errors = append(details.Errors, fmt.Sprintf("etcd round trip failed: %v", err))
+	}
+
+	details.Healthy = len(details.Errors) == 0
+	commonHttp.WriteJson(rw, details, http.StatusOK)
+}
+
+func getBuildInfo() models.BuildInfo {
+	return models.BuildInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+func getHealthCheckTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv(healthCheckTimeoutEnv))
+	if err != nil || timeout <= 0 {
+		return defaultHealthCheckTimeout
+	}
+	return timeout
+}
+
+// getHealthProbeKey is different for each catalog replica, so that their probes do not overwrite each other
+func (c *Context) getHealthProbeKey() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "catalog"
+	}
+	return c.mapper.ToKey(data.GetEntityKey(c.organization, data.HealthProbe), hostname)
+}
@@ -0,0 +1,113 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func TestHealthProbes(t *testing.T) {
+	Convey("Testing health probes", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareStrictMocksAndClient(t)
+
+		Convey("Liveness should not touch etcd", func() {
+			rr := commonHttp.SendRequestWithHeaders("GET", "/healthz/live", nil, SetupRouter(context), http.Header{}, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When etcd is reachable and layout exists, catalog should be ready", func() {
+			mocks.repositoryMock.EXPECT().GetMissingDirs(context.organization, defaultHealthCheckTimeout).Return([]string{}, nil)
+
+			readiness, status, err := catalogClient.GetCatalogReadiness()
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(readiness.Ready, ShouldBeTrue)
+		})
+
+		Convey("When etcd is not reachable, response status should be ServiceUnavailable", func() {
+			mocks.repositoryMock.EXPECT().GetMissingDirs(gomock.Any(), gomock.Any()).Return(nil, errors.New("context deadline exceeded"))
+
+			readiness, status, err := catalogClient.GetCatalogReadiness()
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusServiceUnavailable)
+			So(readiness.Ready, ShouldBeFalse)
+			So(readiness.Message, ShouldContainSubstring, "context deadline exceeded")
+		})
+
+		Convey("When directory is missing, response status should be ServiceUnavailable", func() {
+			mocks.repositoryMock.EXPECT().GetMissingDirs(gomock.Any(), gomock.Any()).Return([]string{"/Images"}, nil)
+
+			readiness, status, err := catalogClient.GetCatalogReadiness()
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusServiceUnavailable)
+			So(readiness.MissingDirs, ShouldResemble, []string{"/Images"})
+		})
+
+		Convey("When cluster is healthy, details should report it", func() {
+			members := []models.EtcdMemberHealth{{Id: "1", Name: "etcd-0", Healthy: true}}
+			roundTrip := models.EtcdRoundTrip{WriteMs: 1.5, ReadMs: 0.5}
+			mocks.repositoryMock.EXPECT().GetOpenWatchesCount().Return(int64(3))
+			mocks.repositoryMock.EXPECT().GetClusterHealth(defaultHealthCheckTimeout).Return(members, nil)
+			mocks.repositoryMock.EXPECT().MeasureRoundTrip(gomock.Any(), defaultHealthCheckTimeout).Return(roundTrip, nil)
+
+			details, status, err := catalogClient.GetCatalogHealthDetails()
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(details.Healthy, ShouldBeTrue)
+			So(details.Members, ShouldResemble, members)
+			So(details.RoundTrip, ShouldResemble, roundTrip)
+			So(details.OpenWatches, ShouldEqual, 3)
+			So(details.Build.Version, ShouldEqual, Version)
+		})
+
+		Convey("When member is not healthy, details should report errors", func() {
+			members := []models.EtcdMemberHealth{{Id: "1", Name: "etcd-0", Error: "connection refused"}}
+			mocks.repositoryMock.EXPECT().GetOpenWatchesCount().Return(int64(0))
+			mocks.repositoryMock.EXPECT().GetClusterHealth(gomock.Any()).Return(members, nil)
+			mocks.repositoryMock.EXPECT().MeasureRoundTrip(gomock.Any(), defaultHealthCheckTimeout).Return(models.EtcdRoundTrip{}, errors.New("timeout"))
+
+			details, status, err := catalogClient.GetCatalogHealthDetails()
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(details.Healthy, ShouldBeFalse)
+			So(details.Errors, ShouldHaveLength, 2)
+		})
+
+		Convey("Details should require authorization", func() {
+			rr := commonHttp.SendRequestWithHeaders("GET", "/healthz/details", nil, SetupRouter(context), http.Header{}, t)
+
+			So(rr.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
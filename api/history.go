@@ -0,0 +1,134 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/metrics"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	// reasonQueryParam gives reason of state change requested by JSON Patch or Merge Patch document
+	reasonQueryParam = "reason"
+
+	stateHistoryLimitEnv     = "STATE_HISTORY_LIMIT"
+	defaultStateHistoryLimit = 50
+)
+
+var stateHistoryDirs = map[models.EntityType]string{
+	models.EntityTypeInstance: data.Instances,
+	models.EntityTypeService:  data.Services,
+	models.EntityTypeImage:    data.Images,
+	models.EntityTypeTemplate: data.Templates,
+}
+
+func (c *Context) GetInstanceHistory(rw web.ResponseWriter, req *web.Request) {
+	c.getStateHistory(rw, models.EntityTypeInstance, req.PathParams["instanceId"], models.Instance{})
+}
+
+func (c *Context) GetServiceHistory(rw web.ResponseWriter, req *web.Request) {
+	c.getStateHistory(rw, models.EntityTypeService, req.PathParams["serviceId"], models.Service{})
+}
+
+func (c *Context) GetImageHistory(rw web.ResponseWriter, req *web.Request) {
+	c.getStateHistory(rw, models.EntityTypeImage, req.PathParams["imageId"], models.Image{})
+}
+
+func (c *Context) GetTemplateHistory(rw web.ResponseWriter, req *web.Request) {
+	c.getStateHistory(rw, models.EntityTypeTemplate, req.PathParams["templateId"], models.Template{})
+}
+
+// getStateHistory responds with NotFound only if entity has no history and does not exist. History of entity moved
+// to trash is kept, so it is complete after restore - it is removed when the entity is purged or when its
+// trash entry expires (see SweepTrash).
+func (c *Context) getStateHistory(rw web.ResponseWriter, entityType models.EntityType, id string, model interface{}) {
+	history, err := c.repository.GetStateHistory(c.buildStateHistoryKey(entityType, id))
+	if err != nil {
+		if !commonHttp.IsNotFoundError(err) {
+			commonHttp.HandleError(rw, err)
+			return
+		}
+
+		entityKey := c.mapper.ToKey(data.GetEntityKey(c.organization, stateHistoryDirs[entityType]), id)
+		if _, err := c.repository.GetData(entityKey, model); err != nil {
+			commonHttp.HandleError(rw, err)
+			return
+		}
+		history = []models.StateTransitionRecord{}
+	}
+	commonHttp.WriteJson(rw, history, http.StatusOK)
+}
+
+// recordStateTransition adds state change made by applied patches to history of entity. Patches are already
+// applied at this point, so failure is only logged.
+func (c *Context) recordStateTransition(req *web.Request, entityType models.EntityType, id, currentState string, patches []models.Patch) {
+	statePatch, newState, ok := c.findStatePatch(patches)
+	if !ok || newState == currentState {
+		return
+	}
+	metrics.RecordStateTransition(entityType, currentState, newState)
+
+	record := models.StateTransitionRecord{
+		From:      currentState,
+		To:        newState,
+		Timestamp: time.Now().UnixNano(),
+		User:      statePatch.Username,
+		Reason:    statePatch.Reason,
+	}
+	if record.User == "" {
+		record.User = c.mapper.Username
+	}
+	if record.Reason == "" {
+		record.Reason = req.URL.Query().Get(reasonQueryParam)
+	}
+
+	err := c.repository.AddStateHistoryRecord(c.buildStateHistoryKey(entityType, id), record, getStateHistoryLimit())
+	if err != nil {
+		logger.Errorf("cannot add state change of %s %q from %s to %s to history: %v", entityType, id, currentState, newState, err)
+	}
+}
+
+// removeStateHistory drops history of purged entity. Entity is already removed at this point, so failure is only logged.
+func (c *Context) removeStateHistory(entityType models.EntityType, id string) {
+	if _, ok := stateHistoryDirs[entityType]; !ok {
+		return
+	}
+	if err := c.repository.DeleteStateHistory(c.buildStateHistoryKey(entityType, id)); err != nil {
+		logger.Errorf("cannot remove state history of %s %q: %v", entityType, id, err)
+	}
+}
+
+func getStateHistoryLimit() int {
+	limit, err := strconv.Atoi(os.Getenv(stateHistoryLimitEnv))
+	if err != nil || limit <= 0 {
+		return defaultStateHistoryLimit
+	}
+	return limit
+}
+
+func (c *Context) buildStateHistoryKey(entityType models.EntityType, id string) string {
+	historyKey := c.mapper.ToKey(data.GetEntityKey(c.organization, data.History), stateHistoryDirs[entityType])
+	return c.mapper.ToKey(historyKey, id)
+}
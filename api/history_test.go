@@ -0,0 +1,115 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestStateHistory(t *testing.T) {
+	Convey("Testing state history", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+		historyKey := context.buildStateHistoryKey(models.EntityTypeImage, sampleID1)
+
+		Convey("When history of image is requested, recorded transitions should be returned", func() {
+			records := []models.StateTransitionRecord{
+				{From: string(models.ImageStatePending), To: string(models.ImageStateBuilding), Timestamp: 1, User: "admin"},
+			}
+			mocks.repositoryMock.EXPECT().GetStateHistory(historyKey).Return(records, nil)
+
+			result, status, err := catalogClient.GetStateHistory(models.EntityTypeImage, sampleID1)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldResemble, records)
+		})
+
+		Convey("When image has no history yet, empty list should be returned", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetStateHistory(historyKey).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(models.Image{}, nil),
+			)
+
+			result, status, err := catalogClient.GetStateHistory(models.EntityTypeImage, sampleID1)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldBeEmpty)
+		})
+
+		Convey("When image does not exist, response status should be NotFound", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetStateHistory(historyKey).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(nil, errors.New("Key not found")),
+			)
+
+			_, status, err := catalogClient.GetStateHistory(models.EntityTypeImage, sampleID1)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When entity type has no state, client should return BadRequest", func() {
+			_, status, err := catalogClient.GetStateHistory(models.EntityTypeApplication, sampleID1)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When state of image is changed, transition with reason should be recorded", func() {
+			image := models.Image{Id: sampleID1, State: models.ImageStateBuilding}
+			var record models.StateTransitionRecord
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(image, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().AddStateHistoryRecord(historyKey, gomock.Any(), defaultStateHistoryLimit).Do(
+					func(key string, added models.StateTransitionRecord, limit int) { record = added }).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(image, nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/images/"+sampleID1+"?reason=build+finished",
+				[]byte(`[{"op":"Update","field":"state","value":"READY"}]`), t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(record.From, ShouldEqual, string(models.ImageStateBuilding))
+			So(record.To, ShouldEqual, string(models.ImageStateReady))
+			So(record.Reason, ShouldEqual, "build finished")
+			So(record.Timestamp, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("When patch is a dry run, transition should not be recorded", func() {
+			image := models.Image{Id: sampleID1, State: models.ImageStateBuilding}
+			mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(image, nil)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/images/"+sampleID1+"?dryRun=true",
+				[]byte(`[{"op":"Update","field":"state","value":"READY"}]`), t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	idempotencyKeyTTLEnv     = "IDEMPOTENCY_KEY_TTL"
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+)
+
+// IdempotencyMiddleware makes POST requests sent with Idempotency-Key header safe to retry: the first response
+// is stored and replayed to retries with the same key and body. Retry with different body is rejected with 422.
+// Responses with 5xx status are not stored, so such request can be retried with the same key.
+func (c *Context) IdempotencyMiddleware(rw web.ResponseWriter, req *web.Request, next web.NextMiddlewareFunc) {
+	idempotencyKey := req.Header.Get(models.IdempotencyKeyHeader)
+	if req.Method != "POST" || idempotencyKey == "" || isDryRun(req) {
+		next(rw, req)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	key := c.buildIdempotencyKey(idempotencyKey)
+	record := models.IdempotencyRecord{RequestHash: getRequestHash(req, body)}
+	ttl := getIdempotencyKeyTTL()
+
+	if err := c.repository.CreateIdempotencyRecord(key, record, ttl); err != nil {
+		if !commonHttp.IsAlreadyExistsError(err) {
+			commonHttp.Respond500(rw, fmt.Errorf("cannot store idempotency key: %v", err))
+			return
+		}
+		c.replayIdempotentResponse(rw, key, record)
+		return
+	}
+
+	recorder := &idempotentResponseRecorder{ResponseWriter: rw}
+	next(recorder, req)
+
+	if recorder.StatusCode() >= http.StatusInternalServerError {
+		if err := c.repository.DeleteIdempotencyRecord(key); err != nil {
+			logger.Errorf("cannot remove idempotency key %q of failed request: %v", idempotencyKey, err)
+		}
+		return
+	}
+
+	record.Completed = true
+	record.Status = recorder.StatusCode()
+	record.ContentType = recorder.Header().Get("Content-Type")
+	record.Body = recorder.body.Bytes()
+	if err := c.repository.SetIdempotencyRecord(key, record, ttl); err != nil {
+		logger.Errorf("cannot store response for idempotency key %q: %v", idempotencyKey, err)
+	}
+}
+
+func (c *Context) replayIdempotentResponse(rw web.ResponseWriter, key string, record models.IdempotencyRecord) {
+	stored, err := c.repository.GetIdempotencyRecord(key)
+	if err != nil {
+		commonHttp.Respond500(rw, fmt.Errorf("cannot read idempotency key: %v", err))
+		return
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		commonHttp.GenericRespond(http.StatusUnprocessableEntity, rw,
+			errors.New("Idempotency-Key was already used for request with different body"))
+		return
+	}
+
+	if !stored.Completed {
+		commonHttp.Respond409(rw, errors.New("request with the same Idempotency-Key is still being processed"))
+		return
+	}
+
+	if stored.ContentType != "" {
+		rw.Header().Set("Content-Type", stored.ContentType)
+	}
+	rw.Header().Set(models.IdempotentReplayedHeader, "true")
+	rw.WriteHeader(stored.Status)
+	rw.Write(stored.Body)
+}
+
+// buildIdempotencyKey hashes key given by client, so that it is always a valid etcd key
+func (c *Context) buildIdempotencyKey(idempotencyKey string) string {
+	hash := sha256.Sum256([]byte(idempotencyKey))
+	return c.mapper.ToKey(data.GetEntityKey(c.organization, data.IdempotencyKeys), hex.EncodeToString(hash[:]))
+}
+
+func getRequestHash(req *web.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func getIdempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv(idempotencyKeyTTLEnv))
+	if err != nil || ttl <= 0 {
+		return defaultIdempotencyKeyTTL
+	}
+	return ttl
+}
+
+// idempotentResponseRecorder passes response to the client and keeps copy of the body
+type idempotentResponseRecorder struct {
+	web.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotentResponseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,113 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gocraft/web"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	Convey("Testing create requests with Idempotency-Key header", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+
+		sampleService := getSampleServices()[0]
+		sampleService.Id = ""
+		body, _ := json.Marshal(sampleService)
+		header := http.Header{models.IdempotencyKeyHeader: {"key-1"}}
+		key := context.buildIdempotencyKey("key-1")
+
+		httpReq, _ := http.NewRequest("POST", "/api/v1/services", nil)
+		requestHash := getRequestHash(&web.Request{Request: httpReq}, body)
+
+		Convey("When key is used for the first time, request should be processed and its response stored", func() {
+			var stored models.IdempotencyRecord
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().CreateIdempotencyRecord(key, models.IdempotencyRecord{RequestHash: requestHash}, gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().IsExistByName(sampleService.Name, models.Service{}, context.getServiceKey()).Return(false, nil),
+				mocks.repositoryMock.EXPECT().CreateDir(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(gomock.Any(), models.Service{}).Return(sampleService, nil),
+				mocks.repositoryMock.EXPECT().SetIdempotencyRecord(key, gomock.Any(), gomock.Any()).Return(nil).Do(
+					func(key string, record models.IdempotencyRecord, ttl interface{}) {
+						stored = record
+					}),
+			)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "POST", "/api/v1/services", body, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusCreated)
+			So(stored.Completed, ShouldBeTrue)
+			So(stored.Status, ShouldEqual, http.StatusCreated)
+			So(string(stored.Body), ShouldEqual, rr.Body.String())
+		})
+
+		Convey("When request with the same key and body was completed, stored response should be replayed", func() {
+			mocks.repositoryMock.EXPECT().CreateIdempotencyRecord(key, gomock.Any(), gomock.Any()).Return(errors.New("105: Key already exists"))
+			mocks.repositoryMock.EXPECT().GetIdempotencyRecord(key).Return(models.IdempotencyRecord{
+				RequestHash: requestHash, Completed: true, Status: http.StatusCreated, Body: []byte(`{"id":"1"}`),
+			}, nil)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "POST", "/api/v1/services", body, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusCreated)
+			So(rr.Body.String(), ShouldEqual, `{"id":"1"}`)
+			So(rr.Header().Get(models.IdempotentReplayedHeader), ShouldEqual, "true")
+		})
+
+		Convey("When the same key was used with different body, response status should be UnprocessableEntity", func() {
+			mocks.repositoryMock.EXPECT().CreateIdempotencyRecord(key, gomock.Any(), gomock.Any()).Return(errors.New("105: Key already exists"))
+			mocks.repositoryMock.EXPECT().GetIdempotencyRecord(key).Return(models.IdempotencyRecord{RequestHash: "other", Completed: true}, nil)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "POST", "/api/v1/services", body, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("When request with the same key is still processed, response status should be Conflict", func() {
+			mocks.repositoryMock.EXPECT().CreateIdempotencyRecord(key, gomock.Any(), gomock.Any()).Return(errors.New("105: Key already exists"))
+			mocks.repositoryMock.EXPECT().GetIdempotencyRecord(key).Return(models.IdempotencyRecord{RequestHash: requestHash}, nil)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "POST", "/api/v1/services", body, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("When request fails with server error, key should be released", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().CreateIdempotencyRecord(key, gomock.Any(), gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().IsExistByName(sampleService.Name, models.Service{}, context.getServiceKey()).Return(false, errors.New("etcd error")),
+				mocks.repositoryMock.EXPECT().DeleteIdempotencyRecord(key).Return(nil),
+			)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "POST", "/api/v1/services", body, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -21,7 +21,6 @@ import (
 	"net/http"
 
 	"github.com/gocraft/web"
-	"github.com/looplab/fsm"
 
 	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
@@ -30,15 +29,27 @@ import (
 )
 
 func (c *Context) Images(rw web.ResponseWriter, req *web.Request) {
-	result, err := c.repository.GetListOfData(c.getImagesKey(), models.Image{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Image{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.getListOfDataWithProjection(c.getImagesKey(), models.Image{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) GetImage(rw web.ResponseWriter, req *web.Request) {
 	imageId := req.PathParams["imageId"]
 
-	result, err := c.repository.GetData(c.buildImagesKey(imageId), models.Image{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Image{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.getDataWithProjection(c.buildImagesKey(imageId), models.Image{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) AddImage(rw web.ResponseWriter, req *web.Request) {
@@ -50,7 +61,27 @@ func (c *Context) AddImage(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if err = reqImage.ValidateImageStructCreate(); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if status, err := c.assureImageOwnerExists(reqImage.Id); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if status, err := c.assureImageDoesNotExist(reqImage.Id); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
 	reqImage.State = models.ImageStateRequested
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqImage)
+		return
+	}
+
 	imageKeyStore := c.mapper.ToKeyValue(c.getImagesKey(), reqImage, true)
 
 	err = c.repository.CreateData(imageKeyStore)
@@ -58,12 +89,50 @@ func (c *Context) AddImage(rw web.ResponseWriter, req *web.Request) {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypeImage, reqImage.Id, "", reqImage)
 
 	image, err := c.repository.GetData(c.buildImagesKey(reqImage.Id), models.Image{})
 	commonHttp.WriteJsonOrError(rw, image, http.StatusCreated, err)
 }
 
+// assureImageDoesNotExist checks what CreateData would - image id is given by client, so it has to be unique
+func (c *Context) assureImageDoesNotExist(imageID string) (int, error) {
+	_, err := c.repository.GetData(c.buildImagesKey(imageID), models.Image{})
+	if err == nil {
+		return http.StatusConflict, fmt.Errorf("image %q already exists", imageID)
+	} else if !commonHttp.IsNotFoundError(err) {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// assureImageOwnerExists checks that application or offering which id is part of image id exists
+func (c *Context) assureImageOwnerExists(imageID string) (int, error) {
+	var err error
+	if models.IsApplicationInstance(imageID) {
+		_, err = c.getApplication(models.GetApplicationId(imageID))
+	} else {
+		_, err = c.getService(models.GetOfferingId(imageID))
+	}
+
+	if err != nil {
+		if commonHttp.IsNotFoundError(err) {
+			return http.StatusBadRequest, fmt.Errorf("image %q does not belong to existing application or offering", imageID)
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
 func (c *Context) PatchImage(rw web.ResponseWriter, req *web.Request) {
+	c.updateImage(rw, req, c.readPatches)
+}
+
+func (c *Context) PutImage(rw web.ResponseWriter, req *web.Request) {
+	c.updateImage(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updateImage(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	imageId := req.PathParams["imageId"]
 	imageInt, err := c.repository.GetData(c.buildImagesKey(imageId), models.Image{})
 	if err != nil {
@@ -77,17 +146,13 @@ func (c *Context) PatchImage(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, image)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
 	}
 
-	fsmFunc := func() *fsm.FSM {
-		return c.getImagesFSM(image.State)
-	}
-	if err = c.handleFsm(rw, req, patches, fsmFunc); err != nil {
+	if err = c.handleFsm(rw, req, patches, models.EntityTypeImage, string(image.State)); err != nil {
 		return
 	}
 
@@ -97,11 +162,18 @@ func (c *Context) PatchImage(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, image, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordStateTransition(req, models.EntityTypeImage, imageId, string(image.State), patches)
+	c.recordUpdateAudit(models.EntityTypeImage, imageId, "", image, patches)
 
 	imageInt, err = c.repository.GetData(c.buildImagesKey(imageId), models.Image{})
 	commonHttp.WriteJsonOrError(rw, imageInt, http.StatusOK, err)
@@ -109,8 +181,19 @@ func (c *Context) PatchImage(rw web.ResponseWriter, req *web.Request) {
 
 func (c *Context) DeleteImage(rw web.ResponseWriter, req *web.Request) {
 	imageId := req.PathParams["imageId"]
-	err := c.repository.DeleteData(c.buildImagesKey(imageId))
-	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
+
+	if status, err := c.assureImageIsNotUsed(imageId); err != nil {
+		err := fmt.Errorf("cannot remove image %q: %v", imageId, err)
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.buildImagesKey(imageId), models.Image{})
+		return
+	}
+
+	c.deleteOrMoveToTrash(rw, req, "images", imageId)
 }
 
 func (c *Context) GetImageCheckRefs(rw web.ResponseWriter, req *web.Request) {
@@ -195,6 +278,15 @@ func (c *Context) MonitorSpecificImageState(rw web.ResponseWriter, req *web.Requ
 	c.monitorSpecificState(rw, req, c.buildImagesKey(imageId))
 }
 
+func (c *Context) MonitorImagesChanges(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificChange(rw, req, c.getImagesKey())
+}
+
+func (c *Context) MonitorSpecificImageChange(rw web.ResponseWriter, req *web.Request) {
+	imageId := req.PathParams["imageId"]
+	c.monitorSpecificChange(rw, req, c.buildImagesKey(imageId))
+}
+
 func (c *Context) getImagesKey() string {
 	org := c.mapper.ToKey("", c.organization)
 	return c.mapper.ToKey(org, data.Images)
@@ -203,20 +295,3 @@ func (c *Context) getImagesKey() string {
 func (c *Context) buildImagesKey(imageId string) string {
 	return c.mapper.ToKey(c.getImagesKey(), imageId)
 }
-
-func (c *Context) getImagesFSM(initialState models.ImageState) *fsm.FSM {
-	return fsm.NewFSM(string(initialState),
-		fsm.Events{
-			{Name: "PENDING", Src: []string{"REQUESTED"}, Dst: "PENDING"},
-			{Name: "BUILDING", Src: []string{"PENDING"}, Dst: "BUILDING"},
-			{Name: "ERROR", Src: []string{"BUILDING"}, Dst: "ERROR"},
-			{Name: "READY", Src: []string{"BUILDING"}, Dst: "READY"},
-			{Name: "REMOVING", Src: []string{"READY"}, Dst: "REMOVING"},
-		},
-		fsm.Callbacks{
-			"enter_state": func(e *fsm.Event) {
-				c.enterState(e)
-			},
-		},
-	)
-}
@@ -31,11 +31,13 @@ import (
 
 func TestAddImage(t *testing.T) {
 	Convey("Testing AddImage", t, func() {
-		mockCtrl, _, mocks, catalogClient := prepareMocksAndClient(t)
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
 
 		Convey("When providing AddImage with proper Image", func() {
 			image := getSampleImage()
 			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildApplicationKey(sampleID1), models.Application{}).Return(models.Application{Id: sampleID1}, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(image.Id), models.Image{}).Return(nil, errors.New("Key not found")),
 				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
 				mocks.repositoryMock.EXPECT().GetData(gomock.Any(), models.Image{}).Return(image, nil),
 			)
@@ -55,6 +57,50 @@ func TestAddImage(t *testing.T) {
 			})
 		})
 
+		Convey("When image id has no application or offering prefix, response status should be BadRequest", func() {
+			image := getSampleImage()
+			image.Id = sampleID1
+
+			_, status, err := catalogClient.AddImage(image)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When image type is unknown, response status should be BadRequest", func() {
+			image := getSampleImage()
+			image.Type = "RUBY"
+
+			_, status, err := catalogClient.AddImage(image)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When offering of image does not exist, response status should be BadRequest", func() {
+			image := getSampleImage()
+			image.Id = models.ConstructImageIdForUserOffering(sampleID2)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID2), models.Service{}).Return(nil, errors.New("Key not found"))
+
+			_, status, err := catalogClient.AddImage(image)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When image already exists, response status should be Conflict", func() {
+			image := getSampleImage()
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildApplicationKey(sampleID1), models.Application{}).Return(models.Application{Id: sampleID1}, nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(image.Id), models.Image{}).Return(image, nil),
+			)
+
+			_, status, err := catalogClient.AddImage(image)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusConflict)
+		})
+
 		Reset(func() {
 			mockCtrl.Finish()
 		})
@@ -112,6 +158,7 @@ func TestMonitorImagesState(t *testing.T) {
 
 func getSampleImage() models.Image {
 	return models.Image{
+		Id:       models.GenerateImageId(sampleID1),
 		Type:     models.ImageTypeJava,
 		BlobType: models.BlobTypeJar,
 		State:    models.ImageStateBuilding,
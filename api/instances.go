@@ -21,7 +21,6 @@ import (
 	"net/http"
 
 	"github.com/gocraft/web"
-	"github.com/looplab/fsm"
 
 	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
@@ -29,8 +28,20 @@ import (
 )
 
 func (c *Context) Instances(rw web.ResponseWriter, req *web.Request) {
-	result, err := c.getInstances()
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if projection == nil {
+		result, err := c.getInstances()
+		commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+		return
+	}
+
+	result, err := c.getListOfDataWithProjection(c.getInstanceKey(), models.Instance{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) getInstances() ([]models.Instance, error) {
@@ -55,44 +66,86 @@ func (c *Context) getInstances() ([]models.Instance, error) {
 	return result, nil
 }
 
+func (c *Context) getInstance(id string) (models.Instance, error) {
+	entity, err := c.repository.GetData(c.buildInstanceKey(id), models.Instance{})
+	if err != nil {
+		return models.Instance{}, err
+	}
+
+	instance, ok := entity.(models.Instance)
+	if !ok {
+		return models.Instance{}, errors.New("Instance retrieved is in wrong format")
+	}
+	return instance, nil
+}
+
 func (c *Context) ServicesInstances(rw web.ResponseWriter, req *web.Request) {
-	instances, err := c.getFilteredInstances(models.InstanceTypeService, "")
-	commonHttp.WriteJsonOrError(rw, instances, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	instances, err := c.getFilteredInstancesWithProjection(models.InstanceTypeService, "", projection)
+	writeProjectedJsonOrError(rw, projection, instances, http.StatusOK, err)
 }
 
 func (c *Context) ServiceInstances(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
 
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
 	if _, err := c.repository.GetData(c.buildServiceKey(serviceId), models.Service{}); err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
 
-	instances, err := c.getFilteredInstances(models.InstanceTypeService, serviceId)
-	commonHttp.WriteJsonOrError(rw, instances, http.StatusOK, err)
+	instances, err := c.getFilteredInstancesWithProjection(models.InstanceTypeService, serviceId, projection)
+	writeProjectedJsonOrError(rw, projection, instances, http.StatusOK, err)
 }
 
 func (c *Context) ApplicationsInstances(rw web.ResponseWriter, req *web.Request) {
-	instances, err := c.getFilteredInstances(models.InstanceTypeApplication, "")
-	commonHttp.WriteJsonOrError(rw, instances, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	instances, err := c.getFilteredInstancesWithProjection(models.InstanceTypeApplication, "", projection)
+	writeProjectedJsonOrError(rw, projection, instances, http.StatusOK, err)
 }
 
 func (c *Context) ApplicationInstances(rw web.ResponseWriter, req *web.Request) {
 	appId := req.PathParams["applicationId"]
 
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
 	if _, err := c.repository.GetData(c.buildApplicationKey(appId), models.Application{}); err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
 
-	instances, err := c.getFilteredInstances(models.InstanceTypeApplication, appId)
-	commonHttp.WriteJsonOrError(rw, instances, http.StatusOK, err)
+	instances, err := c.getFilteredInstancesWithProjection(models.InstanceTypeApplication, appId, projection)
+	writeProjectedJsonOrError(rw, projection, instances, http.StatusOK, err)
 }
 
 func (c *Context) getFilteredInstances(expectedInstanceType models.InstanceType, expectedClassId string) ([]models.Instance, error) {
 	return data.GetFilteredInstances(expectedInstanceType, expectedClassId, c.organization, c.repository)
 }
 
+func (c *Context) getFilteredInstancesWithProjection(expectedInstanceType models.InstanceType, expectedClassId string,
+	projection *data.FieldProjection) ([]models.Instance, error) {
+	return data.GetFilteredInstancesWithProjection(expectedInstanceType, expectedClassId, c.organization, c.repository, projection)
+}
+
 func (c *Context) GetApplicationInstance(rw web.ResponseWriter, req *web.Request) {
 	applicationId := req.PathParams["applicationId"]
 
@@ -118,14 +171,26 @@ func (c *Context) GetServiceInstance(rw web.ResponseWriter, req *web.Request) {
 func (c *Context) GetInstance(rw web.ResponseWriter, req *web.Request) {
 	instanceId := req.PathParams["instanceId"]
 
-	result, err := c.repository.GetData(c.buildInstanceKey(instanceId), models.Instance{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.getDataWithProjection(c.buildInstanceKey(instanceId), models.Instance{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) GetInstanceBindings(rw web.ResponseWriter, req *web.Request) {
 	instanceId := req.PathParams["instanceId"]
 	result := []models.Instance{}
 
+	projection, err := getFieldProjection(req, models.Instance{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
 	instance, err := c.repository.GetData(c.buildInstanceKey(instanceId), models.Instance{})
 	if err != nil {
 		commonHttp.HandleError(rw, err)
@@ -133,14 +198,14 @@ func (c *Context) GetInstanceBindings(rw web.ResponseWriter, req *web.Request) {
 	}
 
 	for _, binding := range instance.(models.Instance).Bindings {
-		boundInstance, err := c.repository.GetData(c.buildInstanceKey(binding.Id), models.Instance{})
+		boundInstance, err := c.getDataWithProjection(c.buildInstanceKey(binding.Id), models.Instance{}, projection)
 		if err != nil {
 			commonHttp.HandleError(rw, err)
 			return
 		}
 		result = append(result, boundInstance.(models.Instance))
 	}
-	commonHttp.WriteJson(rw, result, http.StatusOK)
+	commonHttp.WriteJson(rw, projection.Apply(result), http.StatusOK)
 }
 
 func (c *Context) AddApplicationInstance(rw web.ResponseWriter, req *web.Request) {
@@ -203,20 +268,25 @@ func (c *Context) addInstance(rw web.ResponseWriter, req *web.Request, classId s
 		return
 	}
 
+	reqInstance.ClassId = classId
+	reqInstance.Type = instanceType
+	reqInstance.State = models.InstanceStateRequested
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqInstance)
+		return
+	}
+
 	if reqInstance.Id, err = c.reserveID(c.getInstanceKey()); err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
 
-	reqInstance.ClassId = classId
-	reqInstance.Type = instanceType
-	reqInstance.State = models.InstanceStateRequested
-
 	err = c.repository.CreateData(c.mapper.ToKeyValue(c.getInstanceKey(), reqInstance, true))
 	if err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypeInstance, reqInstance.Id, "", reqInstance)
 
 	instance, err := c.repository.GetData(c.buildInstanceKey(reqInstance.Id), models.Instance{})
 	if err != nil {
@@ -249,6 +319,14 @@ func (c *Context) PatchApplicationInstance(rw web.ResponseWriter, req *web.Reque
 }
 
 func (c *Context) PatchInstance(rw web.ResponseWriter, req *web.Request) {
+	c.updateInstance(rw, req, c.readPatches)
+}
+
+func (c *Context) PutInstance(rw web.ResponseWriter, req *web.Request) {
+	c.updateInstance(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updateInstance(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	instanceId := req.PathParams["instanceId"]
 	instanceInt, err := c.repository.GetData(c.buildInstanceKey(instanceId), models.Instance{})
 	if err != nil {
@@ -262,17 +340,18 @@ func (c *Context) PatchInstance(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, instance)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
 	}
 
-	fsmFunc := func() *fsm.FSM {
-		return c.getInstancesFSM(instance.State)
+	if status, err := c.validateBindingPatches(instanceId, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
 	}
-	if err = c.handleFsm(rw, req, patches, fsmFunc); err != nil {
+
+	if err = c.handleFsm(rw, req, patches, models.EntityTypeInstance, string(instance.State)); err != nil {
 		return
 	}
 
@@ -282,11 +361,23 @@ func (c *Context) PatchInstance(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if status, err := c.assureNoNewDependencyCycleByPatches(instance, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, instance, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordStateTransition(req, models.EntityTypeInstance, instanceId, string(instance.State), patches)
+	c.recordUpdateAudit(models.EntityTypeInstance, instanceId, "", instance, patches)
 
 	instanceInt, err = c.repository.GetData(c.buildInstanceKey(instanceId), models.Instance{})
 	if err != nil {
@@ -320,7 +411,25 @@ func (c *Context) DeleteApplicationInstance(rw web.ResponseWriter, req *web.Requ
 
 func (c *Context) DeleteInstance(rw web.ResponseWriter, req *web.Request) {
 	instanceID := req.PathParams["instanceId"]
-	err := c.repository.DeleteData(c.buildInstanceKey(instanceID))
+
+	if status, err := c.assureInstanceIsNotUsed(instanceID); err != nil {
+		err := fmt.Errorf("cannot remove instance %q: %v", instanceID, err)
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.buildInstanceKey(instanceID), models.Instance{})
+		return
+	}
+
+	instance, err := c.repository.GetData(c.buildInstanceKey(instanceID), models.Instance{})
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	err = c.purgeEntity(c.buildInstanceKey(instanceID), models.EntityTypeInstance, instanceID, "", instance)
 	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
 }
 
@@ -333,50 +442,19 @@ func (c *Context) MonitorSpecificInstanceState(rw web.ResponseWriter, req *web.R
 	c.monitorSpecificState(rw, req, c.buildInstanceKey(instanceId))
 }
 
-func (c *Context) getInstanceKey() string {
-	return data.GetEntityKey(c.organization, data.Instances)
+func (c *Context) MonitorInstancesChanges(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificChange(rw, req, c.getInstanceKey())
 }
 
-func (c *Context) buildInstanceKey(instanceId string) string {
-	return c.mapper.ToKey(c.getInstanceKey(), instanceId)
+func (c *Context) MonitorSpecificInstanceChange(rw web.ResponseWriter, req *web.Request) {
+	instanceId := req.PathParams["instanceId"]
+	c.monitorSpecificChange(rw, req, c.buildInstanceKey(instanceId))
 }
 
-func (c *Context) getInstancesFSM(initialState models.InstanceState) *fsm.FSM {
-	return fsm.NewFSM(string(initialState),
-		fsm.Events{
-			makeEventDesc(models.InstanceStateDeploying, models.InstanceStateRequested),
-			makeEventDesc(models.InstanceStateFailure, models.InstanceStateDeploying, models.InstanceStateStarting,
-				models.InstanceStateRunning, models.InstanceStateStopping, models.InstanceStateDestroying),
-			makeEventDesc(models.InstanceStateStopped, models.InstanceStateDeploying, models.InstanceStateStopping,
-				models.InstanceStateUnavailable),
-			makeEventDesc(models.InstanceStateStartReq, models.InstanceStateStopped),
-			makeEventDesc(models.InstanceStateStarting, models.InstanceStateStartReq, models.InstanceStateStopped,
-				models.InstanceStateReconfiguration),
-			makeEventDesc(models.InstanceStateRunning, models.InstanceStateStarting),
-			makeEventDesc(models.InstanceStateReconfiguration, models.InstanceStateRunning, models.InstanceStateStopped),
-			makeEventDesc(models.InstanceStateStopReq, models.InstanceStateRunning, models.InstanceStateStarting),
-			makeEventDesc(models.InstanceStateStopping, models.InstanceStateStopReq, models.InstanceStateReconfiguration),
-			makeEventDesc(models.InstanceStateDestroyReq, models.InstanceStateStopped, models.InstanceStateFailure),
-			makeEventDesc(models.InstanceStateDestroying, models.InstanceStateDestroyReq),
-			makeEventDesc(models.InstanceStateUnavailable, models.InstanceStateStopped, models.InstanceStateRunning),
-		},
-		fsm.Callbacks{
-			"enter_state": func(e *fsm.Event) {
-				c.enterState(e)
-			},
-		},
-	)
+func (c *Context) getInstanceKey() string {
+	return data.GetEntityKey(c.organization, data.Instances)
 }
 
-func makeEventDesc(destination models.InstanceState, sources ...models.InstanceState) fsm.EventDesc {
-	sourceString := []string{}
-	for _, source := range sources {
-		sourceString = append(sourceString, source.String())
-	}
-
-	return fsm.EventDesc{
-		Name: destination.String(),
-		Src:  sourceString,
-		Dst:  destination.String(),
-	}
+func (c *Context) buildInstanceKey(instanceId string) string {
+	return c.mapper.ToKey(c.getInstanceKey(), instanceId)
 }
@@ -0,0 +1,92 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+type patchesReader func(req *web.Request, entity interface{}) ([]models.Patch, error)
+
+// readPatches reads PATCH request body as list of catalog patches. JSON Patch and Merge Patch documents
+// are applied to the current entity and translated into catalog patches, so they are validated the same way.
+// Catalog client sends its own patches with JSON Patch content type, so JSON Patch is recognized by its "path" members.
+func (c *Context) readPatches(req *web.Request, entity interface{}) ([]models.Patch, error) {
+	patches, err := c.decodePatches(req, entity)
+	if err != nil {
+		return nil, err
+	}
+	return patches, c.mapper.ValidateEnumPatches(entity, patches)
+}
+
+func (c *Context) decodePatches(req *web.Request, entity interface{}) ([]models.Patch, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case contentType == models.MergePatchContentType:
+		return c.mapper.ToPatchesByMergePatch(entity, body, c.mapper.Username)
+	case contentType == models.JsonPatchContentType && isJsonPatchDocument(body):
+		operations := []models.JsonPatchOperation{}
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return nil, err
+		}
+		return c.mapper.ToPatchesByJsonPatch(entity, operations, c.mapper.Username)
+	}
+
+	patches := []models.Patch{}
+	err = commonHttp.ReadJsonFromByte(body, &patches)
+	return patches, err
+}
+
+func isJsonPatchDocument(body []byte) bool {
+	operations := []map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &operations); err != nil || len(operations) == 0 {
+		return false
+	}
+
+	for _, operation := range operations {
+		_, hasPath := operation["path"]
+		_, hasField := operation["field"]
+		if !hasPath || hasField {
+			return false
+		}
+	}
+	return true
+}
+
+// readReplacementPatches reads PUT request body as the whole entity and translates it into catalog patches
+func (c *Context) readReplacementPatches(req *web.Request, entity interface{}) ([]models.Patch, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := c.mapper.ToPatchesByReplacement(entity, body, c.mapper.Username)
+	if err != nil {
+		return nil, err
+	}
+	return patches, c.mapper.ValidateEnumPatches(entity, patches)
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func TestPatchTemplateWithStandardPatches(t *testing.T) {
+	Convey("Testing PatchTemplate with JSON Patch and Merge Patch", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+		template := models.Template{Id: sampleID1, State: models.TemplateStateInProgress}
+		templatePath := "/api/v1/templates/" + sampleID1
+
+		Convey("When JSON Patch changes state to allowed one, response status is 200", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().AddStateHistoryRecord(context.buildStateHistoryKey(models.EntityTypeTemplate, sampleID1), gomock.Any(), defaultStateHistoryLimit).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+			)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "PATCH", templatePath, []byte(`[{"op":"replace","path":"/state","value":"READY"}]`),
+				http.Header{"Content-Type": {models.JsonPatchContentType}}, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When Merge Patch changes state to not allowed one, response status is 400", func() {
+			template.State = models.TemplateStateReady
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "PATCH", templatePath, []byte(`{"state":"IN_PROGRESS"}`),
+				http.Header{"Content-Type": {models.MergePatchContentType}}, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When JSON Patch changes immutable id, response status is 400", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil)
+
+			rr := sendAuthorizedRequestWithHeaders(context, "PATCH", templatePath, []byte(`[{"op":"replace","path":"/templateId","value":"2"}]`),
+				http.Header{"Content-Type": {models.JsonPatchContentType}}, t)
+
+			commonHttp.AssertResponse(rr, "can not be changed", http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestPutService(t *testing.T) {
+	Convey("Testing PutService", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+		service := models.Service{Id: sampleID1, Name: sampleName1, Description: "old", State: models.ServiceStateReady}
+		servicePath := "/api/v1/services/" + sampleID1
+
+		Convey("When name is changed, response status is 400", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil)
+
+			rr := sendAuthorizedRequest(context, "PUT", servicePath, []byte(`{"name":"`+sampleName2+`","description":"old"}`), t)
+
+			commonHttp.AssertResponse(rr, "can not be changed", http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,222 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const dependenciesField = "Dependencies"
+
+// CheckPlanDependencies re-validates dependencies of all plans and reports the ones which drifted -
+// referenced offering or plan no longer exists or stored names differ from the current ones
+func (c *Context) CheckPlanDependencies(rw web.ResponseWriter, req *web.Request) {
+	services, err := c.getServices()
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	servicesByID := mapServicesByID(services)
+
+	reports := []models.PlanDependencyReport{}
+	for _, service := range services {
+		for _, plan := range service.Plans {
+			issues := []string{}
+			for _, dependency := range plan.Dependencies {
+				issues = append(issues, getDependencyDrift(service.Id, dependency, servicesByID)...)
+			}
+
+			if len(issues) > 0 {
+				reports = append(reports, models.PlanDependencyReport{
+					ServiceId:   service.Id,
+					ServiceName: service.Name,
+					PlanId:      plan.Id,
+					PlanName:    plan.Name,
+					Issues:      issues,
+				})
+			}
+		}
+	}
+	commonHttp.WriteJson(rw, reports, http.StatusOK)
+}
+
+// resolvePlansDependencies validates dependencies of given plans and reconciles their names with referenced objects
+func (c *Context) resolvePlansDependencies(serviceID string, plans []models.ServicePlan) (int, error) {
+	if !hasDependencies(plans) {
+		return http.StatusOK, nil
+	}
+
+	services, err := c.getServices()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	servicesByID := mapServicesByID(services)
+
+	for i := range plans {
+		for j, dependency := range plans[i].Dependencies {
+			if plans[i].Dependencies[j], err = resolveServiceDependency(serviceID, dependency, servicesByID); err != nil {
+				return http.StatusBadRequest, err
+			}
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// resolveDependencyPatches does the same as resolvePlansDependencies for values of plan patches changing dependencies
+func (c *Context) resolveDependencyPatches(serviceID string, patches []models.Patch) (int, error) {
+	for i, patch := range patches {
+		if patch.Operation == models.OperationDelete || patch.Field == nil || patch.Value == nil ||
+			strings.Title(*patch.Field) != dependenciesField {
+			continue
+		}
+
+		dependencies := []models.ServiceDependency{}
+		isList := json.Unmarshal(*patch.Value, &dependencies) == nil
+		if !isList {
+			dependency := models.ServiceDependency{}
+			if err := json.Unmarshal(*patch.Value, &dependency); err != nil {
+				return http.StatusBadRequest, err
+			}
+			dependencies = append(dependencies, dependency)
+		}
+
+		plans := []models.ServicePlan{{Dependencies: dependencies}}
+		if status, err := c.resolvePlansDependencies(serviceID, plans); err != nil {
+			return status, err
+		}
+
+		var value []byte
+		var err error
+		if isList {
+			value, err = json.Marshal(plans[0].Dependencies)
+		} else {
+			value, err = json.Marshal(plans[0].Dependencies[0])
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		rawValue := json.RawMessage(value)
+		patches[i].Value = &rawValue
+	}
+	return http.StatusOK, nil
+}
+
+// resolvePlanPatches does the same as resolvePlansDependencies for values of service patches adding or replacing plans
+func (c *Context) resolvePlanPatches(serviceID string, patches []models.Patch) (int, error) {
+	for i, patch := range patches {
+		if patch.Operation == models.OperationDelete || patch.Field == nil || patch.Value == nil ||
+			strings.Title(*patch.Field) != data.Plans {
+			continue
+		}
+
+		plans := []models.ServicePlan{}
+		isList := json.Unmarshal(*patch.Value, &plans) == nil
+		if !isList {
+			plan := models.ServicePlan{}
+			if err := json.Unmarshal(*patch.Value, &plan); err != nil {
+				return http.StatusBadRequest, err
+			}
+			plans = append(plans, plan)
+		}
+
+		if status, err := c.resolvePlansDependencies(serviceID, plans); err != nil {
+			return status, err
+		}
+
+		var value []byte
+		var err error
+		if isList {
+			value, err = json.Marshal(plans)
+		} else {
+			value, err = json.Marshal(plans[0])
+		}
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		rawValue := json.RawMessage(value)
+		patches[i].Value = &rawValue
+	}
+	return http.StatusOK, nil
+}
+
+func resolveServiceDependency(serviceID string, dependency models.ServiceDependency, services map[string]models.Service) (models.ServiceDependency, error) {
+	if dependency.ServiceId == "" || dependency.PlanId == "" {
+		return dependency, errors.New("dependency has to contain service_id and plan_id")
+	}
+
+	if dependency.ServiceId == serviceID {
+		return dependency, fmt.Errorf("offering %q can not depend on itself", serviceID)
+	}
+
+	service, ok := services[dependency.ServiceId]
+	if !ok {
+		return dependency, fmt.Errorf("dependency offering %q does not exist", dependency.ServiceId)
+	}
+
+	for _, plan := range service.Plans {
+		if plan.Id == dependency.PlanId {
+			dependency.ServiceName = service.Name
+			dependency.PlanName = plan.Name
+			return dependency, nil
+		}
+	}
+	return dependency, fmt.Errorf("dependency plan %q of offering %q does not exist", dependency.PlanId, service.Name)
+}
+
+func getDependencyDrift(serviceID string, dependency models.ServiceDependency, services map[string]models.Service) []string {
+	resolved, err := resolveServiceDependency(serviceID, dependency, services)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	issues := []string{}
+	if resolved.ServiceName != dependency.ServiceName {
+		issues = append(issues, fmt.Sprintf("service_name of dependency on offering %q is %q, expected %q",
+			dependency.ServiceId, dependency.ServiceName, resolved.ServiceName))
+	}
+	if resolved.PlanName != dependency.PlanName {
+		issues = append(issues, fmt.Sprintf("plan_name of dependency on plan %q is %q, expected %q",
+			dependency.PlanId, dependency.PlanName, resolved.PlanName))
+	}
+	return issues
+}
+
+func hasDependencies(plans []models.ServicePlan) bool {
+	for _, plan := range plans {
+		if len(plan.Dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func mapServicesByID(services []models.Service) map[string]models.Service {
+	result := make(map[string]models.Service)
+	for _, service := range services {
+		result[service.Id] = service
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestPlanDependencies(t *testing.T) {
+	Convey("Testing plan dependencies validation", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+		service := models.Service{Id: sampleID1, Name: sampleName1}
+		dependencyService := models.Service{Id: sampleID2, Name: sampleName2, Plans: []models.ServicePlan{{Id: planId, Name: "free"}}}
+		plansPath := "/api/v1/services/" + sampleID1 + "/plans"
+		expectServices := func(services ...interface{}) {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(services, nil).AnyTimes()
+		}
+
+		Convey("When dependency references existing plan, its names should be filled in", func() {
+			expectServices(service, dependencyService)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil).Times(2)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{}, nil)
+
+			body := []byte(`{"name":"plan","dependencies":[{"service_id":"` + sampleID2 + `","plan_id":"` + planId + `","service_name":"old"}]}`)
+			rr := sendAuthorizedRequest(context, "POST", plansPath+"?dryRun=true", body, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			plan := models.ServicePlan{}
+			So(json.Unmarshal(rr.Body.Bytes(), &plan), ShouldBeNil)
+			So(plan.Dependencies[0].ServiceName, ShouldEqual, sampleName2)
+			So(plan.Dependencies[0].PlanName, ShouldEqual, "free")
+		})
+
+		Convey("When dependency references not existing plan, response status should be BadRequest", func() {
+			expectServices(service, dependencyService)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil)
+
+			body := []byte(`{"name":"plan","dependencies":[{"service_id":"` + sampleID2 + `","plan_id":"unknown"}]}`)
+			rr := sendAuthorizedRequest(context, "POST", plansPath, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "does not exist")
+		})
+
+		Convey("When offering depends on itself, response status should be BadRequest", func() {
+			expectServices(service, dependencyService)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil)
+
+			body := []byte(`{"name":"plan","dependencies":[{"service_id":"` + sampleID1 + `","plan_id":"` + planId + `"}]}`)
+			rr := sendAuthorizedRequest(context, "POST", plansPath, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "can not depend on itself")
+		})
+
+		Convey("When plan with not existing dependency is added by service patch, response status should be BadRequest", func() {
+			expectServices(service, dependencyService)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil)
+
+			body := []byte(`[{"op":"Add","field":"plans","value":{"id":"` + planId + `","name":"plan",` +
+				`"dependencies":[{"service_id":"` + sampleID2 + `","plan_id":"unknown"}]}}]`)
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/services/"+sampleID1, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "does not exist")
+		})
+
+		Convey("When service is replaced with plan depending on not existing offering, response status should be BadRequest", func() {
+			expectServices(service, dependencyService)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil)
+
+			body := []byte(`{"name":"` + sampleName1 + `","plans":[{"id":"` + planId + `","name":"plan",` +
+				`"dependencies":[{"service_id":"unknown","plan_id":"` + planId + `"}]}]}`)
+			rr := sendAuthorizedRequest(context, "PUT", "/api/v1/services/"+sampleID1, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "does not exist")
+		})
+
+		Convey("When dependencies are checked, plans with drifted names should be reported", func() {
+			service.Plans = []models.ServicePlan{{Id: sampleID1, Name: "plan", Dependencies: []models.ServiceDependency{
+				{ServiceId: sampleID2, ServiceName: sampleName2, PlanId: planId, PlanName: "renamed"},
+			}}}
+			expectServices(service, dependencyService)
+
+			reports, status, err := catalogClient.CheckPlanDependencies()
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(reports, ShouldHaveLength, 1)
+			So(reports[0].PlanId, ShouldEqual, sampleID1)
+			So(reports[0].Issues[0], ShouldContainSubstring, `is "renamed", expected "free"`)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,67 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	fieldsQueryParam  = "fields"
+	excludeQueryParam = "exclude"
+)
+
+// getFieldProjection returns nil projection if neither fields nor exclude query parameter was provided
+func getFieldProjection(req *web.Request, model interface{}) (*data.FieldProjection, error) {
+	return data.NewFieldProjection(model, getQueryParameterAsList(req, fieldsQueryParam), getQueryParameterAsList(req, excludeQueryParam))
+}
+
+func getQueryParameterAsList(req *web.Request, name string) []string {
+	result := []string{}
+	for _, value := range strings.Split(commonHttp.GetQueryParameterCaseInsensitive(req, name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func writeProjectedJsonOrError(rw web.ResponseWriter, projection *data.FieldProjection, response interface{}, status int, err error) {
+	if err != nil {
+		commonHttp.WriteJsonOrError(rw, response, status, err)
+		return
+	}
+	commonHttp.WriteJson(rw, projection.Apply(response), status)
+}
+
+func (c *Context) getDataWithProjection(key string, model interface{}, projection *data.FieldProjection) (interface{}, error) {
+	if projection == nil {
+		return c.repository.GetData(key, model)
+	}
+	return c.repository.GetDataWithProjection(key, model, projection)
+}
+
+func (c *Context) getListOfDataWithProjection(key string, model interface{}, projection *data.FieldProjection) ([]interface{}, error) {
+	if projection == nil {
+		return c.repository.GetListOfData(key, model)
+	}
+	return c.repository.GetListOfDataWithProjection(key, model, projection)
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func TestInstancesFieldProjection(t *testing.T) {
+	Convey("Testing Instances with field projection", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+
+		Convey("When fields parameter is provided only selected fields should be returned", func() {
+			instance := getSampleInstance()
+			instance.Id = instanceId
+
+			mocks.repositoryMock.EXPECT().GetListOfDataWithProjection(context.getInstanceKey(), models.Instance{}, gomock.Any()).
+				Return([]interface{}{instance}, nil)
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/instances?fields=id,name,state", nil, t)
+
+			commonHttp.AssertResponse(rr, `[{"id":"test-instance-id","name":"test-name","state":"RUNNING"}]`, http.StatusOK)
+		})
+
+		Convey("When exclude parameter is provided excluded fields should not be returned", func() {
+			instance := getSampleInstance()
+
+			mocks.repositoryMock.EXPECT().GetDataWithProjection(context.buildInstanceKey(instanceId), models.Instance{}, gomock.Any()).
+				Return(instance, nil)
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/instances/"+instanceId+"?exclude=bindings,metadata,auditTrail", nil, t)
+
+			commonHttp.AssertResponse(rr, `{"classId":"","id":"","name":"test-name","state":"RUNNING","type":"SERVICE"}`, http.StatusOK)
+		})
+
+		Convey("When service instances are listed with fields parameter, only selected fields should be read and returned", func() {
+			instance := getSampleInstance()
+			instance.Id = instanceId
+			applicationInstance := getSampleInstance()
+			applicationInstance.Type = models.InstanceTypeApplication
+
+			var readProjection *data.FieldProjection
+			mocks.repositoryMock.EXPECT().GetListOfDataWithProjection(context.getInstanceKey(), models.Instance{}, gomock.Any()).
+				Return([]interface{}{instance, applicationInstance}, nil).Do(
+				func(key string, model interface{}, projection *data.FieldProjection) {
+					readProjection = projection
+				})
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/services/instances?fields=id,name", nil, t)
+
+			commonHttp.AssertResponse(rr, `[{"id":"test-instance-id","name":"test-name"}]`, http.StatusOK)
+			So(readProjection.IsSelected("Type"), ShouldBeTrue)
+			So(readProjection.IsSelected("ClassId"), ShouldBeTrue)
+			So(readProjection.IsSelected("Bindings"), ShouldBeFalse)
+		})
+
+		Convey("When unknown field is requested response status is 400", func() {
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/instances?fields=unknown", nil, t)
+
+			commonHttp.AssertResponse(rr, "unknown field", http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
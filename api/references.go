@@ -0,0 +1,186 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// inboundReferences groups names of objects which refer to the one being deleted by their kind
+type inboundReferences struct {
+	kinds []string
+	names map[string][]string
+}
+
+func (r *inboundReferences) add(kind, name string) {
+	if r.names == nil {
+		r.names = make(map[string][]string)
+	}
+	if _, ok := r.names[kind]; !ok {
+		r.kinds = append(r.kinds, kind)
+	}
+	r.names[kind] = append(r.names[kind], name)
+}
+
+func (r *inboundReferences) toError(entityType models.EntityType, id string) error {
+	if len(r.kinds) == 0 {
+		return nil
+	}
+
+	groups := []string{}
+	for _, kind := range r.kinds {
+		groups = append(groups, fmt.Sprintf("%s: %s", kind, strings.Join(r.names[kind], ", ")))
+	}
+	return fmt.Errorf("%s %q is referenced by %s", strings.ToLower(string(entityType)), id, strings.Join(groups, "; "))
+}
+
+func (c *Context) assureTemplateIsNotUsed(templateID string) (int, error) {
+	references := inboundReferences{}
+
+	services, err := c.getServices()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, service := range services {
+		if service.TemplateId == templateID {
+			references.add("services", service.Name)
+		}
+	}
+
+	applications, err := c.getApplications()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, application := range applications {
+		if application.TemplateId == templateID {
+			references.add("applications", application.Name)
+		}
+	}
+
+	return referencesStatus(references.toError(models.EntityTypeTemplate, templateID))
+}
+
+func (c *Context) assureImageIsNotUsed(imageID string) (int, error) {
+	references := inboundReferences{}
+
+	applications, err := c.applicationImageRefs(imageID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, application := range applications {
+		references.add("applications", application.Name)
+	}
+
+	services, err := c.servicesImageRefs(imageID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, service := range services {
+		references.add("services", service.Name)
+	}
+
+	return referencesStatus(references.toError(models.EntityTypeImage, imageID))
+}
+
+func (c *Context) assureApplicationIsNotUsed(applicationID string) (int, error) {
+	references := inboundReferences{}
+
+	instances, err := c.getInstances()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, instance := range instances {
+		if instance.ClassId == applicationID {
+			references.add("instances", instance.Name)
+		}
+	}
+
+	return referencesStatus(references.toError(models.EntityTypeApplication, applicationID))
+}
+
+func (c *Context) assureInstanceIsNotUsed(instanceID string) (int, error) {
+	references := inboundReferences{}
+
+	instances, err := c.getInstances()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, instance := range instances {
+		if instance.Id == instanceID {
+			continue
+		}
+		for _, binding := range instance.Bindings {
+			if binding.Id == instanceID {
+				references.add("instance bindings", instance.Name)
+			}
+		}
+	}
+
+	applications, err := c.getApplications()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, application := range applications {
+		for _, dependency := range application.InstanceDependencies {
+			if dependency.Id == instanceID {
+				references.add("application instance dependencies", application.Name)
+			}
+		}
+	}
+
+	return referencesStatus(references.toError(models.EntityTypeInstance, instanceID))
+}
+
+func (c *Context) assurePlanIsNotUsed(serviceID, planID string) (int, error) {
+	references := inboundReferences{}
+
+	instances, err := c.getFilteredInstances(models.InstanceTypeService, serviceID)
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, instance := range instances {
+		if models.GetValueFromMetadata(instance.Metadata, models.OFFERING_PLAN_ID) == planID {
+			references.add("instances", instance.Name)
+		}
+	}
+
+	services, err := c.getServices()
+	if err != nil {
+		return getHttpStatusOrStatusError(http.StatusInternalServerError, err), err
+	}
+	for _, service := range services {
+		for _, plan := range service.Plans {
+			for _, dependency := range plan.Dependencies {
+				if dependency.PlanId == planID {
+					references.add("plans", fmt.Sprintf("%s/%s", service.Name, plan.Name))
+				}
+			}
+		}
+	}
+
+	return referencesStatus(references.toError(models.EntityTypePlan, planID))
+}
+
+func referencesStatus(err error) (int, error) {
+	if err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
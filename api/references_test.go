@@ -0,0 +1,79 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestDeleteReferencedObjects(t *testing.T) {
+	Convey("Testing referential integrity on delete", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+		application := models.Application{Id: sampleID1, Name: sampleName1, ImageId: sampleID2, TemplateId: sampleID2,
+			InstanceDependencies: []models.InstanceDependency{{Id: sampleID2}}}
+		instance := models.Instance{Id: sampleID1, Name: sampleName1, ClassId: sampleID1,
+			Bindings: []models.InstanceBindings{{Id: sampleID2}}}
+
+		Convey("When Template is used by application, response status should be Forbidden", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{application}, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/templates/"+sampleID2, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusForbidden)
+			So(rr.Body.String(), ShouldContainSubstring, "referenced by applications: "+sampleName1)
+		})
+
+		Convey("When Image is used by application, response status should be Forbidden", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{application}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/images/"+sampleID2, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusForbidden)
+			So(rr.Body.String(), ShouldContainSubstring, "referenced by applications: "+sampleName1)
+		})
+
+		Convey("When Application has instances, response status should be Forbidden", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{instance}, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/applications/"+sampleID1, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusForbidden)
+			So(rr.Body.String(), ShouldContainSubstring, "referenced by instances: "+sampleName1)
+		})
+
+		Convey("When Instance is bound and required by application, both references should be listed", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{instance}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{application}, nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/instances/"+sampleID2, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusForbidden)
+			So(rr.Body.String(), ShouldContainSubstring, "instance bindings: "+sampleName1)
+			So(rr.Body.String(), ShouldContainSubstring, "application instance dependencies: "+sampleName1)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
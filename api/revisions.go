@@ -0,0 +1,297 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	revisionsLimitEnv     = "REVISIONS_LIMIT"
+	defaultRevisionsLimit = 20
+
+	// compareToQueryParam gives revision to which the revision is compared, current object is used without it
+	compareToQueryParam = "to"
+)
+
+// revisionTarget is an object which revisions are stored - ParentId is set only for plans (service id)
+type revisionTarget struct {
+	entityType models.EntityType
+	id         string
+	parentId   string
+	key        string
+	model      interface{}
+}
+
+var revisionDirs = map[models.EntityType]string{
+	models.EntityTypeService:     data.Services,
+	models.EntityTypePlan:        data.Plans,
+	models.EntityTypeApplication: data.Applications,
+}
+
+func (c *Context) getServiceRevisionTarget(req *web.Request) revisionTarget {
+	serviceId := req.PathParams["serviceId"]
+	return revisionTarget{entityType: models.EntityTypeService, id: serviceId, key: c.buildServiceKey(serviceId), model: models.Service{}}
+}
+
+func (c *Context) getPlanRevisionTarget(req *web.Request) revisionTarget {
+	serviceId := req.PathParams["serviceId"]
+	planId := req.PathParams["planId"]
+	return revisionTarget{entityType: models.EntityTypePlan, id: planId, parentId: serviceId,
+		key: c.getServicedPlanIDKey(serviceId, planId), model: models.ServicePlan{}}
+}
+
+func (c *Context) getApplicationRevisionTarget(req *web.Request) revisionTarget {
+	applicationId := req.PathParams["applicationId"]
+	return revisionTarget{entityType: models.EntityTypeApplication, id: applicationId,
+		key: c.buildApplicationKey(applicationId), model: models.Application{}}
+}
+
+func (c *Context) ServiceRevisions(rw web.ResponseWriter, req *web.Request) {
+	c.listRevisions(rw, c.getServiceRevisionTarget(req))
+}
+
+func (c *Context) GetServiceRevision(rw web.ResponseWriter, req *web.Request) {
+	c.getRevision(rw, req, c.getServiceRevisionTarget(req))
+}
+
+func (c *Context) ServiceRevisionDiff(rw web.ResponseWriter, req *web.Request) {
+	c.diffRevision(rw, req, c.getServiceRevisionTarget(req))
+}
+
+func (c *Context) RestoreServiceRevision(rw web.ResponseWriter, req *web.Request) {
+	c.restoreRevision(rw, req, c.getServiceRevisionTarget(req), c.updateService)
+}
+
+func (c *Context) PlanRevisions(rw web.ResponseWriter, req *web.Request) {
+	c.listRevisions(rw, c.getPlanRevisionTarget(req))
+}
+
+func (c *Context) GetPlanRevision(rw web.ResponseWriter, req *web.Request) {
+	c.getRevision(rw, req, c.getPlanRevisionTarget(req))
+}
+
+func (c *Context) PlanRevisionDiff(rw web.ResponseWriter, req *web.Request) {
+	c.diffRevision(rw, req, c.getPlanRevisionTarget(req))
+}
+
+func (c *Context) RestorePlanRevision(rw web.ResponseWriter, req *web.Request) {
+	c.restoreRevision(rw, req, c.getPlanRevisionTarget(req), c.updatePlan)
+}
+
+func (c *Context) ApplicationRevisions(rw web.ResponseWriter, req *web.Request) {
+	c.listRevisions(rw, c.getApplicationRevisionTarget(req))
+}
+
+func (c *Context) GetApplicationRevision(rw web.ResponseWriter, req *web.Request) {
+	c.getRevision(rw, req, c.getApplicationRevisionTarget(req))
+}
+
+func (c *Context) ApplicationRevisionDiff(rw web.ResponseWriter, req *web.Request) {
+	c.diffRevision(rw, req, c.getApplicationRevisionTarget(req))
+}
+
+func (c *Context) RestoreApplicationRevision(rw web.ResponseWriter, req *web.Request) {
+	c.restoreRevision(rw, req, c.getApplicationRevisionTarget(req), c.updateApplication)
+}
+
+// listRevisions responds with NotFound only if object has no revisions and does not exist. Revisions of object
+// moved to trash are kept, so they are complete after it is restored from trash (revision can be restored only
+// to existing object) - they are removed when the object is purged or when its trash entry expires.
+func (c *Context) listRevisions(rw web.ResponseWriter, target revisionTarget) {
+	revisions, err := c.repository.GetRevisions(c.buildRevisionsKey(target))
+	if err != nil {
+		if !commonHttp.IsNotFoundError(err) {
+			commonHttp.HandleError(rw, err)
+			return
+		}
+
+		if _, err := c.repository.GetData(target.key, target.model); err != nil {
+			commonHttp.HandleError(rw, err)
+			return
+		}
+		revisions = []models.Revision{}
+	}
+	commonHttp.WriteJson(rw, revisions, http.StatusOK)
+}
+
+func (c *Context) getRevision(rw web.ResponseWriter, req *web.Request, target revisionTarget) {
+	revision, status, err := c.getRevisionByNumber(target, req.PathParams["rev"])
+	if err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+	commonHttp.WriteJson(rw, revision, http.StatusOK)
+}
+
+// diffRevision responds with changes which lead from the revision to the one given in query or to current object
+func (c *Context) diffRevision(rw web.ResponseWriter, req *web.Request, target revisionTarget) {
+	revision, status, err := c.getRevisionByNumber(target, req.PathParams["rev"])
+	if err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+	before, err := readRevisionEntity(target, revision)
+	if err != nil {
+		commonHttp.Respond500(rw, err)
+		return
+	}
+
+	var after interface{}
+	if compareTo := req.URL.Query().Get(compareToQueryParam); compareTo != "" {
+		compared, status, err := c.getRevisionByNumber(target, compareTo)
+		if err != nil {
+			commonHttp.GenericRespond(status, rw, err)
+			return
+		}
+		if after, err = readRevisionEntity(target, compared); err != nil {
+			commonHttp.Respond500(rw, err)
+			return
+		}
+	} else if after, err = c.repository.GetData(target.key, target.model); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	changes, err := models.DiffEntities(before, after)
+	commonHttp.WriteJsonOrError(rw, changes, http.StatusOK, err)
+}
+
+// restoreRevision replaces object with the revision through the same path as PUT request, so the result is
+// validated the same way (and dry run is supported). State is not restored - it follows its own transitions.
+func (c *Context) restoreRevision(rw web.ResponseWriter, req *web.Request, target revisionTarget,
+	update func(web.ResponseWriter, *web.Request, patchesReader)) {
+
+	revision, status, err := c.getRevisionByNumber(target, req.PathParams["rev"])
+	if err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	update(rw, req, func(req *web.Request, entity interface{}) ([]models.Patch, error) {
+		snapshot := map[string]interface{}{}
+		if err := json.Unmarshal(revision.Entity, &snapshot); err != nil {
+			return nil, fmt.Errorf("cannot read revision %d: %v", revision.Revision, err)
+		}
+		delete(snapshot, "state")
+
+		replacement, err := json.Marshal(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		patches, err := c.mapper.ToPatchesByReplacement(entity, replacement, c.mapper.Username)
+		if err != nil {
+			return nil, err
+		}
+		return patches, c.mapper.ValidateEnumPatches(entity, patches)
+	})
+}
+
+func (c *Context) getRevisionByNumber(target revisionTarget, value string) (models.Revision, int, error) {
+	number, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return models.Revision{}, http.StatusBadRequest, fmt.Errorf("revision %q must match unsigned integer", value)
+	}
+
+	revision, err := c.repository.GetRevision(c.buildRevisionsKey(target), number)
+	if err != nil {
+		if commonHttp.IsNotFoundError(err) {
+			return revision, http.StatusNotFound, fmt.Errorf("revision %d of %s %q not found", number, target.entityType, target.id)
+		}
+		return revision, http.StatusInternalServerError, err
+	}
+	return revision, http.StatusOK, nil
+}
+
+func readRevisionEntity(target revisionTarget, revision models.Revision) (interface{}, error) {
+	entity := reflect.New(reflect.TypeOf(target.model))
+	if err := json.Unmarshal(revision.Entity, entity.Interface()); err != nil {
+		return nil, fmt.Errorf("cannot read revision %d of %s %q: %v", revision.Revision, target.entityType, target.id, err)
+	}
+	return entity.Elem().Interface(), nil
+}
+
+// recordRevision stores current version of object as its next revision, objects of other types than services, plans
+// and applications are skipped. Change is already written at this point, so failure is only logged.
+func (c *Context) recordRevision(entityType models.EntityType, id, parentId string, entity interface{}) {
+	if _, ok := revisionDirs[entityType]; !ok {
+		return
+	}
+
+	target := revisionTarget{entityType: entityType, id: id, parentId: parentId}
+	entityJson, err := json.Marshal(entity)
+	if err != nil {
+		logger.Errorf("cannot marshal revision of %s %q: %v", target.entityType, target.id, err)
+		return
+	}
+
+	revision := models.Revision{CreatedOn: time.Now().Unix(), Entity: entityJson}
+	if _, err := c.repository.AddRevision(c.buildRevisionsKey(target), revision, getRevisionsLimit()); err != nil {
+		logger.Errorf("cannot add revision of %s %q: %v", target.entityType, target.id, err)
+	}
+}
+
+// removeRevisions drops revisions of purged object, including revisions of plans of purged service.
+// Object is already removed at this point, so failure is only logged.
+func (c *Context) removeRevisions(entityType models.EntityType, id, parentId string) {
+	if _, ok := revisionDirs[entityType]; !ok {
+		return
+	}
+
+	keys := []string{c.buildRevisionsKey(revisionTarget{entityType: entityType, id: id, parentId: parentId})}
+	if entityType == models.EntityTypeService {
+		plansRevisionsKey := c.mapper.ToKey(c.getRevisionsDirKey(), data.Plans)
+		keys = append(keys, c.mapper.ToKey(plansRevisionsKey, id))
+	}
+	for _, key := range keys {
+		if err := c.repository.DeleteRevisions(key); err != nil {
+			logger.Errorf("cannot remove revisions of %s %q: %v", entityType, id, err)
+		}
+	}
+}
+
+func getRevisionsLimit() int {
+	limit, err := strconv.Atoi(os.Getenv(revisionsLimitEnv))
+	if err != nil || limit <= 0 {
+		return defaultRevisionsLimit
+	}
+	return limit
+}
+
+func (c *Context) buildRevisionsKey(target revisionTarget) string {
+	revisionsKey := c.mapper.ToKey(c.getRevisionsDirKey(), revisionDirs[target.entityType])
+	if target.parentId != "" {
+		revisionsKey = c.mapper.ToKey(revisionsKey, target.parentId)
+	}
+	return c.mapper.ToKey(revisionsKey, target.id)
+}
+
+func (c *Context) getRevisionsDirKey() string {
+	return data.GetEntityKey(c.organization, data.Revisions)
+}
@@ -0,0 +1,158 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestServiceRevisions(t *testing.T) {
+	Convey("Testing service revisions", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareStrictMocksAndClient(t)
+		revisionsKey := context.buildRevisionsKey(revisionTarget{entityType: models.EntityTypeService, id: sampleID1})
+		revisionsPath := "/api/v1/services/" + sampleID1 + "/revisions"
+
+		current := models.Service{Id: sampleID1, Name: sampleName1, Description: "new", State: models.ServiceStateReady}
+		previous := models.Service{Id: sampleID1, Name: sampleName1, Description: "old", State: models.ServiceStateOffline}
+		getRevision := func(number uint64, service models.Service) models.Revision {
+			serviceJson, _ := json.Marshal(service)
+			return models.Revision{Revision: number, CreatedOn: 1, Entity: serviceJson}
+		}
+
+		Convey("When revisions are listed, stored revisions should be returned", func() {
+			revisions := []models.Revision{getRevision(1, previous), getRevision(2, current)}
+			mocks.repositoryMock.EXPECT().GetRevisions(revisionsKey).Return(revisions, nil)
+
+			result, status, err := catalogClient.ListServiceRevisions(sampleID1)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result, ShouldHaveLength, 2)
+			So(result[1].Revision, ShouldEqual, 2)
+		})
+
+		Convey("When service has no revisions and does not exist, response status should be NotFound", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetRevisions(revisionsKey).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(nil, errors.New("Key not found")),
+			)
+
+			rr := sendAuthorizedRequest(context, "GET", revisionsPath, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When revision number is not a number, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "GET", revisionsPath+"/latest", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When revision does not exist, response status should be NotFound", func() {
+			mocks.repositoryMock.EXPECT().GetRevision(revisionsKey, uint64(3)).Return(models.Revision{}, errors.New("Key not found"))
+
+			rr := sendAuthorizedRequest(context, "GET", revisionsPath+"/3", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When two revisions are compared, changed fields should be returned", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetRevision(revisionsKey, uint64(1)).Return(getRevision(1, previous), nil),
+				mocks.repositoryMock.EXPECT().GetRevision(revisionsKey, uint64(2)).Return(getRevision(2, current), nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "GET", revisionsPath+"/1/diff?to=2", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			changes := []models.FieldChange{}
+			So(json.Unmarshal(rr.Body.Bytes(), &changes), ShouldBeNil)
+			So(changes, ShouldHaveLength, 2)
+			So(changes[0].Field, ShouldEqual, "description")
+			So(changes[1].Field, ShouldEqual, "state")
+		})
+
+		Convey("When revision is restored, fields except state should be replaced and new revision stored", func() {
+			restored := current
+			restored.Description = previous.Description
+			var auditEntry models.AuditEntry
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetRevision(revisionsKey, uint64(1)).Return(getRevision(1, previous), nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(current, nil),
+				mocks.repositoryMock.EXPECT().ApplyPatchedValues(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().AddAuditEntry(context.getAuditKey(), gomock.Any(), defaultAuditRetention).Do(
+					func(key string, entry models.AuditEntry, ttl time.Duration) { auditEntry = entry }).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(restored, nil),
+				mocks.repositoryMock.EXPECT().AddRevision(revisionsKey, gomock.Any(), defaultRevisionsLimit).Return(getRevision(2, restored), nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "POST", revisionsPath+"/1/restore", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(auditEntry.Changes, ShouldHaveLength, 1)
+			So(auditEntry.Changes[0].Field, ShouldEqual, "description")
+			So(string(auditEntry.Changes[0].NewValue), ShouldEqual, `"old"`)
+		})
+
+		Convey("When revision restore is a dry run, nothing should be written", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetRevision(revisionsKey, uint64(1)).Return(getRevision(1, previous), nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(current, nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "POST", revisionsPath+"/1/restore?dryRun=true", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			service := models.Service{}
+			So(json.Unmarshal(rr.Body.Bytes(), &service), ShouldBeNil)
+			So(service.Description, ShouldEqual, previous.Description)
+			So(service.State, ShouldEqual, current.State)
+		})
+
+		Convey("When service is purged, its revisions and revisions of its plans should be removed", func() {
+			plansRevisionsKey := context.mapper.ToKey(context.mapper.ToKey(context.getRevisionsDirKey(), data.Plans), sampleID1)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(current, nil),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().AddAuditEntry(context.getAuditKey(), gomock.Any(), defaultAuditRetention).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteStateHistory(context.buildStateHistoryKey(models.EntityTypeService, sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteRevisions(revisionsKey).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteRevisions(plansRevisionsKey).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/services/"+sampleID1+"?purge=true", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
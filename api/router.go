@@ -19,13 +19,22 @@ import (
 	"net/http"
 
 	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/metrics"
 	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 )
 
 func SetupRouter(context Context) *web.Router {
 	r := web.New(context)
 	r.Middleware(web.LoggerMiddleware)
+	r.Middleware(metrics.HttpMiddleware)
 	r.Get("/healthz", context.GetCatalogHealth)
+	r.Get("/healthz/live", context.GetLiveness)
+	r.Get("/healthz/ready", context.GetReadiness)
+
+	healthRouter := r.Subrouter(context, "/healthz")
+	healthRouter.Middleware(context.BasicAuthorizeMiddleware)
+	healthRouter.Get("/details", context.GetHealthDetails)
 
 	apiRouter := r.Subrouter(context, "/api")
 
@@ -43,17 +52,36 @@ func SetupRouter(context Context) *web.Router {
 func route(router *web.Router, context *Context) {
 	router.Middleware(context.BasicAuthorizeMiddleware)
 	router.Middleware(context.OrganizationSetupMiddleware)
+	router.Middleware(context.IdempotencyMiddleware)
 
 	router.Get("/services", context.Services)
+	router.Get("/services/next-state", context.MonitorServicesStates)
+	router.Get("/services/next-change", context.MonitorServicesChanges)
 	router.Get("/services/:serviceId", context.GetService)
+	router.Get("/services/:serviceId/next-state", context.MonitorSpecificServiceState)
+	router.Get("/services/:serviceId/next-change", context.MonitorSpecificServiceChange)
+	router.Get("/services/:serviceId/history", context.GetServiceHistory)
+	router.Get("/services/:serviceId/revisions", context.ServiceRevisions)
+	router.Get("/services/:serviceId/revisions/:rev", context.GetServiceRevision)
+	router.Get("/services/:serviceId/revisions/:rev/diff", context.ServiceRevisionDiff)
+	router.Post("/services/:serviceId/revisions/:rev/restore", context.RestoreServiceRevision)
 	router.Post("/services", context.AddService)
 	router.Patch("/services/:serviceId", context.PatchService)
+	router.Put("/services/:serviceId", context.PutService)
 	router.Delete("/services/:serviceId", context.DeleteService)
 
+	router.Get("/services/plans/check-dependencies", context.CheckPlanDependencies)
 	router.Get("/services/:serviceId/plans", context.Plans)
 	router.Get("/services/:serviceId/plans/:planId", context.GetPlan)
+	router.Get("/services/:serviceId/plans/:planId/next-state", context.MonitorSpecificPlanState)
+	router.Get("/services/:serviceId/plans/:planId/next-change", context.MonitorSpecificPlanChange)
+	router.Get("/services/:serviceId/plans/:planId/revisions", context.PlanRevisions)
+	router.Get("/services/:serviceId/plans/:planId/revisions/:rev", context.GetPlanRevision)
+	router.Get("/services/:serviceId/plans/:planId/revisions/:rev/diff", context.PlanRevisionDiff)
+	router.Post("/services/:serviceId/plans/:planId/revisions/:rev/restore", context.RestorePlanRevision)
 	router.Post("/services/:serviceId/plans", context.AddPlan)
 	router.Patch("/services/:serviceId/plans/:planId", context.PatchPlan)
+	router.Put("/services/:serviceId/plans/:planId", context.PutPlan)
 	router.Delete("/services/:serviceId/plans/:planId", context.DeletePlan)
 
 	router.Get("/services/instances", context.ServicesInstances)
@@ -64,9 +92,18 @@ func route(router *web.Router, context *Context) {
 	router.Delete("/services/:serviceId/instances/:instanceId", context.DeleteServiceInstance)
 
 	router.Get("/applications", context.Applications)
+	router.Get("/applications/next-state", context.MonitorApplicationsStates)
+	router.Get("/applications/next-change", context.MonitorApplicationsChanges)
 	router.Get("/applications/:applicationId", context.GetApplication)
+	router.Get("/applications/:applicationId/next-state", context.MonitorSpecificApplicationState)
+	router.Get("/applications/:applicationId/next-change", context.MonitorSpecificApplicationChange)
+	router.Get("/applications/:applicationId/revisions", context.ApplicationRevisions)
+	router.Get("/applications/:applicationId/revisions/:rev", context.GetApplicationRevision)
+	router.Get("/applications/:applicationId/revisions/:rev/diff", context.ApplicationRevisionDiff)
+	router.Post("/applications/:applicationId/revisions/:rev/restore", context.RestoreApplicationRevision)
 	router.Post("/applications", context.AddApplication)
 	router.Patch("/applications/:applicationId", context.PatchApplication)
+	router.Put("/applications/:applicationId", context.PutApplication)
 	router.Delete("/applications/:applicationId", context.DeleteApplication)
 
 	router.Get("/applications/instances", context.ApplicationsInstances)
@@ -78,26 +115,62 @@ func route(router *web.Router, context *Context) {
 
 	router.Get("/images", context.Images)
 	router.Get("/images/next-state", context.MonitorImagesStates)
+	router.Get("/images/next-change", context.MonitorImagesChanges)
 	router.Get("/images/:imageId", context.GetImage)
 	router.Get("/images/:imageId/next-state", context.MonitorSpecificImageState)
+	router.Get("/images/:imageId/next-change", context.MonitorSpecificImageChange)
+	router.Get("/images/:imageId/history", context.GetImageHistory)
 	router.Post("/images", context.AddImage)
 	router.Patch("/images/:imageId", context.PatchImage)
+	router.Put("/images/:imageId", context.PutImage)
 	router.Delete("/images/:imageId", context.DeleteImage)
 	router.Get("/images/:imageId/check-refs", context.GetImageCheckRefs)
 
 	router.Get("/instances", context.Instances)
 	router.Get("/instances/next-state", context.MonitorInstancesStates)
+	router.Get("/instances/next-change", context.MonitorInstancesChanges)
 	router.Get("/instances/:instanceId", context.GetInstance)
 	router.Get("/instances/:instanceId/next-state", context.MonitorSpecificInstanceState)
+	router.Get("/instances/:instanceId/next-change", context.MonitorSpecificInstanceChange)
+	router.Get("/instances/:instanceId/history", context.GetInstanceHistory)
 	router.Get("/instances/:instanceId/bindings", context.GetInstanceBindings)
+	router.Post("/instances/:instanceId/bindings", context.AddInstanceBinding)
+	router.Delete("/instances/:instanceId/bindings/:targetId", context.DeleteInstanceBinding)
+	router.Get("/instances/:instanceId/bound-by", context.GetInstanceBoundBy)
 	router.Delete("/instances/:instanceId", context.DeleteInstance)
 	router.Patch("/instances/:instanceId", context.PatchInstance)
+	router.Put("/instances/:instanceId", context.PutInstance)
 
 	router.Get("/templates", context.Templates)
+	router.Get("/templates/next-state", context.MonitorTemplatesStates)
+	router.Get("/templates/next-change", context.MonitorTemplatesChanges)
 	router.Post("/templates", context.AddTemplate)
 	router.Get("/templates/:templateId", context.GetTemplate)
+	router.Get("/templates/:templateId/next-state", context.MonitorSpecificTemplateState)
+	router.Get("/templates/:templateId/next-change", context.MonitorSpecificTemplateChange)
+	router.Get("/templates/:templateId/history", context.GetTemplateHistory)
 	router.Delete("/templates/:templateId", context.DeleteTemplate)
 	router.Patch("/templates/:templateId", context.PatchTemplate)
+	router.Put("/templates/:templateId", context.PutTemplate)
+
+	router.Get("/trash", context.Trash)
+	router.Get("/trash/:resource/:id", context.GetTrashEntry)
+	router.Post("/trash/:resource/:id/restore", context.RestoreTrashEntry)
+	router.Delete("/trash/:resource/:id", context.PurgeTrashEntry)
+
+	router.Get("/audit", context.Audit)
+
+	router.Post("/batch", context.Batch)
+
+	router.Get("/graph", context.Graph)
+
+	router.Get("/state-machines", context.StateMachines)
+	router.Get("/state-machines/:kind", context.GetStateMachine)
+
+	router.Get("/settings", context.GetSettings)
+	router.Put("/settings", context.PutSettings)
+
+	router.Get("/events", context.Events)
 
 	router.Get("/latest-index", context.LatestIndex)
 	router.Get("/stable-state", context.CheckStateStability)
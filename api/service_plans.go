@@ -28,6 +28,13 @@ import (
 
 func (c *Context) Plans(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
+
+	projection, err := getFieldProjection(req, models.ServicePlan{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
 	services, err := c.repository.GetData(c.buildServiceKey(serviceId), models.Service{})
 	if err != nil {
 		commonHttp.HandleError(rw, err)
@@ -37,17 +44,23 @@ func (c *Context) Plans(rw web.ResponseWriter, req *web.Request) {
 	if services.(models.Service).Plans != nil {
 		plans = services.(models.Service).Plans
 	}
-	commonHttp.WriteJson(rw, plans, http.StatusOK)
+	commonHttp.WriteJson(rw, projection.Apply(plans), http.StatusOK)
 }
 
 func (c *Context) GetPlan(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
 	planId := req.PathParams["planId"]
 
+	projection, err := getFieldProjection(req, models.ServicePlan{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
 	key := c.mapper.ToKey(c.getServicePlansDir(serviceId), planId)
 
-	result, err := c.repository.GetData(key, models.ServicePlan{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	result, err := c.getDataWithProjection(key, models.ServicePlan{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) AddPlan(rw web.ResponseWriter, req *web.Request) {
@@ -72,6 +85,25 @@ func (c *Context) AddPlan(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	plans := []models.ServicePlan{*reqPlan}
+	if status, err := c.resolvePlansDependencies(serviceId, plans); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+	reqPlan.Dependencies = plans[0].Dependencies
+
+	if len(reqPlan.Dependencies) > 0 {
+		if status, err := c.assureNoNewPlanDependencyCycle(serviceId, *reqPlan); err != nil {
+			commonHttp.GenericRespond(status, rw, err)
+			return
+		}
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqPlan)
+		return
+	}
+
 	if reqPlan.Id, err = c.reserveID(c.getServicePlansDir(serviceId)); err != nil {
 		commonHttp.Respond500(rw, err)
 		return
@@ -83,12 +115,24 @@ func (c *Context) AddPlan(rw web.ResponseWriter, req *web.Request) {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypePlan, reqPlan.Id, serviceId, reqPlan)
 
 	plan, err := c.repository.GetData(c.getServicedPlanIDKey(serviceId, reqPlan.Id), models.ServicePlan{})
+	if err == nil {
+		c.recordRevision(models.EntityTypePlan, reqPlan.Id, serviceId, plan)
+	}
 	commonHttp.WriteJsonOrError(rw, plan, http.StatusCreated, err)
 }
 
 func (c *Context) PatchPlan(rw web.ResponseWriter, req *web.Request) {
+	c.updatePlan(rw, req, c.readPatches)
+}
+
+func (c *Context) PutPlan(rw web.ResponseWriter, req *web.Request) {
+	c.updatePlan(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updatePlan(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	serviceId := req.PathParams["serviceId"]
 	planId := req.PathParams["planId"]
 
@@ -98,26 +142,44 @@ func (c *Context) PatchPlan(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, plan)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
 	}
 
+	if status, err := c.resolveDependencyPatches(serviceId, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
 	patchedValues, err := c.mapper.ToKeyValueByPatches(c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}, patches)
 	if err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
 
+	if status, err := c.assureNoNewDependencyCycleByPlanPatches(serviceId, plan, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, plan, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordUpdateAudit(models.EntityTypePlan, planId, serviceId, plan, patches)
 
 	plan, err = c.repository.GetData(c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{})
+	if err == nil {
+		c.recordRevision(models.EntityTypePlan, planId, serviceId, plan)
+	}
 	commonHttp.WriteJsonOrError(rw, plan, http.StatusOK, err)
 }
 
@@ -125,34 +187,42 @@ func (c *Context) DeletePlan(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
 	planId := req.PathParams["planId"]
 
-	if _, err := c.repository.GetData(c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}); err != nil {
+	plan, err := c.repository.GetData(c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{})
+	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
 
-	instances, err := c.getFilteredInstances(models.InstanceTypeService, serviceId)
-	if err != nil {
-		commonHttp.HandleError(rw, err)
+	if status, err := c.assurePlanIsNotUsed(serviceId, planId); err != nil {
+		err := fmt.Errorf("cannot remove plan %q: %v", planId, err)
+		commonHttp.GenericRespond(status, rw, err)
 		return
 	}
 
-	if err = checkIfPlanIsNotUsedByInstance(planId, instances); err != nil {
-		commonHttp.Respond400(rw, err)
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{})
 		return
 	}
 
-	err = c.repository.DeleteData(c.getServicedPlanIDKey(serviceId, planId))
+	err = c.purgeEntity(c.getServicedPlanIDKey(serviceId, planId), models.EntityTypePlan, planId, serviceId, plan)
 	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
 }
 
-func checkIfPlanIsNotUsedByInstance(planId string, instances []models.Instance) error {
-	for _, instance := range instances {
-		instancePlanId := models.GetValueFromMetadata(instance.Metadata, models.OFFERING_PLAN_ID)
-		if planId == instancePlanId {
-			return fmt.Errorf("plan with id: %s can not be deleted - is in use by instance: %s, instanceId: %s", planId, instance.Name, instance.Id)
-		}
+// Plan has no State - it is available as long as its offering is, so the offering State is monitored
+func (c *Context) MonitorSpecificPlanState(rw web.ResponseWriter, req *web.Request) {
+	serviceId := req.PathParams["serviceId"]
+	planId := req.PathParams["planId"]
+	if _, err := c.repository.GetData(c.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
 	}
-	return nil
+	c.monitorSpecificState(rw, req, c.buildServiceKey(serviceId))
+}
+
+func (c *Context) MonitorSpecificPlanChange(rw web.ResponseWriter, req *web.Request) {
+	serviceId := req.PathParams["serviceId"]
+	planId := req.PathParams["planId"]
+	c.monitorSpecificChange(rw, req, c.getServicedPlanIDKey(serviceId, planId))
 }
 
 func (c *Context) getServicedPlanIDKey(serviceId, planId string) string {
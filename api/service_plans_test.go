@@ -37,6 +37,7 @@ func TestDeletePlan(t *testing.T) {
 			gomock.InOrder(
 				mocks.repositoryMock.EXPECT().GetData(context.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}).Return(samplePlan, nil),
 				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil),
 				mocks.repositoryMock.EXPECT().DeleteData(context.getServicedPlanIDKey(serviceId, planId)).Return(nil),
 			)
 
@@ -56,12 +57,30 @@ func TestDeletePlan(t *testing.T) {
 			gomock.InOrder(
 				mocks.repositoryMock.EXPECT().GetData(context.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}).Return(samplePlan, nil),
 				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil),
 			)
 
 			status, err := catalogClient.DeleteServicePlan(serviceId, planId)
 			So(err, ShouldNotBeNil)
-			So(status, ShouldEqual, http.StatusBadRequest)
-			So(err.Error(), ShouldContainSubstring, "can not be deleted - is in use by instance")
+			So(status, ShouldEqual, http.StatusForbidden)
+			So(err.Error(), ShouldContainSubstring, "is referenced by instances: "+sampleInstances[0].Name)
+		})
+
+		Convey("Should return error on delete plan which other plan depends on", func() {
+			dependentService := models.Service{Name: sampleName2, Plans: []models.ServicePlan{
+				{Name: sampleName1, Dependencies: []models.ServiceDependency{{ServiceId: serviceId, PlanId: planId}}},
+			}}
+
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.getServicedPlanIDKey(serviceId, planId), models.ServicePlan{}).Return(samplePlan, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{dependentService}, nil),
+			)
+
+			status, err := catalogClient.DeleteServicePlan(serviceId, planId)
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusForbidden)
+			So(err.Error(), ShouldContainSubstring, "is referenced by plans: "+sampleName2+"/"+sampleName1)
 		})
 
 		Reset(func() {
@@ -22,7 +22,6 @@ import (
 	"strings"
 
 	"github.com/gocraft/web"
-	"github.com/looplab/fsm"
 
 	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
@@ -52,8 +51,20 @@ func (c *Context) getServices() ([]models.Service, error) {
 }
 
 func (c *Context) Services(rw web.ResponseWriter, req *web.Request) {
-	result, err := c.getServices()
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Service{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if projection == nil {
+		result, err := c.getServices()
+		commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+		return
+	}
+
+	result, err := c.getListOfDataWithProjection(c.getServiceKey(), models.Service{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) getService(id string) (models.Service, error) {
@@ -77,8 +88,20 @@ func (c *Context) getService(id string) (models.Service, error) {
 func (c *Context) GetService(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
 
-	service, err := c.getService(serviceId)
-	commonHttp.WriteJsonOrError(rw, service, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Service{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if projection == nil {
+		service, err := c.getService(serviceId)
+		commonHttp.WriteJsonOrError(rw, service, http.StatusOK, err)
+		return
+	}
+
+	service, err := c.getDataWithProjection(c.buildServiceKey(serviceId), models.Service{}, projection)
+	writeProjectedJsonOrError(rw, projection, service, http.StatusOK, err)
 }
 
 func (c *Context) AddService(rw web.ResponseWriter, req *web.Request) {
@@ -97,6 +120,18 @@ func (c *Context) AddService(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if status, err := c.resolvePlansDependencies("", reqService.Plans); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if reqService.TemplateId != "" {
+		if status, err := c.validateTemplateReference(rw, reqService.TemplateId); err != nil {
+			commonHttp.GenericRespond(status, rw, err)
+			return
+		}
+	}
+
 	exists, err := c.repository.IsExistByName(reqService.Name, models.Service{}, c.getServiceKey())
 	if err != nil {
 		commonHttp.Respond500(rw, err)
@@ -107,24 +142,41 @@ func (c *Context) AddService(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	reqService.State = models.ServiceStateDeploying
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqService)
+		return
+	}
+
 	if reqService.Id, err = c.reserveID(c.getServiceKey()); err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
 
-	reqService.State = models.ServiceStateDeploying
 	serviceKeyStore := c.mapper.ToKeyValue(c.getServiceKey(), reqService, true)
 	err = c.repository.CreateData(serviceKeyStore)
 	if err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypeService, reqService.Id, "", reqService)
 
 	service, err := c.repository.GetData(c.buildServiceKey(reqService.Id), models.Service{})
+	if err == nil {
+		c.recordRevision(models.EntityTypeService, reqService.Id, "", service)
+	}
 	commonHttp.WriteJsonOrError(rw, service, http.StatusCreated, err)
 }
 
 func (c *Context) PatchService(rw web.ResponseWriter, req *web.Request) {
+	c.updateService(rw, req, c.readPatches)
+}
+
+func (c *Context) PutService(rw web.ResponseWriter, req *web.Request) {
+	c.updateService(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updateService(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	serviceId := req.PathParams["serviceId"]
 	serviceInt, err := c.repository.GetData(c.buildServiceKey(serviceId), models.Service{})
 	if err != nil {
@@ -138,8 +190,7 @@ func (c *Context) PatchService(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, service)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
@@ -150,10 +201,12 @@ func (c *Context) PatchService(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	fsmFunc := func() *fsm.FSM {
-		return c.getServicesFSM(service.State)
+	if status, err := c.resolvePlanPatches(serviceId, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
 	}
-	if err = c.handleFsm(rw, req, patches, fsmFunc); err != nil {
+
+	if err = c.handleFsm(rw, req, patches, models.EntityTypeService, string(service.State)); err != nil {
 		return
 	}
 
@@ -163,13 +216,33 @@ func (c *Context) PatchService(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if status, err := c.assureNoNewDependencyCycleByPatches(service, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if status, err := c.validateTemplateReferenceByPatches(rw, service, patches); err != nil {
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, service, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordStateTransition(req, models.EntityTypeService, serviceId, string(service.State), patches)
+	c.recordUpdateAudit(models.EntityTypeService, serviceId, "", service, patches)
 
 	serviceInt, err = c.repository.GetData(c.buildServiceKey(serviceId), models.Service{})
+	if err == nil {
+		c.recordRevision(models.EntityTypeService, serviceId, "", serviceInt)
+	}
 	commonHttp.WriteJsonOrError(rw, serviceInt, http.StatusOK, err)
 }
 
@@ -201,14 +274,23 @@ func (c *Context) checkIfStateCanBeUpdated(service models.Service, newState mode
 func (c *Context) DeleteService(rw web.ResponseWriter, req *web.Request) {
 	serviceId := req.PathParams["serviceId"]
 
+	if isCascade(req) {
+		c.cascadeDelete(rw, req, models.EntityTypeService, serviceId)
+		return
+	}
+
 	if status, err := c.assureOfferingIsNotUsed(serviceId); err != nil {
 		err := fmt.Errorf("cannot remove offering %q: %v", serviceId, err)
 		commonHttp.GenericRespond(status, rw, err)
 		return
 	}
 
-	err := c.repository.DeleteData(c.buildServiceKey(serviceId))
-	commonHttp.WriteJsonOrError(rw, serviceId, http.StatusNoContent, err)
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.buildServiceKey(serviceId), models.Service{})
+		return
+	}
+
+	c.deleteOrMoveToTrash(rw, req, "services", serviceId)
 }
 
 func (c *Context) assureOfferingIsNotUsed(serviceID string) (int, error) {
@@ -274,6 +356,24 @@ func isOfferingDependence(serviceID string, services []models.Service) (bool, er
 	return false, nil
 }
 
+func (c *Context) MonitorServicesStates(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificState(rw, req, c.getServiceKey())
+}
+
+func (c *Context) MonitorSpecificServiceState(rw web.ResponseWriter, req *web.Request) {
+	serviceId := req.PathParams["serviceId"]
+	c.monitorSpecificState(rw, req, c.buildServiceKey(serviceId))
+}
+
+func (c *Context) MonitorServicesChanges(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificChange(rw, req, c.getServiceKey())
+}
+
+func (c *Context) MonitorSpecificServiceChange(rw web.ResponseWriter, req *web.Request) {
+	serviceId := req.PathParams["serviceId"]
+	c.monitorSpecificChange(rw, req, c.buildServiceKey(serviceId))
+}
+
 func (c *Context) getServiceKey() string {
 	return data.GetEntityKey(c.organization, data.Services)
 }
@@ -281,17 +381,3 @@ func (c *Context) getServiceKey() string {
 func (c *Context) buildServiceKey(serviceId string) string {
 	return c.mapper.ToKey(c.getServiceKey(), serviceId)
 }
-
-func (c *Context) getServicesFSM(initialState models.ServiceState) *fsm.FSM {
-	return fsm.NewFSM(string(initialState),
-		fsm.Events{
-			{Name: "READY", Src: []string{"DEPLOYING"}, Dst: "READY"},
-			{Name: "OFFLINE", Src: []string{"DEPLOYING", "READY"}, Dst: "OFFLINE"},
-		},
-		fsm.Callbacks{
-			"enter_state": func(e *fsm.Event) {
-				c.enterState(e)
-			},
-		},
-	)
-}
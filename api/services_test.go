@@ -25,6 +25,7 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
@@ -146,6 +147,7 @@ func TestPatchServiceUpdate(t *testing.T) {
 			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil)
 			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(sampleServicesAsListOfInterfaces, nil)
 			mocks.repositoryMock.EXPECT().ApplyPatchedValues(patchedValues)
+			mocks.repositoryMock.EXPECT().AddStateHistoryRecord(context.buildStateHistoryKey(models.EntityTypeService, sampleService.Id), gomock.Any(), defaultStateHistoryLimit).Return(nil)
 			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleService.Id), models.Service{}).Return(sampleServiceInterface, nil)
 
 			service, status, err := catalogClient.UpdateService(sampleService.Id, patches)
@@ -172,6 +174,20 @@ func TestPatchServiceUpdate(t *testing.T) {
 			})
 		})
 
+		Convey("When field state is updated to unknown state", func() {
+			unknownValue := json.RawMessage(`"RETIRED"`)
+			unknownPatches := []models.Patch{{Operation: models.OperationUpdate, Field: &fieldName, Value: &unknownValue}}
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleService.Id), models.Service{}).Return(sampleServiceInterface, nil)
+
+			_, status, err := catalogClient.UpdateService(sampleService.Id, unknownPatches)
+
+			Convey("response should list allowed states", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, string(models.ServiceStateOffline))
+				So(status, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
 		Reset(func() {
 			mockCtrl.Finish()
 		})
@@ -295,6 +311,8 @@ func TestDeleteService(t *testing.T) {
 
 			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil)
 			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(sampleServicesAsListOfInterfaces, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(id), models.Service{}).Return(sampleServices[0], nil)
+			mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Services, id)), gomock.Any(), gomock.Any()).Return(nil)
 			mocks.repositoryMock.EXPECT().DeleteData(context.buildServiceKey(id)).Return(nil)
 
 			status, err := catalogClient.DeleteService(id)
@@ -0,0 +1,84 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+// templateValidationModeEnv sets template validation mode of organizations which have not configured it
+const templateValidationModeEnv = "TEMPLATE_VALIDATION_MODE"
+
+func (c *Context) GetSettings(rw web.ResponseWriter, req *web.Request) {
+	settings, err := c.getOrganizationSettings()
+	commonHttp.WriteJsonOrError(rw, settings, http.StatusOK, err)
+}
+
+func (c *Context) PutSettings(rw web.ResponseWriter, req *web.Request) {
+	settings := models.OrganizationSettings{}
+	if err := commonHttp.ReadJson(req, &settings); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if settings.TemplateValidation == "" {
+		settings.TemplateValidation = getDefaultTemplateValidationMode()
+	}
+
+	if err := settings.ValidateOrganizationSettings(); err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		commonHttp.WriteJson(rw, settings, http.StatusOK)
+		return
+	}
+
+	err := c.repository.SetOrganizationSettings(c.getSettingsKey(), settings)
+	commonHttp.WriteJsonOrError(rw, settings, http.StatusOK, err)
+}
+
+// getOrganizationSettings returns defaults if organization has not stored its own settings yet
+func (c *Context) getOrganizationSettings() (models.OrganizationSettings, error) {
+	settings, err := c.repository.GetOrganizationSettings(c.getSettingsKey())
+	if err != nil && !commonHttp.IsNotFoundError(err) {
+		return models.OrganizationSettings{}, err
+	}
+
+	if settings.TemplateValidation == "" {
+		settings.TemplateValidation = getDefaultTemplateValidationMode()
+	}
+	return settings, nil
+}
+
+func getDefaultTemplateValidationMode() models.TemplateValidationMode {
+	if mode := models.TemplateValidationMode(os.Getenv(templateValidationModeEnv)); mode == models.TemplateValidationEnforce {
+		return mode
+	}
+	return models.TemplateValidationWarn
+}
+
+func (c *Context) getSettingsKey() string {
+	return data.GetEntityKey(c.organization, data.Settings)
+}
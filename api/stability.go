@@ -13,12 +13,13 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package api
 
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gocraft/web"
 
@@ -26,43 +27,242 @@ import (
 	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 )
 
-var stableStates = []models.InstanceState{
-	models.InstanceStateStopped,
-	models.InstanceStateRunning,
-	models.InstanceStateFailure,
+const (
+	stabilityWaitQueryParam = "wait"
+	maxStabilityWait        = 10 * time.Minute
+)
+
+// stabilityPollInterval is how often states are checked again while waiting for stability
+var stabilityPollInterval = 2 * time.Second
+
+var stableStates = map[models.EntityType][]string{
+	models.EntityTypeInstance: {
+		models.InstanceStateStopped.String(),
+		models.InstanceStateRunning.String(),
+		models.InstanceStateFailure.String(),
+	},
+	models.EntityTypeImage: {
+		string(models.ImageStateReady),
+		string(models.ImageStateError),
+	},
+	models.EntityTypeTemplate: {
+		string(models.TemplateStateReady),
+		string(models.TemplateStateUnavailable),
+	},
 }
 
+// CheckStateStability reports all instances, images and templates in transitional states. With wait query parameter
+// states are checked again until all of them are stable or given time passes.
 func (c *Context) CheckStateStability(rw web.ResponseWriter, req *web.Request) {
-	instances, err := c.getInstances()
+	filter, err := getStabilityFilter(req)
 	if err != nil {
-		commonHttp.WriteJson(rw, err.Error(), getHttpStatusOrStatusError(http.StatusOK, err))
+		commonHttp.Respond400(rw, err)
 		return
 	}
-
-	err = assureInstanceStatesAreStable(instances)
+	wait, err := getStabilityWait(req)
 	if err != nil {
-		commonHttp.WriteJson(rw, models.StateStability{Stable: false, Message: err.Error()}, http.StatusOK)
+		commonHttp.Respond400(rw, err)
 		return
 	}
 
-	commonHttp.WriteJson(rw, models.StateStability{Stable: true}, http.StatusOK)
+	deadline := time.Now().Add(wait)
+	var closed <-chan bool
+	if wait > 0 {
+		closed = rw.CloseNotify()
+	}
+
+	for {
+		stability, err := c.getStateStability(filter)
+		if err != nil {
+			commonHttp.WriteJson(rw, err.Error(), getHttpStatusOrStatusError(http.StatusOK, err))
+			return
+		}
+
+		remaining := deadline.Sub(time.Now())
+		if stability.Stable || remaining <= 0 {
+			commonHttp.WriteJson(rw, stability, http.StatusOK)
+			return
+		}
+
+		if remaining > stabilityPollInterval {
+			remaining = stabilityPollInterval
+		}
+		select {
+		case <-time.After(remaining):
+		case <-closed:
+			return
+		}
+	}
 }
 
-func assureInstanceStatesAreStable(instances []models.Instance) error {
+func (c *Context) getStateStability(filter models.StabilityFilter) (models.StateStability, error) {
+	unstable := []models.UnstableEntity{}
+	for _, find := range []func(models.StabilityFilter) ([]models.UnstableEntity, error){
+		c.findUnstableInstances,
+		c.findUnstableImages,
+		c.findUnstableTemplates,
+	} {
+		entities, err := find(filter)
+		if err != nil {
+			return models.StateStability{}, err
+		}
+		unstable = append(unstable, entities...)
+	}
+
+	if len(unstable) == 0 {
+		return models.StateStability{Stable: true, Unstable: unstable}, nil
+	}
+
+	first := unstable[0]
+	message := fmt.Sprintf("%s %q state %q is not stable", strings.ToLower(string(first.EntityType)), first.Id, first.State)
+	if len(unstable) > 1 {
+		message = fmt.Sprintf("%s (and %d more)", message, len(unstable)-1)
+	}
+	return models.StateStability{Stable: false, Message: message, Unstable: unstable}, nil
+}
+
+func (c *Context) findUnstableInstances(filter models.StabilityFilter) ([]models.UnstableEntity, error) {
+	result := []models.UnstableEntity{}
+	if !filter.Includes(models.EntityTypeInstance) {
+		return result, nil
+	}
+
+	instances, err := c.getInstances()
+	if err != nil {
+		return result, err
+	}
 	for _, instance := range instances {
-		if !isInstanceInStableState(instance) {
-			return fmt.Errorf("instance %q state %q is not stable", instance.Id, instance.State.String())
+		if !filter.Matches(models.EntityTypeInstance, string(instance.Type), instance.ClassId) {
+			continue
+		}
+		if unstable, ok := c.checkStability(models.EntityTypeInstance, instance.Id, instance.State.String(), instance.AuditTrail); !ok {
+			unstable.Name = instance.Name
+			unstable.Type = string(instance.Type)
+			unstable.ClassId = instance.ClassId
+			result = append(result, unstable)
+		}
+	}
+	return result, nil
+}
+
+func (c *Context) findUnstableImages(filter models.StabilityFilter) ([]models.UnstableEntity, error) {
+	result := []models.UnstableEntity{}
+	if !filter.Includes(models.EntityTypeImage) || filter.ClassId != "" {
+		return result, nil
+	}
+
+	entities, err := c.repository.GetListOfData(c.getImagesKey(), models.Image{})
+	if err != nil {
+		return result, fmt.Errorf("images retrieval failed: %v", err)
+	}
+	for _, entity := range entities {
+		image, ok := entity.(models.Image)
+		if !ok {
+			return []models.UnstableEntity{}, fmt.Errorf("type assertion for image failed: object from database: %v", entity)
+		}
+		if !filter.Matches(models.EntityTypeImage, string(image.Type), "") {
+			continue
 		}
+		if unstable, ok := c.checkStability(models.EntityTypeImage, image.Id, string(image.State), image.AuditTrail); !ok {
+			unstable.Type = string(image.Type)
+			result = append(result, unstable)
+		}
+	}
+	return result, nil
+}
+
+func (c *Context) findUnstableTemplates(filter models.StabilityFilter) ([]models.UnstableEntity, error) {
+	result := []models.UnstableEntity{}
+	if !filter.Includes(models.EntityTypeTemplate) || filter.Type != "" || filter.ClassId != "" {
+		return result, nil
 	}
 
-	return nil
+	entities, err := c.repository.GetListOfData(c.getTemplateKey(), models.Template{})
+	if err != nil {
+		return result, fmt.Errorf("templates retrieval failed: %v", err)
+	}
+	for _, entity := range entities {
+		template, ok := entity.(models.Template)
+		if !ok {
+			return []models.UnstableEntity{}, fmt.Errorf("type assertion for template failed: object from database: %v", entity)
+		}
+		if unstable, ok := c.checkStability(models.EntityTypeTemplate, template.Id, string(template.State), template.AuditTrail); !ok {
+			result = append(result, unstable)
+		}
+	}
+	return result, nil
 }
 
-func isInstanceInStableState(instance models.Instance) bool {
-	for _, stateOK := range stableStates {
-		if instance.State == stateOK {
-			return true
+// checkStability returns false together with description of entity if its state is not stable
+func (c *Context) checkStability(entityType models.EntityType, id, state string, auditTrail models.AuditTrail) (models.UnstableEntity, bool) {
+	for _, stableState := range stableStates[entityType] {
+		if state == stableState {
+			return models.UnstableEntity{}, true
 		}
 	}
-	return false
+
+	since := c.getStateSince(entityType, id, state, auditTrail)
+	return models.UnstableEntity{
+		EntityType:      entityType,
+		Id:              id,
+		State:           state,
+		Since:           since,
+		DurationSeconds: time.Now().Unix() - since,
+	}, false
+}
+
+// getStateSince takes time of entering current state from state history. Entities without history fall back to
+// their last update, which may be later than actual state change.
+func (c *Context) getStateSince(entityType models.EntityType, id, state string, auditTrail models.AuditTrail) int64 {
+	history, err := c.repository.GetStateHistory(c.buildStateHistoryKey(entityType, id))
+	if err == nil {
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].To == state {
+				return time.Unix(0, history[i].Timestamp).Unix()
+			}
+		}
+	} else if !commonHttp.IsNotFoundError(err) {
+		logger.Warningf("cannot get state history of %s %q: %v", entityType, id, err)
+	}
+
+	if auditTrail.LastUpdatedOn != 0 {
+		return auditTrail.LastUpdatedOn
+	}
+	return auditTrail.CreatedOn
+}
+
+func getStabilityFilter(req *web.Request) (models.StabilityFilter, error) {
+	filter := models.StabilityFilter{
+		Type:    commonHttp.GetQueryParameterCaseInsensitive(req, "type"),
+		ClassId: commonHttp.GetQueryParameterCaseInsensitive(req, "classId"),
+	}
+	for _, value := range getQueryParameterAsList(req, "entityType") {
+		entityType, err := models.ParseEntityType(value)
+		if err != nil {
+			return filter, err
+		}
+		if _, ok := stableStates[entityType]; !ok {
+			return filter, fmt.Errorf("entity type %q has no state stability - entity type must match one of: %s, %s, %s",
+				value, models.EntityTypeInstance, models.EntityTypeImage, models.EntityTypeTemplate)
+		}
+		filter.EntityTypes = append(filter.EntityTypes, entityType)
+	}
+	return filter, nil
+}
+
+// getStabilityWait returns zero if request should not wait for stability. Longer waits are limited to maxStabilityWait.
+func getStabilityWait(req *web.Request) (time.Duration, error) {
+	value := req.URL.Query().Get(stabilityWaitQueryParam)
+	if value == "" {
+		return 0, nil
+	}
+
+	wait, err := time.ParseDuration(value)
+	if err != nil || wait < 0 {
+		return 0, fmt.Errorf("wait %q must match non-negative duration, e.g. 30s or 5m", value)
+	}
+	if wait > maxStabilityWait {
+		wait = maxStabilityWait
+	}
+	return wait, nil
 }
@@ -13,28 +13,39 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/trustedanalytics-ng/tap-catalog/client"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
+const sampleClassId = "sample-class"
+
 func TestCheckStateStability(t *testing.T) {
 	Convey("Testing CheckStateStability", t, func() {
 		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
 		sampleInstances := getInstancesInStableState()
 
+		expectImagesAndTemplates := func(images []interface{}, templates []interface{}) {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getImagesKey(), models.Image{}).Return(images, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getTemplateKey(), models.Template{}).Return(templates, nil)
+		}
+
 		Convey("When all instances are in stable state", func() {
 			sampleInstancesAsListOfInterfaces := getSampleInstancesAsListOfInterfaces(sampleInstances)
 
 			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil)
+			expectImagesAndTemplates(nil, nil)
 
 			result, status, err := catalogClient.CheckStateStability()
 
@@ -46,6 +57,7 @@ func TestCheckStateStability(t *testing.T) {
 			})
 			Convey("result should be proper", func() {
 				So(result.Stable, ShouldEqual, true)
+				So(result.Unstable, ShouldBeEmpty)
 			})
 		})
 
@@ -68,6 +80,8 @@ func TestCheckStateStability(t *testing.T) {
 				sampleInstancesAsListOfInterfaces := getSampleInstancesAsListOfInterfaces(sampleInstances)
 
 				mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(sampleInstancesAsListOfInterfaces, nil)
+				mocks.repositoryMock.EXPECT().GetStateHistory(gomock.Any()).Return(nil, errors.New("Key not found"))
+				expectImagesAndTemplates(nil, nil)
 
 				result, status, err := catalogClient.CheckStateStability()
 
@@ -79,11 +93,107 @@ func TestCheckStateStability(t *testing.T) {
 				})
 				Convey("result should be proper", func() {
 					So(result.Stable, ShouldEqual, false)
+					So(result.Unstable, ShouldHaveLength, 1)
+					So(result.Unstable[0].State, ShouldEqual, stateNotReady.String())
 				})
 			})
 		}
 
+		Convey("When instances, images and templates are unstable, all of them should be reported", func() {
+			enteredState := time.Now().Add(-time.Minute)
+			instances := []models.Instance{
+				{Id: sampleID1, Name: "first", ClassId: sampleClassId, State: models.InstanceStateDeploying},
+				{Id: sampleID2, State: models.InstanceStateStopping, AuditTrail: models.AuditTrail{LastUpdatedOn: enteredState.Unix()}},
+			}
+			images := []interface{}{models.Image{Id: sampleID1, State: models.ImageStateBuilding}, models.Image{Id: sampleID2, State: models.ImageStateReady}}
+			templates := []interface{}{models.Template{Id: sampleID1, State: models.TemplateStateInProgress}}
+
+			history := []models.StateTransitionRecord{
+				{From: models.InstanceStateRequested.String(), To: models.InstanceStateDeploying.String(), Timestamp: enteredState.UnixNano()},
+			}
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(getSampleInstancesAsListOfInterfaces(instances), nil)
+			mocks.repositoryMock.EXPECT().GetStateHistory(context.buildStateHistoryKey(models.EntityTypeInstance, sampleID1)).Return(history, nil)
+			mocks.repositoryMock.EXPECT().GetStateHistory(gomock.Any()).Return(nil, errors.New("Key not found")).Times(3)
+			expectImagesAndTemplates(images, templates)
+
+			result, status, err := catalogClient.CheckStateStability()
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result.Stable, ShouldBeFalse)
+			So(result.Message, ShouldContainSubstring, "(and 3 more)")
+			So(result.Unstable, ShouldHaveLength, 4)
+			So(result.Unstable[0].EntityType, ShouldEqual, models.EntityTypeInstance)
+			So(result.Unstable[0].Name, ShouldEqual, "first")
+			So(result.Unstable[0].ClassId, ShouldEqual, sampleClassId)
+			So(result.Unstable[0].Since, ShouldEqual, enteredState.Unix())
+			So(result.Unstable[0].DurationSeconds, ShouldBeGreaterThanOrEqualTo, 60)
+			So(result.Unstable[1].Since, ShouldEqual, enteredState.Unix())
+			So(result.Unstable[2].EntityType, ShouldEqual, models.EntityTypeImage)
+			So(result.Unstable[3].EntityType, ShouldEqual, models.EntityTypeTemplate)
+		})
+
+		Convey("When entity type filter is given, only entities of that type should be checked", func() {
+			images := []interface{}{models.Image{Id: sampleID1, State: models.ImageStatePending}}
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getImagesKey(), models.Image{}).Return(images, nil)
+			mocks.repositoryMock.EXPECT().GetStateHistory(gomock.Any()).Return(nil, errors.New("Key not found"))
+
+			result, status, err := catalogClient.CheckStateStability(client.WithStabilityEntityTypes(models.EntityTypeImage))
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result.Stable, ShouldBeFalse)
+			So(result.Unstable, ShouldHaveLength, 1)
+			So(result.Unstable[0].EntityType, ShouldEqual, models.EntityTypeImage)
+		})
+
+		Convey("When class filter is given, only instances of that class should be checked", func() {
+			instances := []models.Instance{
+				{Id: sampleID1, ClassId: sampleID2, State: models.InstanceStateDeploying},
+				{Id: sampleID2, ClassId: sampleClassId, State: models.InstanceStateRunning},
+			}
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getInstanceKey(), models.Instance{}).Return(getSampleInstancesAsListOfInterfaces(instances), nil)
+
+			result, status, err := catalogClient.CheckStateStability(client.WithStabilityClassId(sampleClassId))
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result.Stable, ShouldBeTrue)
+		})
+
+		Convey("When wait is given, states should be checked until they are stable", func() {
+			stabilityPollInterval = time.Millisecond
+			unstable := []interface{}{models.Template{Id: sampleID1, State: models.TemplateStateInProgress}}
+			stable := []interface{}{models.Template{Id: sampleID1, State: models.TemplateStateReady}}
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getTemplateKey(), models.Template{}).Return(unstable, nil),
+				mocks.repositoryMock.EXPECT().GetListOfData(context.getTemplateKey(), models.Template{}).Return(stable, nil),
+			)
+			mocks.repositoryMock.EXPECT().GetStateHistory(gomock.Any()).Return(nil, errors.New("Key not found"))
+
+			result, status, err := catalogClient.CheckStateStability(
+				client.WithStabilityEntityTypes(models.EntityTypeTemplate), client.WithStabilityWait(time.Minute))
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(result.Stable, ShouldBeTrue)
+		})
+
+		Convey("When entity type has no state, response status should be BadRequest", func() {
+			_, status, err := catalogClient.CheckStateStability(client.WithStabilityEntityTypes(models.EntityTypePlan))
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When wait is not a duration, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/stable-state?wait=soon", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
 		Reset(func() {
+			stabilityPollInterval = 2 * time.Second
 			mockCtrl.Finish()
 		})
 	})
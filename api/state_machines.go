@@ -0,0 +1,186 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gocraft/web"
+	"github.com/looplab/fsm"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+// stateMachinesFileEnv points to JSON list of state machines replacing the default ones of given entity types
+const stateMachinesFileEnv = "STATE_MACHINES_FILE"
+
+// stateEnums are state types of entities which have state machine
+var stateEnums = map[models.EntityType]models.Enum{
+	models.EntityTypeInstance: models.InstanceState(""),
+	models.EntityTypeService:  models.ServiceState(""),
+	models.EntityTypeImage:    models.ImageState(""),
+	models.EntityTypeTemplate: models.TemplateState(""),
+}
+
+var defaultStateMachines = []models.StateMachine{
+	{
+		EntityType: models.EntityTypeInstance,
+		States:     models.InstanceState("").EnumValues(),
+		Transitions: []models.StateTransition{
+			instanceTransition(models.InstanceStateDeploying, models.InstanceStateRequested),
+			instanceTransition(models.InstanceStateFailure, models.InstanceStateDeploying, models.InstanceStateStarting,
+				models.InstanceStateRunning, models.InstanceStateStopping, models.InstanceStateDestroying),
+			instanceTransition(models.InstanceStateStopped, models.InstanceStateDeploying, models.InstanceStateStopping,
+				models.InstanceStateUnavailable),
+			instanceTransition(models.InstanceStateStartReq, models.InstanceStateStopped),
+			instanceTransition(models.InstanceStateStarting, models.InstanceStateStartReq, models.InstanceStateStopped,
+				models.InstanceStateReconfiguration),
+			instanceTransition(models.InstanceStateRunning, models.InstanceStateStarting),
+			instanceTransition(models.InstanceStateReconfiguration, models.InstanceStateRunning, models.InstanceStateStopped),
+			instanceTransition(models.InstanceStateStopReq, models.InstanceStateRunning, models.InstanceStateStarting),
+			instanceTransition(models.InstanceStateStopping, models.InstanceStateStopReq, models.InstanceStateReconfiguration),
+			instanceTransition(models.InstanceStateDestroyReq, models.InstanceStateStopped, models.InstanceStateFailure),
+			instanceTransition(models.InstanceStateDestroying, models.InstanceStateDestroyReq),
+			instanceTransition(models.InstanceStateUnavailable, models.InstanceStateStopped, models.InstanceStateRunning),
+		},
+	},
+	{
+		EntityType: models.EntityTypeService,
+		States:     models.ServiceState("").EnumValues(),
+		Transitions: []models.StateTransition{
+			{To: "READY", From: []string{"DEPLOYING"}},
+			{To: "OFFLINE", From: []string{"DEPLOYING", "READY"}},
+		},
+	},
+	{
+		EntityType: models.EntityTypeImage,
+		States:     models.ImageState("").EnumValues(),
+		Transitions: []models.StateTransition{
+			{To: "PENDING", From: []string{"REQUESTED"}},
+			{To: "BUILDING", From: []string{"PENDING"}},
+			{To: "ERROR", From: []string{"BUILDING"}},
+			{To: "READY", From: []string{"BUILDING"}},
+			{To: "REMOVING", From: []string{"READY"}},
+		},
+	},
+	{
+		EntityType: models.EntityTypeTemplate,
+		States:     models.TemplateState("").EnumValues(),
+		Transitions: []models.StateTransition{
+			{To: "READY", From: []string{"IN_PROGRESS"}},
+			{To: "UNAVAILABLE", From: []string{"IN_PROGRESS"}},
+		},
+	},
+}
+
+func instanceTransition(destination models.InstanceState, sources ...models.InstanceState) models.StateTransition {
+	sourceString := []string{}
+	for _, source := range sources {
+		sourceString = append(sourceString, source.String())
+	}
+	return models.StateTransition{To: destination.String(), From: sourceString}
+}
+
+func (c *Context) StateMachines(rw web.ResponseWriter, req *web.Request) {
+	result := []models.StateMachine{}
+	for _, machine := range defaultStateMachines {
+		stateMachine, _ := c.getStateMachine(machine.EntityType)
+		result = append(result, stateMachine)
+	}
+	commonHttp.WriteJson(rw, result, http.StatusOK)
+}
+
+func (c *Context) GetStateMachine(rw web.ResponseWriter, req *web.Request) {
+	entityType, err := models.ParseEntityType(req.PathParams["kind"])
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	stateMachine, ok := c.getStateMachine(entityType)
+	if !ok {
+		commonHttp.Respond404(rw, fmt.Errorf("state machine of %s not found", entityType))
+		return
+	}
+	commonHttp.WriteJson(rw, stateMachine, http.StatusOK)
+}
+
+func (c *Context) getStateMachine(entityType models.EntityType) (models.StateMachine, bool) {
+	if stateMachine, ok := c.stateMachines[entityType]; ok {
+		return stateMachine, true
+	}
+	for _, stateMachine := range defaultStateMachines {
+		if stateMachine.EntityType == entityType {
+			return stateMachine, true
+		}
+	}
+	return models.StateMachine{}, false
+}
+
+func (c *Context) newFSM(stateMachine models.StateMachine, initialState string) *fsm.FSM {
+	events := fsm.Events{}
+	for _, transition := range stateMachine.Transitions {
+		events = append(events, fsm.EventDesc{Name: transition.To, Src: transition.From, Dst: transition.To})
+	}
+
+	return fsm.NewFSM(initialState,
+		events,
+		fsm.Callbacks{
+			"enter_state": func(e *fsm.Event) {
+				c.enterState(e)
+			},
+		},
+	)
+}
+
+// loadStateMachines reads state machines from definition file - entity types which are not defined there keep defaults
+func loadStateMachines(path string) (map[models.EntityType]models.StateMachine, error) {
+	result := map[models.EntityType]models.StateMachine{}
+	if path == "" {
+		return result, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("cannot read state machines definition: %v", err)
+	}
+
+	stateMachines := []models.StateMachine{}
+	if err = json.Unmarshal(content, &stateMachines); err != nil {
+		return result, fmt.Errorf("cannot parse state machines definition %s: %v", path, err)
+	}
+
+	for _, stateMachine := range stateMachines {
+		stateEnum, ok := stateEnums[stateMachine.EntityType]
+		if !ok {
+			return result, fmt.Errorf("entity type %q has no state machine", stateMachine.EntityType)
+		}
+		if _, ok := result[stateMachine.EntityType]; ok {
+			return result, fmt.Errorf("state machine of %s is defined more than once", stateMachine.EntityType)
+		}
+
+		stateMachine.States = stateEnum.EnumValues()
+		if err = stateMachine.ValidateStateMachine(); err != nil {
+			return result, err
+		}
+		result[stateMachine.EntityType] = stateMachine
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestStateMachines(t *testing.T) {
+	Convey("Testing state machines", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+
+		Convey("When state machine of instances is requested, default transitions should be returned", func() {
+			stateMachine, status, err := catalogClient.GetStateMachine(models.EntityTypeInstance)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, http.StatusOK)
+			So(stateMachine.States, ShouldContain, string(models.InstanceStateReconfiguration))
+			So(stateMachine.NextStates(string(models.InstanceStateStopped)), ShouldResemble, []string{
+				string(models.InstanceStateStartReq), string(models.InstanceStateStarting),
+				string(models.InstanceStateReconfiguration), string(models.InstanceStateDestroyReq),
+				string(models.InstanceStateUnavailable),
+			})
+		})
+
+		Convey("When entity type has no state machine, response status should be NotFound", func() {
+			_, status, err := catalogClient.GetStateMachine(models.EntityTypeApplication)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("When state change is not allowed, allowed next states should be listed", func() {
+			image := models.Image{Id: sampleID1, State: models.ImageStateError}
+			mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(image, nil)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/images/"+sampleID1,
+				[]byte(`[{"op":"Update","field":"state","value":"BUILDING"}]`), t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "allowed next states: []")
+		})
+
+		Convey("When state machine is loaded from definition file, its transitions should be used", func() {
+			path := writeStateMachinesFile(`[{"entityType":"IMAGE","transitions":[{"to":"BUILDING","from":["PENDING","ERROR"]}]}]`)
+			defer os.Remove(path)
+
+			stateMachines, err := loadStateMachines(path)
+			So(err, ShouldBeNil)
+			context.stateMachines = stateMachines
+			image := models.Image{Id: sampleID1, State: models.ImageStateError}
+			mocks.repositoryMock.EXPECT().GetData(context.buildImagesKey(sampleID1), models.Image{}).Return(image, nil)
+
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/images/"+sampleID1+"?dryRun=true",
+				[]byte(`[{"op":"Update","field":"state","value":"BUILDING"}]`), t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			templateMachine, _ := context.getStateMachine(models.EntityTypeTemplate)
+			So(templateMachine.Transitions, ShouldHaveLength, 2)
+		})
+
+		Convey("When definition file uses unknown state, loading should fail", func() {
+			path := writeStateMachinesFile(`[{"entityType":"SERVICE","transitions":[{"to":"RETIRED","from":["OFFLINE"]}]}]`)
+			defer os.Remove(path)
+
+			_, err := loadStateMachines(path)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "RETIRED")
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func writeStateMachinesFile(content string) string {
+	file, _ := ioutil.TempFile("", "state-machines")
+	defer file.Close()
+	file.WriteString(content)
+	return file.Name()
+}
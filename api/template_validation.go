@@ -0,0 +1,141 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const warningHeader = "Warning"
+
+// validateTemplateReference checks that referenced template exists and is READY. Depending on organization
+// settings problem either rejects request or is only reported in Warning header of the response
+func (c *Context) validateTemplateReference(rw web.ResponseWriter, templateID string) (int, error) {
+	settings, err := c.getOrganizationSettings()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	status, err := c.checkTemplateReference(templateID)
+	if err == nil || status == http.StatusInternalServerError {
+		return status, err
+	}
+
+	if settings.TemplateValidation == models.TemplateValidationEnforce {
+		return status, err
+	}
+
+	logger.Warningf("template validation: %v", err)
+	rw.Header().Add(warningHeader, fmt.Sprintf("299 - %q", err.Error()))
+	return http.StatusOK, nil
+}
+
+func (c *Context) checkTemplateReference(templateID string) (int, error) {
+	if templateID == "" {
+		return http.StatusBadRequest, fmt.Errorf("templateId is required")
+	}
+
+	entity, err := c.repository.GetData(c.buildTemplateKey(templateID), models.Template{})
+	if err != nil {
+		if commonHttp.IsNotFoundError(err) {
+			return http.StatusBadRequest, fmt.Errorf("template %q does not exist", templateID)
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	template, ok := entity.(models.Template)
+	if !ok {
+		return http.StatusInternalServerError, fmt.Errorf("type assertion for template %q failed: object from database: %v", templateID, entity)
+	}
+	if template.State != models.TemplateStateReady {
+		return http.StatusBadRequest, fmt.Errorf("template %q is in state %s, expected %s", templateID, template.State, models.TemplateStateReady)
+	}
+	return http.StatusOK, nil
+}
+
+// validateTemplateReferenceByPatches validates template of entity with patches applied, if any of them changes it
+func (c *Context) validateTemplateReferenceByPatches(rw web.ResponseWriter, entity interface{}, patches []models.Patch) (int, error) {
+	if !changesTemplate(patches) {
+		return http.StatusOK, nil
+	}
+
+	patched, err := c.mapper.PreviewPatches(entity, patches)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	switch patchedEntity := patched.(type) {
+	case models.Service:
+		return c.validateTemplateReference(rw, patchedEntity.TemplateId)
+	case models.Application:
+		return c.validateTemplateReference(rw, patchedEntity.TemplateId)
+	default:
+		return http.StatusInternalServerError, fmt.Errorf("type %T does not reference template", patched)
+	}
+}
+
+func changesTemplate(patches []models.Patch) bool {
+	for _, patch := range patches {
+		if patch.Field != nil && strings.Title(*patch.Field) == "TemplateId" {
+			return true
+		}
+	}
+	return false
+}
+
+// getTemplatesUsage returns services and applications using templates mapped by template id
+func (c *Context) getTemplatesUsage() (map[string]models.TemplateUsage, error) {
+	usage := map[string]models.TemplateUsage{}
+
+	services, err := c.getServices()
+	if err != nil {
+		return usage, err
+	}
+	for _, service := range services {
+		templateUsage := usage[service.TemplateId]
+		templateUsage.Services = append(templateUsage.Services, models.EntityReference{Id: service.Id, Name: service.Name})
+		usage[service.TemplateId] = templateUsage
+	}
+
+	applications, err := c.getApplications()
+	if err != nil {
+		return usage, err
+	}
+	for _, application := range applications {
+		templateUsage := usage[application.TemplateId]
+		templateUsage.Applications = append(templateUsage.Applications, models.EntityReference{Id: application.Id, Name: application.Name})
+		usage[application.TemplateId] = templateUsage
+	}
+	return usage, nil
+}
+
+func getTemplateUsage(usage map[string]models.TemplateUsage, templateID string) models.TemplateUsage {
+	templateUsage := usage[templateID]
+	if templateUsage.Services == nil {
+		templateUsage.Services = []models.EntityReference{}
+	}
+	if templateUsage.Applications == nil {
+		templateUsage.Applications = []models.EntityReference{}
+	}
+	return templateUsage
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestTemplateValidation(t *testing.T) {
+	Convey("Testing template reference validation", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+		applicationBody := []byte(`{"name":"` + sampleName1 + `","templateId":"` + sampleID1 + `"}`)
+		expectSettings := func(mode models.TemplateValidationMode) {
+			mocks.repositoryMock.EXPECT().GetOrganizationSettings(context.getSettingsKey()).Return(
+				models.OrganizationSettings{TemplateValidation: mode}, nil)
+		}
+
+		Convey("When template is not ready and validation is enforced, response status should be BadRequest", func() {
+			expectSettings(models.TemplateValidationEnforce)
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(
+				models.Template{Id: sampleID1, State: models.TemplateStateInProgress}, nil)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/applications", applicationBody, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "is in state IN_PROGRESS")
+		})
+
+		Convey("When template does not exist and validation only warns, application should be created with warning", func() {
+			expectSettings(models.TemplateValidationWarn)
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(
+				nil, errors.New("Key not found"))
+			mocks.repositoryMock.EXPECT().IsExistByName(sampleName1, models.Application{}, context.getApplicationKey()).Return(false, nil)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/applications?dryRun=true", applicationBody, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Header().Get(warningHeader), ShouldContainSubstring, "does not exist")
+		})
+
+		Convey("When patch changes template to not existing one and validation is enforced, response status should be BadRequest", func() {
+			service := models.Service{Id: sampleID2, Name: sampleName2, TemplateId: sampleID2}
+			mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID2), models.Service{}).Return(service, nil)
+			expectSettings(models.TemplateValidationEnforce)
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(
+				nil, errors.New("Key not found"))
+
+			body := []byte(`[{"op":"Update","field":"templateId","value":"` + sampleID1 + `"}]`)
+			rr := sendAuthorizedRequest(context, "PATCH", "/api/v1/services/"+sampleID2, body, t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(rr.Body.String(), ShouldContainSubstring, "does not exist")
+		})
+
+		Convey("When templates are listed, services and applications using them should be returned", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getTemplateKey(), models.Template{}).Return(
+				[]interface{}{models.Template{Id: sampleID1}, models.Template{Id: sampleID2}}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return(
+				[]interface{}{models.Service{Id: sampleID2, Name: sampleName2, TemplateId: sampleID1}}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/templates", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			templates := []models.TemplateWithUsage{}
+			So(json.Unmarshal(rr.Body.Bytes(), &templates), ShouldBeNil)
+			So(templates, ShouldHaveLength, 2)
+			So(templates[0].UsedBy.Services, ShouldResemble, []models.EntityReference{{Id: sampleID2, Name: sampleName2}})
+			So(templates[1].UsedBy.Services, ShouldBeEmpty)
+		})
+
+		Convey("When settings were not stored, defaults should be returned", func() {
+			mocks.repositoryMock.EXPECT().GetOrganizationSettings(context.getSettingsKey()).Return(
+				models.OrganizationSettings{}, errors.New("Key not found"))
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/settings", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Body.String(), ShouldContainSubstring, string(models.TemplateValidationWarn))
+		})
+
+		Convey("When settings with unknown mode are put, response status should be BadRequest", func() {
+			rr := sendAuthorizedRequest(context, "PUT", "/api/v1/settings", []byte(`{"templateValidation":"STRICT"}`), t)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -17,10 +17,10 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gocraft/web"
-	"github.com/looplab/fsm"
 
 	"github.com/trustedanalytics-ng/tap-catalog/data"
 	"github.com/trustedanalytics-ng/tap-catalog/models"
@@ -28,14 +28,55 @@ import (
 )
 
 func (c *Context) Templates(rw web.ResponseWriter, req *web.Request) {
-	result, err := c.repository.GetListOfData(c.getTemplateKey(), models.Template{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+	projection, err := getFieldProjection(req, models.Template{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	if projection == nil {
+		result, err := c.getTemplatesWithUsage()
+		commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+		return
+	}
+
+	result, err := c.getListOfDataWithProjection(c.getTemplateKey(), models.Template{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
+}
+
+func (c *Context) getTemplatesWithUsage() ([]models.TemplateWithUsage, error) {
+	result := []models.TemplateWithUsage{}
+	entities, err := c.repository.GetListOfData(c.getTemplateKey(), models.Template{})
+	if err != nil {
+		return result, err
+	}
+
+	usage, err := c.getTemplatesUsage()
+	if err != nil {
+		return result, err
+	}
+
+	for _, entity := range entities {
+		template, ok := entity.(models.Template)
+		if !ok {
+			return []models.TemplateWithUsage{}, fmt.Errorf("type assertion for template failed: object from database: %v", entity)
+		}
+		result = append(result, models.TemplateWithUsage{Template: template, UsedBy: getTemplateUsage(usage, template.Id)})
+	}
+	return result, nil
 }
 
 func (c *Context) GetTemplate(rw web.ResponseWriter, req *web.Request) {
 	templateId := req.PathParams["templateId"]
-	result, err := c.repository.GetData(c.buildTemplateKey(templateId), models.Template{})
-	commonHttp.WriteJsonOrError(rw, result, http.StatusOK, err)
+
+	projection, err := getFieldProjection(req, models.Template{})
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.getDataWithProjection(c.buildTemplateKey(templateId), models.Template{}, projection)
+	writeProjectedJsonOrError(rw, projection, result, http.StatusOK, err)
 }
 
 func (c *Context) AddTemplate(rw web.ResponseWriter, req *web.Request) {
@@ -53,18 +94,24 @@ func (c *Context) AddTemplate(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	reqTemplate.State = models.TemplateStateInProgress
+	if isDryRun(req) {
+		c.respondDryRunCreate(rw, reqTemplate)
+		return
+	}
+
 	if reqTemplate.Id, err = c.reserveID(c.getTemplateKey()); err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
 
-	reqTemplate.State = models.TemplateStateInProgress
 	templateKeyStore := c.mapper.ToKeyValue(c.getTemplateKey(), reqTemplate, true)
 	err = c.repository.CreateData(templateKeyStore)
 	if err != nil {
 		commonHttp.Respond500(rw, err)
 		return
 	}
+	c.recordCreateAudit(models.EntityTypeTemplate, reqTemplate.Id, "", reqTemplate)
 
 	template, err := c.repository.GetData(c.buildTemplateKey(reqTemplate.Id), models.Template{})
 	commonHttp.WriteJsonOrError(rw, template, http.StatusCreated, err)
@@ -73,11 +120,34 @@ func (c *Context) AddTemplate(rw web.ResponseWriter, req *web.Request) {
 func (c *Context) DeleteTemplate(rw web.ResponseWriter, req *web.Request) {
 	templateId := req.PathParams["templateId"]
 
-	err := c.repository.DeleteData(c.buildTemplateKey(templateId))
-	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
+	if isCascade(req) {
+		c.cascadeDelete(rw, req, models.EntityTypeTemplate, templateId)
+		return
+	}
+
+	if status, err := c.assureTemplateIsNotUsed(templateId); err != nil {
+		err := fmt.Errorf("cannot remove template %q: %v", templateId, err)
+		commonHttp.GenericRespond(status, rw, err)
+		return
+	}
+
+	if isDryRun(req) {
+		c.respondDryRunDelete(rw, c.buildTemplateKey(templateId), models.Template{})
+		return
+	}
+
+	c.deleteOrMoveToTrash(rw, req, "templates", templateId)
 }
 
 func (c *Context) PatchTemplate(rw web.ResponseWriter, req *web.Request) {
+	c.updateTemplate(rw, req, c.readPatches)
+}
+
+func (c *Context) PutTemplate(rw web.ResponseWriter, req *web.Request) {
+	c.updateTemplate(rw, req, c.readReplacementPatches)
+}
+
+func (c *Context) updateTemplate(rw web.ResponseWriter, req *web.Request, readPatches patchesReader) {
 	templateId := req.PathParams["templateId"]
 	templateInt, err := c.repository.GetData(c.buildTemplateKey(templateId), models.Template{})
 	if err != nil {
@@ -91,17 +161,13 @@ func (c *Context) PatchTemplate(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
-	patches := []models.Patch{}
-	err = commonHttp.ReadJson(req, &patches)
+	patches, err := readPatches(req, template)
 	if err != nil {
 		commonHttp.Respond400(rw, err)
 		return
 	}
 
-	fsmFunc := func() *fsm.FSM {
-		return c.getTemplatesFSM(template.State)
-	}
-	if err = c.handleFsm(rw, req, patches, fsmFunc); err != nil {
+	if err = c.handleFsm(rw, req, patches, models.EntityTypeTemplate, string(template.State)); err != nil {
 		return
 	}
 
@@ -111,16 +177,41 @@ func (c *Context) PatchTemplate(rw web.ResponseWriter, req *web.Request) {
 		return
 	}
 
+	if isDryRun(req) {
+		c.respondDryRunPatch(rw, template, patches)
+		return
+	}
+
 	err = c.repository.ApplyPatchedValues(patchedValues)
 	if err != nil {
 		commonHttp.HandleError(rw, err)
 		return
 	}
+	c.recordStateTransition(req, models.EntityTypeTemplate, templateId, string(template.State), patches)
+	c.recordUpdateAudit(models.EntityTypeTemplate, templateId, "", template, patches)
 
 	templateInt, err = c.repository.GetData(c.buildTemplateKey(templateId), models.Template{})
 	commonHttp.WriteJsonOrError(rw, templateInt, http.StatusOK, err)
 }
 
+func (c *Context) MonitorTemplatesStates(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificState(rw, req, c.getTemplateKey())
+}
+
+func (c *Context) MonitorSpecificTemplateState(rw web.ResponseWriter, req *web.Request) {
+	templateId := req.PathParams["templateId"]
+	c.monitorSpecificState(rw, req, c.buildTemplateKey(templateId))
+}
+
+func (c *Context) MonitorTemplatesChanges(rw web.ResponseWriter, req *web.Request) {
+	c.monitorSpecificChange(rw, req, c.getTemplateKey())
+}
+
+func (c *Context) MonitorSpecificTemplateChange(rw web.ResponseWriter, req *web.Request) {
+	templateId := req.PathParams["templateId"]
+	c.monitorSpecificChange(rw, req, c.buildTemplateKey(templateId))
+}
+
 func (c *Context) getTemplateKey() string {
 	org := c.mapper.ToKey("", c.organization)
 	return c.mapper.ToKey(org, data.Templates)
@@ -129,17 +220,3 @@ func (c *Context) getTemplateKey() string {
 func (c *Context) buildTemplateKey(templateId string) string {
 	return c.mapper.ToKey(c.getTemplateKey(), templateId)
 }
-
-func (c *Context) getTemplatesFSM(initialState models.TemplateState) *fsm.FSM {
-	return fsm.NewFSM(string(initialState),
-		fsm.Events{
-			{Name: "READY", Src: []string{"IN_PROGRESS"}, Dst: "READY"},
-			{Name: "UNAVAILABLE", Src: []string{"IN_PROGRESS"}, Dst: "UNAVAILABLE"},
-		},
-		fsm.Callbacks{
-			"enter_state": func(e *fsm.Event) {
-				c.enterState(e)
-			},
-		},
-	)
-}
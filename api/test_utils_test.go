@@ -17,8 +17,10 @@
 package api
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gocraft/web"
@@ -26,13 +28,31 @@ import (
 
 	"github.com/trustedanalytics-ng/tap-catalog/client"
 	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	testUser     = "user"
+	testPassword = "password"
 )
 
 type MockPack struct {
 	repositoryMock *data.MockRepositoryApi
 }
 
+// prepareMocksAndClient accepts any audit log entries and revisions - handlers add them after every write,
+// they are verified by audit and revisions tests which use prepareStrictMocksAndClient
 func prepareMocksAndClient(t *testing.T) (mockCtrl *gomock.Controller, c Context, mocks MockPack, catalogClient client.TapCatalogApi) {
+	mockCtrl, c, mocks, catalogClient = prepareStrictMocksAndClient(t)
+	mocks.repositoryMock.EXPECT().AddAuditEntry(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mocks.repositoryMock.EXPECT().AddRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Revision{}, nil).AnyTimes()
+	mocks.repositoryMock.EXPECT().DeleteStateHistory(gomock.Any()).Return(nil).AnyTimes()
+	mocks.repositoryMock.EXPECT().DeleteRevisions(gomock.Any()).Return(nil).AnyTimes()
+	return
+}
+
+func prepareStrictMocksAndClient(t *testing.T) (mockCtrl *gomock.Controller, c Context, mocks MockPack, catalogClient client.TapCatalogApi) {
 	mockCtrl = gomock.NewController(t)
 	mocks = MockPack{
 		repositoryMock: data.NewMockRepositoryApi(mockCtrl),
@@ -46,16 +66,41 @@ func prepareMocksAndClient(t *testing.T) (mockCtrl *gomock.Controller, c Context
 }
 
 func getCatalogClient(router *web.Router, t *testing.T) client.TapCatalogApi {
-	const user = "user"
-	const password = "password"
-
-	os.Setenv("CATALOG_USER", user)
-	os.Setenv("CATALOG_PASS", password)
+	os.Setenv("CATALOG_USER", testUser)
+	os.Setenv("CATALOG_PASS", testPassword)
 
 	testServer := httptest.NewServer(router)
-	catalogClient, err := client.NewTapCatalogApiWithBasicAuth(testServer.URL, user, password)
+	catalogClient, err := client.NewTapCatalogApiWithBasicAuth(testServer.URL, testUser, testPassword)
 	if err != nil {
 		t.Fatal("Catalog client error: ", err)
 	}
 	return catalogClient
 }
+
+// sendAuthorizedRequest is used for cases which are not covered by catalog client, e.g. query parameters
+func sendAuthorizedRequest(c Context, rType, path string, body []byte, t *testing.T) *httptest.ResponseRecorder {
+	return sendAuthorizedRequestWithHeaders(c, rType, path, body, http.Header{}, t)
+}
+
+func sendAuthorizedRequestWithHeaders(c Context, rType, path string, body []byte, header http.Header, t *testing.T) *httptest.ResponseRecorder {
+	header.Set("Authorization", commonHttp.GetBasicAuthHeader(&commonHttp.BasicAuth{User: testUser, Password: testPassword}))
+	return commonHttp.SendRequestWithHeaders(rType, path, body, SetupRouter(c), header, t)
+}
+
+// trashEntryKeyMatcher matches key of any trash entry of object, as entries are named by deletion time
+type trashEntryKeyMatcher struct {
+	trashKey string
+}
+
+func isTrashEntryOf(trashKey string) gomock.Matcher {
+	return trashEntryKeyMatcher{trashKey: trashKey}
+}
+
+func (m trashEntryKeyMatcher) Matches(x interface{}) bool {
+	key, ok := x.(string)
+	return ok && strings.HasPrefix(key, m.trashKey+"/")
+}
+
+func (m trashEntryKeyMatcher) String() string {
+	return "is trash entry of " + m.trashKey
+}
@@ -0,0 +1,340 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/gocraft/web"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const (
+	purgeQueryParam = "purge"
+
+	trashRetentionEnv     = "TRASH_RETENTION"
+	defaultTrashRetention = 7 * 24 * time.Hour
+
+	trashSweepIntervalEnv     = "TRASH_SWEEP_INTERVAL"
+	defaultTrashSweepInterval = time.Hour
+)
+
+// trashResource describes collection which objects are moved to trash on delete
+type trashResource struct {
+	dir        string
+	model      interface{}
+	entityType models.EntityType
+	uniqueName bool
+}
+
+var trashResources = map[string]trashResource{
+	"services":     {dir: data.Services, model: models.Service{}, entityType: models.EntityTypeService, uniqueName: true},
+	"applications": {dir: data.Applications, model: models.Application{}, entityType: models.EntityTypeApplication, uniqueName: true},
+	"templates":    {dir: data.Templates, model: models.Template{}, entityType: models.EntityTypeTemplate},
+	"images":       {dir: data.Images, model: models.Image{}, entityType: models.EntityTypeImage},
+}
+
+// deleteOrMoveToTrash removes object permanently only if purge was requested,
+// otherwise it is moved to trash from which it can be restored until retention period passes
+func (c *Context) deleteOrMoveToTrash(rw web.ResponseWriter, req *web.Request, resourceName, id string) {
+	err := c.deleteEntity(resourceName, id, isPurge(req))
+	commonHttp.WriteJsonOrError(rw, "", http.StatusNoContent, err)
+}
+
+func (c *Context) deleteEntity(resourceName, id string, purge bool) error {
+	resource := trashResources[resourceName]
+	key := c.mapper.ToKey(data.GetEntityKey(c.organization, resource.dir), id)
+
+	entity, err := c.repository.GetData(key, resource.model)
+	if err != nil {
+		return err
+	}
+
+	if purge {
+		return c.purgeEntity(key, resource.entityType, id, "", entity)
+	}
+
+	entityJson, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+
+	retention := getTrashRetention()
+	now := time.Now()
+	entry := models.TrashEntry{
+		Id:                id,
+		EntityType:        resource.entityType,
+		DeletedOn:         now.Unix(),
+		DeletedBy:         c.mapper.Username,
+		DeletedOnBehalfOf: c.actingUser,
+		PurgeOn:           now.Add(retention).Unix(),
+		Entity:            entityJson,
+	}
+
+	trashKey := c.buildTrashEntryKey(resource.dir, id, now)
+	if err = c.repository.SetTrashEntry(trashKey, entry, retention); err != nil {
+		return fmt.Errorf("cannot move %s %q to trash: %v", resource.entityType, id, err)
+	}
+
+	if err = c.repository.DeleteData(key); err != nil {
+		if err := c.repository.DeleteTrashEntry(trashKey); err != nil {
+			logger.Errorf("cannot remove trash entry of not deleted %s %q: %v", resource.entityType, id, err)
+		}
+		return err
+	}
+	c.recordDeleteAudit(resource.entityType, id, "", entity)
+	return nil
+}
+
+// purgeEntity removes object (the key) permanently together with its state history and revisions and records
+// its removal, entity is the object read before
+func (c *Context) purgeEntity(key string, entityType models.EntityType, id, parentId string, entity interface{}) error {
+	if err := c.repository.DeleteData(key); err != nil {
+		return err
+	}
+	c.recordDeleteAudit(entityType, id, parentId, entity)
+	c.removeStateHistory(entityType, id)
+	c.removeRevisions(entityType, id, parentId)
+	return nil
+}
+
+// restoreEntity writes object saved before its removal or change back to its collection (the dirKey) and stores it
+// as the next revision. Object read after the write is returned, audit is left to the caller as the kind
+// of change differs.
+func (c *Context) restoreEntity(dirKey string, model interface{}, entityType models.EntityType, id, parentId string,
+	entity interface{}) (interface{}, error) {
+
+	if err := c.repository.CreateData(c.mapper.ToKeyValue(dirKey, entity, true)); err != nil {
+		return nil, err
+	}
+
+	restored, err := c.repository.GetData(c.mapper.ToKey(dirKey, id), model)
+	if err != nil {
+		return nil, err
+	}
+	c.recordRevision(entityType, id, parentId, restored)
+	return restored, nil
+}
+
+func isPurge(req *web.Request) bool {
+	return req.URL.Query().Get(purgeQueryParam) == "true"
+}
+
+func (c *Context) Trash(rw web.ResponseWriter, req *web.Request) {
+	entries, err := c.repository.GetTrashEntries(c.getTrashKey())
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	if entityType := req.URL.Query().Get("entityType"); entityType != "" {
+		expectedType, err := models.ParseEntityType(entityType)
+		if err != nil {
+			commonHttp.Respond400(rw, err)
+			return
+		}
+
+		filtered := []models.TrashEntry{}
+		for _, entry := range entries {
+			if entry.EntityType == expectedType {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+	commonHttp.WriteJson(rw, entries, http.StatusOK)
+}
+
+func (c *Context) GetTrashEntry(rw web.ResponseWriter, req *web.Request) {
+	resource, ok := getTrashResource(rw, req)
+	if !ok {
+		return
+	}
+
+	_, entry, err := c.repository.GetTrashEntry(c.buildTrashKey(resource.dir, req.PathParams["id"]))
+	commonHttp.WriteJsonOrError(rw, entry, http.StatusOK, err)
+}
+
+// RestoreTrashEntry recreates object with its original id from its latest trash entry - it fails if the id or unique
+// name is already in use
+func (c *Context) RestoreTrashEntry(rw web.ResponseWriter, req *web.Request) {
+	resource, ok := getTrashResource(rw, req)
+	if !ok {
+		return
+	}
+	id := req.PathParams["id"]
+	dirKey := data.GetEntityKey(c.organization, resource.dir)
+
+	trashKey, entry, err := c.repository.GetTrashEntry(c.buildTrashKey(resource.dir, id))
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+
+	entity := reflect.New(reflect.TypeOf(resource.model))
+	if err = json.Unmarshal(entry.Entity, entity.Interface()); err != nil {
+		commonHttp.Respond500(rw, fmt.Errorf("cannot read %s %q from trash: %v", resource.entityType, id, err))
+		return
+	}
+
+	if resource.uniqueName {
+		name := entity.Elem().FieldByName("Name").String()
+		exists, err := c.repository.IsExistByName(name, resource.model, dirKey)
+		if err != nil {
+			commonHttp.Respond500(rw, err)
+			return
+		}
+		if exists {
+			commonHttp.Respond409(rw, fmt.Errorf("cannot restore %s %q: name %q is already used", resource.entityType, id, name))
+			return
+		}
+	}
+
+	restored, err := c.restoreEntity(dirKey, resource.model, resource.entityType, id, "", entity.Elem().Interface())
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	c.recordCreateAudit(resource.entityType, id, "", entity.Elem().Interface())
+
+	if err = c.repository.DeleteTrashEntry(trashKey); err != nil {
+		logger.Errorf("cannot remove trash entry of restored %s %q: %v", resource.entityType, id, err)
+	}
+	commonHttp.WriteJson(rw, restored, http.StatusOK)
+}
+
+// PurgeTrashEntry removes all trash entries of object id
+func (c *Context) PurgeTrashEntry(rw web.ResponseWriter, req *web.Request) {
+	resource, ok := getTrashResource(rw, req)
+	if !ok {
+		return
+	}
+
+	id := req.PathParams["id"]
+	if err := c.repository.DeleteTrashEntry(c.buildTrashKey(resource.dir, id)); err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	c.removeStateHistory(resource.entityType, id)
+	c.removeRevisions(resource.entityType, id, "")
+	commonHttp.WriteJson(rw, "", http.StatusNoContent)
+}
+
+// SweepTrashPeriodically runs SweepTrash on startup and then every TRASH_SWEEP_INTERVAL, it never returns
+func (c *Context) SweepTrashPeriodically() {
+	ticker := time.NewTicker(getTrashSweepInterval())
+	defer ticker.Stop()
+	for {
+		c.SweepTrash()
+		<-ticker.C
+	}
+}
+
+// SweepTrash cleans up after trash entries removed by etcd when their retention passed: state history
+// and revisions of expired objects are removed like in PurgeTrashEntry, together with empty directories of their ids.
+// Directory of restored object becomes empty as well - it is removed, but history and revisions of the object are kept.
+func (c *Context) SweepTrash() {
+	for _, resource := range trashResources {
+		dirKeys, err := c.repository.GetEmptyTrashDirs(c.mapper.ToKey(c.getTrashKey(), resource.dir))
+		if err != nil {
+			if !commonHttp.IsNotFoundError(err) {
+				logger.Errorf("cannot read trash of %s: %v", resource.entityType, err)
+			}
+			continue
+		}
+
+		for _, dirKey := range dirKeys {
+			// object moved to trash again meanwhile makes the directory not empty
+			if err := c.repository.DeleteEmptyTrashDir(dirKey); err != nil {
+				logger.Warningf("cannot remove trash directory %q: %v", dirKey, err)
+				continue
+			}
+
+			id := path.Base(dirKey)
+			_, err := c.repository.GetData(c.mapper.ToKey(data.GetEntityKey(c.organization, resource.dir), id), resource.model)
+			if err == nil {
+				continue
+			} else if !commonHttp.IsNotFoundError(err) {
+				logger.Errorf("cannot check if expired %s %q was restored: %v", resource.entityType, id, err)
+				continue
+			}
+			c.removeStateHistory(resource.entityType, id)
+			c.removeRevisions(resource.entityType, id, "")
+		}
+	}
+}
+
+// removeFromTrash drops trash entry of object which deletion was reverted
+func (c *Context) removeFromTrash(dirKey, id string) {
+	for _, resource := range trashResources {
+		if dirKey != data.GetEntityKey(c.organization, resource.dir) {
+			continue
+		}
+		trashKey, _, err := c.repository.GetTrashEntry(c.buildTrashKey(resource.dir, id))
+		if err == nil {
+			err = c.repository.DeleteTrashEntry(trashKey)
+		}
+		if err != nil {
+			logger.Errorf("cannot remove trash entry of restored %s %q: %v", resource.entityType, id, err)
+		}
+	}
+}
+
+func getTrashResource(rw web.ResponseWriter, req *web.Request) (trashResource, bool) {
+	resource, ok := trashResources[req.PathParams["resource"]]
+	if !ok {
+		commonHttp.Respond404(rw, fmt.Errorf("trash of %q not found", req.PathParams["resource"]))
+	}
+	return resource, ok
+}
+
+func getTrashRetention() time.Duration {
+	retention, err := time.ParseDuration(os.Getenv(trashRetentionEnv))
+	if err != nil || retention <= 0 {
+		return defaultTrashRetention
+	}
+	return retention
+}
+
+func getTrashSweepInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv(trashSweepIntervalEnv))
+	if err != nil || interval <= 0 {
+		return defaultTrashSweepInterval
+	}
+	return interval
+}
+
+func (c *Context) getTrashKey() string {
+	return data.GetEntityKey(c.organization, data.Trash)
+}
+
+// buildTrashKey returns directory of trash entries of object id
+func (c *Context) buildTrashKey(dir, id string) string {
+	return c.mapper.ToKey(c.mapper.ToKey(c.getTrashKey(), dir), id)
+}
+
+func (c *Context) buildTrashEntryKey(dir, id string, deletedOn time.Time) string {
+	return c.mapper.ToKey(c.buildTrashKey(dir, id), fmt.Sprintf("%020d", deletedOn.UnixNano()))
+}
@@ -0,0 +1,213 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestTrash(t *testing.T) {
+	Convey("Testing soft delete and trash", t, func() {
+		mockCtrl, context, mocks, _ := prepareMocksAndClient(t)
+		template := models.Template{Id: sampleID1, State: models.TemplateStateReady}
+		service := models.Service{Id: sampleID1, Name: sampleName1, State: models.ServiceStateReady}
+		serviceJson, _ := json.Marshal(service)
+		serviceEntry := models.TrashEntry{Id: sampleID1, EntityType: models.EntityTypeService, Entity: serviceJson}
+		serviceEntryKey := context.buildTrashEntryKey(data.Services, sampleID1, time.Unix(1, 0))
+
+		Convey("When Template is deleted, it should be moved to trash", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			var entry models.TrashEntry
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1)), gomock.Any(), gomock.Any()).Return(nil).Do(
+					func(key string, trashEntry models.TrashEntry, ttl interface{}) {
+						entry = trashEntry
+					}),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/templates/"+sampleID1, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+			So(entry.Id, ShouldEqual, sampleID1)
+			So(entry.EntityType, ShouldEqual, models.EntityTypeTemplate)
+			So(entry.DeletedBy, ShouldEqual, testUser)
+			So(entry.PurgeOn, ShouldBeGreaterThan, entry.DeletedOn)
+		})
+
+		Convey("When Template is deleted on behalf of other user, the user should be recorded in trash", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			var entry models.TrashEntry
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(template, nil),
+				mocks.repositoryMock.EXPECT().SetTrashEntry(isTrashEntryOf(context.buildTrashKey(data.Templates, sampleID1)), gomock.Any(), gomock.Any()).Return(nil).Do(
+					func(key string, trashEntry models.TrashEntry, ttl interface{}) {
+						entry = trashEntry
+					}),
+				mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil),
+			)
+
+			header := http.Header{}
+			header.Set(models.ActingUserHeader, "admin")
+			rr := sendAuthorizedRequestWithHeaders(context, "DELETE", "/api/v1/templates/"+sampleID1, nil, header, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+			So(entry.DeletedBy, ShouldEqual, testUser)
+			So(entry.DeletedOnBehalfOf, ShouldEqual, "admin")
+		})
+
+		Convey("When Template is deleted with purge, it should be removed permanently", func() {
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getServiceKey(), models.Service{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetListOfData(context.getApplicationKey(), models.Application{}).Return([]interface{}{}, nil)
+			mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(models.Template{Id: sampleID1}, nil)
+			mocks.repositoryMock.EXPECT().DeleteData(context.buildTemplateKey(sampleID1)).Return(nil)
+
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/templates/"+sampleID1+"?purge=true", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNoContent)
+		})
+
+		Convey("When trash is listed with entityType filter, only matching entries should be returned", func() {
+			mocks.repositoryMock.EXPECT().GetTrashEntries(context.getTrashKey()).Return([]models.TrashEntry{
+				serviceEntry, {Id: sampleID2, EntityType: models.EntityTypeTemplate},
+			}, nil)
+
+			rr := sendAuthorizedRequest(context, "GET", "/api/v1/trash?entityType=template", nil, t)
+			So(rr.Code, ShouldEqual, http.StatusOK)
+
+			entries := []models.TrashEntry{}
+			So(json.Unmarshal(rr.Body.Bytes(), &entries), ShouldBeNil)
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].Id, ShouldEqual, sampleID2)
+		})
+
+		Convey("When Service is restored, it should be recreated and removed from trash", func() {
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetTrashEntry(context.buildTrashKey(data.Services, sampleID1)).Return(serviceEntryKey, serviceEntry, nil),
+				mocks.repositoryMock.EXPECT().IsExistByName(sampleName1, models.Service{}, context.getServiceKey()).Return(false, nil),
+				mocks.repositoryMock.EXPECT().CreateData(gomock.Any()).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(service, nil),
+				mocks.repositoryMock.EXPECT().DeleteTrashEntry(serviceEntryKey).Return(nil),
+			)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/trash/services/"+sampleID1+"/restore", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("When name of restored Service is already used, response status should be Conflict", func() {
+			mocks.repositoryMock.EXPECT().GetTrashEntry(context.buildTrashKey(data.Services, sampleID1)).Return(serviceEntryKey, serviceEntry, nil)
+			mocks.repositoryMock.EXPECT().IsExistByName(sampleName1, models.Service{}, context.getServiceKey()).Return(true, nil)
+
+			rr := sendAuthorizedRequest(context, "POST", "/api/v1/trash/services/"+sampleID1+"/restore", nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusConflict)
+		})
+
+		Convey("When trash of not supported resource is requested, response status should be NotFound", func() {
+			rr := sendAuthorizedRequest(context, "DELETE", "/api/v1/trash/instances/"+sampleID1, nil, t)
+
+			So(rr.Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestSweepTrash(t *testing.T) {
+	Convey("Testing sweep of expired trash entries", t, func() {
+		mockCtrl, context, mocks, _ := prepareStrictMocksAndClient(t)
+		expectEmptyTrashDirs := func(dir string, ids ...string) {
+			dirKeys := []string{}
+			for _, id := range ids {
+				dirKeys = append(dirKeys, context.buildTrashKey(dir, id))
+			}
+			mocks.repositoryMock.EXPECT().GetEmptyTrashDirs(context.mapper.ToKey(context.getTrashKey(), dir)).Return(dirKeys, nil)
+		}
+
+		Convey("When trash entry of Template expired, its history and directory should be removed", func() {
+			expectEmptyTrashDirs(data.Services)
+			expectEmptyTrashDirs(data.Applications)
+			expectEmptyTrashDirs(data.Images)
+			expectEmptyTrashDirs(data.Templates, sampleID1)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().DeleteEmptyTrashDir(context.buildTrashKey(data.Templates, sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildTemplateKey(sampleID1), models.Template{}).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().DeleteStateHistory(context.buildStateHistoryKey(models.EntityTypeTemplate, sampleID1)).Return(nil),
+			)
+
+			context.SweepTrash()
+		})
+
+		Convey("When trash entry of Service expired, its history, revisions and directory should be removed", func() {
+			expectEmptyTrashDirs(data.Services, sampleID1)
+			expectEmptyTrashDirs(data.Applications)
+			expectEmptyTrashDirs(data.Images)
+			expectEmptyTrashDirs(data.Templates)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().DeleteEmptyTrashDir(context.buildTrashKey(data.Services, sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(nil, errors.New("Key not found")),
+				mocks.repositoryMock.EXPECT().DeleteStateHistory(context.buildStateHistoryKey(models.EntityTypeService, sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteRevisions(context.buildRevisionsKey(revisionTarget{entityType: models.EntityTypeService, id: sampleID1})).Return(nil),
+				mocks.repositoryMock.EXPECT().DeleteRevisions(context.mapper.ToKey(context.mapper.ToKey(context.getRevisionsDirKey(), data.Plans), sampleID1)).Return(nil),
+			)
+
+			context.SweepTrash()
+		})
+
+		Convey("When Service was restored, only its empty trash directory should be removed", func() {
+			expectEmptyTrashDirs(data.Services, sampleID1)
+			expectEmptyTrashDirs(data.Applications)
+			expectEmptyTrashDirs(data.Images)
+			expectEmptyTrashDirs(data.Templates)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().DeleteEmptyTrashDir(context.buildTrashKey(data.Services, sampleID1)).Return(nil),
+				mocks.repositoryMock.EXPECT().GetData(context.buildServiceKey(sampleID1), models.Service{}).Return(models.Service{Id: sampleID1}, nil),
+			)
+
+			context.SweepTrash()
+		})
+
+		Convey("When Service was moved to trash again meanwhile, nothing should be removed", func() {
+			expectEmptyTrashDirs(data.Services, sampleID1)
+			expectEmptyTrashDirs(data.Applications)
+			expectEmptyTrashDirs(data.Images)
+			expectEmptyTrashDirs(data.Templates)
+			mocks.repositoryMock.EXPECT().DeleteEmptyTrashDir(context.buildTrashKey(data.Services, sampleID1)).Return(errors.New("Directory not empty"))
+
+			context.SweepTrash()
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -45,3 +45,33 @@ func (c *Context) monitorSpecificState(rw web.ResponseWriter, req *web.Request,
 	}
 	commonHttp.WriteJson(rw, result, http.StatusOK)
 }
+
+func (c *Context) monitorInstancesStatesOfClass(rw web.ResponseWriter, req *web.Request, instanceType models.InstanceType, classId string) {
+	afterIndex, err := strconv.ParseUint(req.URL.Query().Get("afterIndex"), 10, 32)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.repository.MonitorInstancesStatesOfClass(c.getInstanceKey(), instanceType, classId, afterIndex)
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	commonHttp.WriteJson(rw, result, http.StatusOK)
+}
+
+func (c *Context) monitorSpecificChange(rw web.ResponseWriter, req *web.Request, key string) {
+	afterIndex, err := strconv.ParseUint(req.URL.Query().Get("afterIndex"), 10, 32)
+	if err != nil {
+		commonHttp.Respond400(rw, err)
+		return
+	}
+
+	result, err := c.repository.MonitorObjectsChanges(key, afterIndex)
+	if err != nil {
+		commonHttp.HandleError(rw, err)
+		return
+	}
+	commonHttp.WriteJson(rw, result, http.StatusOK)
+}
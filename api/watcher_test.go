@@ -17,6 +17,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -57,3 +58,115 @@ func TestLatestIndex(t *testing.T) {
 		})
 	})
 }
+
+func TestMonitorChanges(t *testing.T) {
+	changeEvent := models.ChangeEvent{
+		Type:       models.ChangeEventTypeUpdated,
+		EntityType: models.EntityTypeInstance,
+		Id:         instanceId,
+		Fields:     []string{"Bindings"},
+		Index:      6,
+	}
+
+	Convey("Testing MonitorSpecificInstanceChange", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+
+		Convey("Request correct, response status is 200", func() {
+			afterIndex := uint64(5)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().MonitorObjectsChanges(context.buildInstanceKey(instanceId), afterIndex).Return(changeEvent, nil),
+			)
+
+			response, status, err := catalogClient.WatchInstanceChanges(instanceId, afterIndex)
+
+			So(status, ShouldEqual, http.StatusOK)
+			So(err, ShouldBeNil)
+			So(response, ShouldResemble, changeEvent)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+
+	Convey("Testing MonitorSpecificPlanChange", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+
+		Convey("Request correct, response status is 200", func() {
+			afterIndex := uint64(5)
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().MonitorObjectsChanges(context.getServicedPlanIDKey(serviceId, planId), afterIndex).Return(changeEvent, nil),
+			)
+
+			_, status, err := catalogClient.WatchServicePlanChanges(serviceId, planId, afterIndex)
+
+			So(status, ShouldEqual, http.StatusOK)
+			So(err, ShouldBeNil)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestMonitorStates(t *testing.T) {
+	stateChange := models.StateChange{
+		Id:    sampleID1,
+		State: "READY",
+	}
+
+	Convey("Testing MonitorSpecificServiceState", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+
+		Convey("Request correct, response status is 200", func() {
+			afterIndex := models.WatchFromNow
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().MonitorObjectsStates(context.buildServiceKey(serviceId), afterIndex).Return(stateChange, nil),
+			)
+
+			response, status, err := catalogClient.WatchService(serviceId, afterIndex)
+
+			So(status, ShouldEqual, http.StatusOK)
+			So(err, ShouldBeNil)
+			So(response, ShouldResemble, stateChange)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+
+	Convey("Testing MonitorSpecificApplicationState", t, func() {
+		mockCtrl, context, mocks, catalogClient := prepareMocksAndClient(t)
+
+		Convey("Application exists, application instance state change should be returned", func() {
+			afterIndex := models.WatchFromNow
+			gomock.InOrder(
+				mocks.repositoryMock.EXPECT().GetData(context.buildApplicationKey(sampleID1), models.Application{}).Return(models.Application{Id: sampleID1}, nil),
+				mocks.repositoryMock.EXPECT().MonitorInstancesStatesOfClass(context.getInstanceKey(), models.InstanceTypeApplication, sampleID1, afterIndex).
+					Return(stateChange, nil),
+			)
+
+			response, status, err := catalogClient.WatchApplication(sampleID1, afterIndex)
+
+			So(status, ShouldEqual, http.StatusOK)
+			So(err, ShouldBeNil)
+			So(response, ShouldResemble, stateChange)
+		})
+
+		Convey("Application does not exist, response status is 404", func() {
+			mocks.repositoryMock.EXPECT().GetData(context.buildApplicationKey(sampleID1), models.Application{}).
+				Return(models.Application{}, errors.New("key not found"))
+
+			_, status, err := catalogClient.WatchApplication(sampleID1, models.WatchFromNow)
+
+			So(status, ShouldEqual, http.StatusNotFound)
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
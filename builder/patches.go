@@ -18,6 +18,7 @@ package builder
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/trustedanalytics-ng/tap-catalog/models"
 	commonLogger "github.com/trustedanalytics-ng/tap-go-common/logger"
@@ -26,12 +27,13 @@ import (
 var logger, _ = commonLogger.InitLogger("builder")
 
 // message is optional, if pass then LAST_STATE_CHANGE_REASON key will be added/updated in Instance Metadata
+// and the message is kept as reason in state history of the instance
 func MakePatchesForInstanceStateAndLastStateMetadata(message string, currentState, stateToSet models.InstanceState) ([]models.Patch, error) {
 	if stateToSet == models.InstanceStateDestroyReq && currentState == models.InstanceStateFailure {
 		message = models.ReasonDeleteFailure
 	}
 
-	patches, err := makePatchesForStateUpdate(currentState.String(), stateToSet.String())
+	patches, err := MakePatchesForStateUpdateWithReason(currentState.String(), stateToSet.String(), message)
 	if err != nil {
 		return patches, err
 	}
@@ -53,6 +55,25 @@ func MakePatchesForOfferingStateUpdate(currentState, stateToSet models.ServiceSt
 	return makePatchesForStateUpdate(string(currentState), string(stateToSet))
 }
 
+// MakePatchesForStateUpdateWithReason can be used for state of any entity, reason is kept in its state history
+func MakePatchesForStateUpdateWithReason(currentState, stateToSet, reason string) ([]models.Patch, error) {
+	patches, err := makePatchesForStateUpdate(currentState, stateToSet)
+	if err != nil {
+		return patches, err
+	}
+	return WithStateChangeReason(patches, reason), nil
+}
+
+// WithStateChangeReason sets reason of patches which change state
+func WithStateChangeReason(patches []models.Patch, reason string) []models.Patch {
+	for i, patch := range patches {
+		if patch.Field != nil && strings.EqualFold(*patch.Field, "state") {
+			patches[i].Reason = reason
+		}
+	}
+	return patches
+}
+
 func makePatchesForStateUpdate(currentState, stateToSet string) ([]models.Patch, error) {
 	if currentState == "" || stateToSet == "" {
 		return nil, errors.New("currentState and stateToSet cannot be empty!")
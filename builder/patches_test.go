@@ -124,6 +124,7 @@ func TestMakePatchesForInstanceStateAndLastStateMetadata(t *testing.T) {
 
 			So(*patches[0].Value, ShouldResemble, json.RawMessage(byteStateValue))
 			So(patches[0].PrevValue, ShouldResemble, json.RawMessage(byteOldValue))
+			So(patches[0].Reason, ShouldEqual, message)
 			So(*patches[1].Field, ShouldEqual, "Metadata")
 
 			So(patches[1].Operation, ShouldEqual, models.OperationAdd)
@@ -160,3 +161,34 @@ func TestMakePatchesForOfferingStateUpdate(t *testing.T) {
 		})
 	})
 }
+
+func TestMakePatchesForStateUpdateWithReason(t *testing.T) {
+	Convey("Test MakePatchesForStateUpdateWithReason", t, func() {
+		Convey("Should return error if PrevValue not set", func() {
+			_, err := MakePatchesForStateUpdateWithReason("", string(models.ServiceStateReady), "reason")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should return state patch with reason", func() {
+			patches, err := MakePatchesForStateUpdateWithReason(string(models.ServiceStateDeploying), string(models.ServiceStateReady), "deployed")
+			So(err, ShouldBeNil)
+			So(len(patches), ShouldEqual, 1)
+			So(*patches[0].Field, ShouldEqual, "State")
+			So(patches[0].Reason, ShouldEqual, "deployed")
+		})
+	})
+}
+
+func TestWithStateChangeReason(t *testing.T) {
+	Convey("Test WithStateChangeReason", t, func() {
+		stateField, nameField := "State", "Name"
+		patches := []models.Patch{{Field: &nameField}, {Field: &stateField}, {}}
+
+		result := WithStateChangeReason(patches, "reason")
+		Convey("Should set reason only on state patches", func() {
+			So(result[0].Reason, ShouldBeEmpty)
+			So(result[1].Reason, ShouldEqual, "reason")
+			So(result[2].Reason, ShouldBeEmpty)
+		})
+	})
+}
@@ -56,3 +56,25 @@ func (c *TapCatalogApiConnector) DeleteApplication(applicationId string) (int, e
 	status, err := brokerHttp.DeleteModel(connector, http.StatusNoContent)
 	return status, err
 }
+
+func (c *TapCatalogApiConnector) WatchApplications(afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, applications, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchApplication(applicationId string, afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, applications, applicationId, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchApplicationsChanges(afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, applications, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchApplicationChanges(applicationId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, applications, applicationId, nextChange, afterIndex))
+}
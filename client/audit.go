@@ -0,0 +1,57 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// GetAuditLog returns audit entries of organization matching the filter, the oldest first
+func (c *TapCatalogApiConnector) GetAuditLog(filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	query := url.Values{}
+	if filter.EntityType != "" {
+		query.Set("entityType", string(filter.EntityType))
+	}
+	if filter.EntityId != "" {
+		query.Set("entityId", filter.EntityId)
+	}
+	if filter.User != "" {
+		query.Set("user", filter.User)
+	}
+	if filter.Since != 0 {
+		query.Set("since", time.Unix(0, filter.Since).UTC().Format(time.RFC3339Nano))
+	}
+	if filter.Until != 0 {
+		query.Set("until", time.Unix(0, filter.Until).UTC().Format(time.RFC3339Nano))
+	}
+	if filter.Limit != 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s?%s", c.Address, audit, query.Encode()))
+	result := []models.AuditEntry{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
@@ -0,0 +1,31 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func (c *TapCatalogApiConnector) Batch(batch models.BatchRequest) (models.BatchResponse, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, batchOps))
+	result := &models.BatchResponse{}
+	status, err := brokerHttp.PostModel(connector, batch, http.StatusOK, result)
+	return *result, status, err
+}
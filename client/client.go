@@ -34,11 +34,17 @@ type TapCatalogApi interface {
 	AddTemplate(template models.Template) (models.Template, int, error)
 	GetApplication(applicationId string) (models.Application, int, error)
 	GetCatalogHealth() (int, error)
+	GetCatalogReadiness() (models.Readiness, int, error)
+	GetCatalogHealthDetails() (models.HealthDetails, int, error)
 	GetImage(imageId string) (models.Image, int, error)
 	GetImageRefs(imageId string) (models.ImageRefsResponse, int, error)
 	GetInstance(instanceId string) (models.Instance, int, error)
 	GetInstanceBindings(instanceId string) ([]models.Instance, int, error)
+	GetInstanceBoundBy(instanceId string) ([]models.Instance, int, error)
+	AddInstanceBinding(instanceId string, binding models.InstanceBindings) (models.Instance, int, error)
+	DeleteInstanceBinding(instanceId, targetId string) (int, error)
 	GetServicePlan(serviceId, planId string) (models.ServicePlan, int, error)
+	CheckPlanDependencies() ([]models.PlanDependencyReport, int, error)
 	GetService(serviceId string) (models.Service, int, error)
 	GetServices() ([]models.Service, int, error)
 	GetLatestIndex() (models.Index, int, error)
@@ -64,7 +70,42 @@ type TapCatalogApi interface {
 	WatchInstance(instanceId string, afterIndex uint64) (models.StateChange, int, error)
 	WatchImages(afterIndex uint64) (models.StateChange, int, error)
 	WatchImage(imageId string, afterIndex uint64) (models.StateChange, int, error)
-	CheckStateStability() (models.StateStability, int, error)
+	WatchApplications(afterIndex uint64) (models.StateChange, int, error)
+	WatchApplication(applicationId string, afterIndex uint64) (models.StateChange, int, error)
+	WatchServices(afterIndex uint64) (models.StateChange, int, error)
+	WatchService(serviceId string, afterIndex uint64) (models.StateChange, int, error)
+	WatchServicePlan(serviceId, planId string, afterIndex uint64) (models.StateChange, int, error)
+	WatchTemplates(afterIndex uint64) (models.StateChange, int, error)
+	WatchTemplate(templateId string, afterIndex uint64) (models.StateChange, int, error)
+	WatchApplicationsChanges(afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchApplicationChanges(applicationId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchImagesChanges(afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchImageChanges(imageId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchInstancesChanges(afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchInstanceChanges(instanceId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchServicesChanges(afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchServiceChanges(serviceId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchServicePlanChanges(serviceId, planId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchTemplatesChanges(afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchTemplateChanges(templateId string, afterIndex uint64) (models.ChangeEvent, int, error)
+	WatchEvents(filter models.EventsFilter, afterIndex uint64, stop <-chan struct{}) (<-chan models.StateChangeEvent, <-chan error)
+	CheckStateStability(options ...StabilityOption) (models.StateStability, int, error)
+	Batch(batch models.BatchRequest) (models.BatchResponse, int, error)
+	GetDependencyGraph(rootType models.EntityType, rootId string) (models.DependencyGraph, int, error)
+	GetSettings() (models.OrganizationSettings, int, error)
+	GetStateMachine(entityType models.EntityType) (models.StateMachine, int, error)
+	GetStateHistory(entityType models.EntityType, id string) ([]models.StateTransitionRecord, int, error)
+	UpdateSettings(settings models.OrganizationSettings) (models.OrganizationSettings, int, error)
+	ListTrash() ([]models.TrashEntry, int, error)
+	GetAuditLog(filter models.AuditFilter) ([]models.AuditEntry, int, error)
+	ListServiceRevisions(serviceId string) ([]models.Revision, int, error)
+	RestoreServiceRevision(serviceId string, revision uint64) (models.Service, int, error)
+	ListPlanRevisions(serviceId, planId string) ([]models.Revision, int, error)
+	RestorePlanRevision(serviceId, planId string, revision uint64) (models.ServicePlan, int, error)
+	ListApplicationRevisions(applicationId string) ([]models.Revision, int, error)
+	RestoreApplicationRevision(applicationId string, revision uint64) (models.Application, int, error)
+	RestoreFromTrash(resource, id string) (int, error)
+	PurgeFromTrash(resource, id string) (int, error)
 }
 
 type TapCatalogApiConnector struct {
@@ -85,10 +126,23 @@ const (
 	images       = apiPrefix + apiVersion + "/images"
 	latestIndex  = apiPrefix + apiVersion + "/latest-index"
 	stableState  = apiPrefix + apiVersion + "/stable-state"
+	events       = apiPrefix + apiVersion + "/events"
+	batchOps     = apiPrefix + apiVersion + "/batch"
+	trash        = apiPrefix + apiVersion + "/trash"
+	graph        = apiPrefix + apiVersion + "/graph"
+	settings     = apiPrefix + apiVersion + "/settings"
+	stateMachine = apiPrefix + apiVersion + "/state-machines"
+	audit        = apiPrefix + apiVersion + "/audit"
+	restore      = "restore"
 	checkRefs    = "check-refs"
+	checkDeps    = "check-dependencies"
 	nextState    = "next-state"
+	nextChange   = "next-change"
 	healthz      = "healthz"
+	healthReady  = healthz + "/ready"
+	healthDetail = healthz + "/details"
 	bindings     = "bindings"
+	boundBy      = "bound-by"
 	plans        = "plans"
 
 	maxIdleConnectionPerHost = 100
@@ -131,6 +185,13 @@ func (c *TapCatalogApiConnector) getWatchApiConnector(url string) brokerHttp.Api
 	}
 }
 
+func (c *TapCatalogApiConnector) watchChanges(url string) (models.ChangeEvent, int, error) {
+	connector := c.getWatchApiConnector(url)
+	result := &models.ChangeEvent{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
 func (c *TapCatalogApiConnector) GetLatestIndex() (models.Index, int, error) {
 	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, latestIndex))
 	result := &models.Index{}
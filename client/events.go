@@ -0,0 +1,199 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+	commonLogger "github.com/trustedanalytics-ng/tap-go-common/logger"
+)
+
+const eventsReconnectDelay = 3 * time.Second
+
+var logger, _ = commonLogger.InitLogger("client")
+
+// eventsStreamError is sent by catalog as error event - stream should not be resumed after it
+type eventsStreamError struct {
+	message string
+}
+
+func (e eventsStreamError) Error() string {
+	return "events stream error: " + e.message
+}
+
+// WatchEvents consumes catalog events stream. Events are delivered in order and connection is resumed
+// from the last received event if it was interrupted. Both channels are closed when stop is closed
+// or when unrecoverable error occurs - in that case the error is sent to error channel first.
+func (c *TapCatalogApiConnector) WatchEvents(filter models.EventsFilter, afterIndex uint64, stop <-chan struct{}) (<-chan models.StateChangeEvent, <-chan error) {
+	events := make(chan models.StateChangeEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		lastIndex := afterIndex
+		for {
+			established, err := c.consumeEvents(filter, &lastIndex, stop, events)
+			if isStopped(stop) {
+				return
+			}
+
+			if _, isStreamError := err.(eventsStreamError); !established || isStreamError {
+				if err == nil {
+					err = errors.New("events stream closed unexpectedly")
+				}
+				errs <- err
+				return
+			}
+
+			logger.Infof("events stream interrupted (%v), reconnecting after index %d", err, lastIndex)
+			select {
+			case <-time.After(eventsReconnectDelay):
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return events, errs
+}
+
+func (c *TapCatalogApiConnector) consumeEvents(filter models.EventsFilter, lastIndex *uint64, stop <-chan struct{}, events chan<- models.StateChangeEvent) (bool, error) {
+	req, err := http.NewRequest("GET", c.buildEventsUrl(filter), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", brokerHttp.GetBasicAuthHeader(&brokerHttp.BasicAuth{User: c.Username, Password: c.Password}))
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastIndex > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(*lastIndex, 10))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := c.WatchClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return false, fmt.Errorf("Bad response status: %d, expected status was: %d. Response body: %s", resp.StatusCode, http.StatusOK, string(body))
+	}
+
+	err = readServerSentEvents(resp.Body, func(eventType, data string) error {
+		switch eventType {
+		case models.EventTypeState:
+			event := models.StateChangeEvent{}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return err
+			}
+			select {
+			case events <- event:
+				*lastIndex = event.Index
+			case <-stop:
+			}
+		case models.EventTypeError:
+			message := brokerHttp.MessageResponse{}
+			json.Unmarshal([]byte(data), &message)
+			return eventsStreamError{message: message.Message}
+		}
+		return nil
+	})
+	return true, err
+}
+
+func (c *TapCatalogApiConnector) buildEventsUrl(filter models.EventsFilter) string {
+	query := url.Values{}
+	if filter.Id != "" {
+		query.Set("id", filter.Id)
+	}
+	if len(filter.EntityTypes) > 0 {
+		entityTypes := []string{}
+		for _, entityType := range filter.EntityTypes {
+			entityTypes = append(entityTypes, string(entityType))
+		}
+		query.Set("entityType", strings.Join(entityTypes, ","))
+	}
+	return fmt.Sprintf("%s/%s?%s", c.Address, events, query.Encode())
+}
+
+// readServerSentEvents calls handler for every complete event read from stream; comments (e.g. heartbeats) are skipped
+func readServerSentEvents(body io.Reader, handler func(eventType, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	eventType := ""
+	data := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if err := handler(eventType, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+			eventType = ""
+			data = []string{}
+			continue
+		} else if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if separator := strings.Index(line, ":"); separator >= 0 {
+			field = line[:separator]
+			value = strings.TrimPrefix(line[separator+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
+
+func isStopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// GetDependencyGraph returns whole dependency graph or, if root id is given, only the part root depends on
+func (c *TapCatalogApiConnector) GetDependencyGraph(rootType models.EntityType, rootId string) (models.DependencyGraph, int, error) {
+	address := fmt.Sprintf("%s/%s", c.Address, graph)
+	if rootId != "" {
+		query := url.Values{}
+		query.Set("entityType", string(rootType))
+		query.Set("id", rootId)
+		address += "?" + query.Encode()
+	}
+
+	connector := c.getApiConnector(address)
+	result := models.DependencyGraph{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
@@ -16,6 +16,7 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -23,6 +24,8 @@ import (
 	"strconv"
 
 	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
 func (c *TapCatalogApiConnector) GetCatalogHealth() (int, error) {
@@ -33,3 +36,27 @@ func (c *TapCatalogApiConnector) GetCatalogHealth() (int, error) {
 	}
 	return status, err
 }
+
+// GetCatalogReadiness returns readiness also when catalog is not ready - error tells then what is missing
+func (c *TapCatalogApiConnector) GetCatalogReadiness() (models.Readiness, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, healthReady))
+	result := models.Readiness{}
+	status, body, err := brokerHttp.RestGET(connector.Url, brokerHttp.GetBasicAuthHeader(connector.BasicAuth), connector.Client)
+	if err != nil {
+		return result, status, err
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return result, status, fmt.Errorf("cannot unmarshal readiness: %v", err)
+	}
+	if status != http.StatusOK {
+		err = errors.New("Catalog is not ready: " + result.Message)
+	}
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) GetCatalogHealthDetails() (models.HealthDetails, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, healthDetail))
+	result := models.HealthDetails{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
@@ -0,0 +1,48 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const history = "history"
+
+var stateHistoryPaths = map[models.EntityType]string{
+	models.EntityTypeImage:    images,
+	models.EntityTypeInstance: instances,
+	models.EntityTypeService:  services,
+	models.EntityTypeTemplate: templates,
+}
+
+// GetStateHistory returns recorded state transitions of entity, the oldest first
+func (c *TapCatalogApiConnector) GetStateHistory(entityType models.EntityType, id string) ([]models.StateTransitionRecord, int, error) {
+	path, ok := stateHistoryPaths[entityType]
+	if !ok {
+		return nil, http.StatusBadRequest, fmt.Errorf("entity type %q has no state history", entityType)
+	}
+
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s", c.Address, path, id, history))
+	result := []models.StateTransitionRecord{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
@@ -76,3 +76,11 @@ func (c *TapCatalogApiConnector) WatchImage(imageId string, afterIndex uint64) (
 	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
 	return *result, status, err
 }
+
+func (c *TapCatalogApiConnector) WatchImagesChanges(afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, images, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchImageChanges(imageId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, images, imageId, nextChange, afterIndex))
+}
@@ -72,6 +72,26 @@ func (c *TapCatalogApiConnector) GetInstanceBindings(instanceId string) ([]model
 	return *result, status, err
 }
 
+func (c *TapCatalogApiConnector) GetInstanceBoundBy(instanceId string) ([]models.Instance, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s", c.Address, instances, instanceId, boundBy))
+	result := &[]models.Instance{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) AddInstanceBinding(instanceId string, binding models.InstanceBindings) (models.Instance, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s", c.Address, instances, instanceId, bindings))
+	result := &models.Instance{}
+	status, err := brokerHttp.PostModel(connector, binding, http.StatusCreated, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) DeleteInstanceBinding(instanceId, targetId string) (int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s/%s", c.Address, instances, instanceId, bindings, targetId))
+	status, err := brokerHttp.DeleteModel(connector, http.StatusNoContent)
+	return status, err
+}
+
 func (c *TapCatalogApiConnector) UpdateInstance(instanceId string, patches []models.Patch) (models.Instance, int, error) {
 	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s", c.Address, instances, instanceId))
 	result := &models.Instance{}
@@ -119,3 +139,11 @@ func (c *TapCatalogApiConnector) WatchInstance(instanceId string, afterIndex uin
 	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
 	return *result, status, err
 }
+
+func (c *TapCatalogApiConnector) WatchInstancesChanges(afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, instances, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchInstanceChanges(instanceId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, instances, instanceId, nextChange, afterIndex))
+}
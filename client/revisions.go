@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const revisions = "revisions"
+
+func (c *TapCatalogApiConnector) ListServiceRevisions(serviceId string) ([]models.Revision, int, error) {
+	return c.listRevisions(fmt.Sprintf("%s/%s/%s", c.Address, services, serviceId))
+}
+
+// RestoreServiceRevision replaces service with its revision (except state) and returns restored service
+func (c *TapCatalogApiConnector) RestoreServiceRevision(serviceId string, revision uint64) (models.Service, int, error) {
+	result := models.Service{}
+	status, err := c.restoreRevision(fmt.Sprintf("%s/%s/%s", c.Address, services, serviceId), revision, &result)
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) ListPlanRevisions(serviceId, planId string) ([]models.Revision, int, error) {
+	return c.listRevisions(fmt.Sprintf("%s/%s/%s/%s/%s", c.Address, services, serviceId, plans, planId))
+}
+
+func (c *TapCatalogApiConnector) RestorePlanRevision(serviceId, planId string, revision uint64) (models.ServicePlan, int, error) {
+	result := models.ServicePlan{}
+	status, err := c.restoreRevision(fmt.Sprintf("%s/%s/%s/%s/%s", c.Address, services, serviceId, plans, planId), revision, &result)
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) ListApplicationRevisions(applicationId string) ([]models.Revision, int, error) {
+	return c.listRevisions(fmt.Sprintf("%s/%s/%s", c.Address, applications, applicationId))
+}
+
+func (c *TapCatalogApiConnector) RestoreApplicationRevision(applicationId string, revision uint64) (models.Application, int, error) {
+	result := models.Application{}
+	status, err := c.restoreRevision(fmt.Sprintf("%s/%s/%s", c.Address, applications, applicationId), revision, &result)
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) listRevisions(objectAddress string) ([]models.Revision, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", objectAddress, revisions))
+	result := []models.Revision{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) restoreRevision(objectAddress string, revision uint64, result interface{}) (int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%d/%s", objectAddress, revisions, revision, restore))
+	return brokerHttp.PostModel(connector, nil, http.StatusOK, result)
+}
@@ -65,6 +65,13 @@ func (c *TapCatalogApiConnector) GetServicePlan(serviceId, planId string) (model
 	return result, status, err
 }
 
+func (c *TapCatalogApiConnector) CheckPlanDependencies() ([]models.PlanDependencyReport, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s", c.Address, services, plans, checkDeps))
+	result := []models.PlanDependencyReport{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
+
 func (c *TapCatalogApiConnector) DeleteService(serviceId string) (int, error) {
 	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s", c.Address, services, serviceId))
 	status, err := brokerHttp.DeleteModel(connector, http.StatusNoContent)
@@ -76,3 +83,36 @@ func (c *TapCatalogApiConnector) DeleteServicePlan(serviceId, planId string) (in
 	status, err := brokerHttp.DeleteModel(connector, http.StatusNoContent)
 	return status, err
 }
+
+func (c *TapCatalogApiConnector) WatchServices(afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, services, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchService(serviceId string, afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, services, serviceId, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchServicePlan(serviceId, planId string, afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s/%s/%s/%s?afterIndex=%d", c.Address, services, serviceId, plans, planId, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchServicesChanges(afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, services, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchServiceChanges(serviceId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, services, serviceId, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchServicePlanChanges(serviceId, planId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s/%s/%s?afterIndex=%d", c.Address, services, serviceId, plans, planId, nextChange, afterIndex))
+}
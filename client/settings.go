@@ -0,0 +1,39 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func (c *TapCatalogApiConnector) GetSettings() (models.OrganizationSettings, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, settings))
+	result := models.OrganizationSettings{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
+	return result, status, err
+}
+
+func (c *TapCatalogApiConnector) UpdateSettings(organizationSettings models.OrganizationSettings) (models.OrganizationSettings, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, settings))
+	result := models.OrganizationSettings{}
+	status, err := brokerHttp.PutModel(connector, organizationSettings, http.StatusOK, &result)
+	return result, status, err
+}
@@ -18,14 +18,86 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 
 	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
-func (c *TapCatalogApiConnector) CheckStateStability() (models.StateStability, int, error) {
-	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, stableState))
+type stabilityOptions struct {
+	filter models.StabilityFilter
+	wait   time.Duration
+}
+
+// StabilityOption narrows or prolongs CheckStateStability
+type StabilityOption func(*stabilityOptions)
+
+// WithStabilityEntityTypes limits check to given entity types - instances, images and templates are checked by default
+func WithStabilityEntityTypes(entityTypes ...models.EntityType) StabilityOption {
+	return func(options *stabilityOptions) {
+		options.filter.EntityTypes = append(options.filter.EntityTypes, entityTypes...)
+	}
+}
+
+// WithStabilityType limits check to instances and images of given type
+func WithStabilityType(entityClassType string) StabilityOption {
+	return func(options *stabilityOptions) {
+		options.filter.Type = entityClassType
+	}
+}
+
+// WithStabilityClassId limits check to instances of given class
+func WithStabilityClassId(classId string) StabilityOption {
+	return func(options *stabilityOptions) {
+		options.filter.ClassId = classId
+	}
+}
+
+// WithStabilityWait makes catalog respond once all checked entities are stable or given time passes
+func WithStabilityWait(wait time.Duration) StabilityOption {
+	return func(options *stabilityOptions) {
+		options.wait = wait
+	}
+}
+
+func (c *TapCatalogApiConnector) CheckStateStability(options ...StabilityOption) (models.StateStability, int, error) {
+	checkOptions := stabilityOptions{}
+	for _, option := range options {
+		option(&checkOptions)
+	}
+
+	query := url.Values{}
+	if len(checkOptions.filter.EntityTypes) > 0 {
+		entityTypes := []string{}
+		for _, entityType := range checkOptions.filter.EntityTypes {
+			entityTypes = append(entityTypes, string(entityType))
+		}
+		query.Set("entityType", strings.Join(entityTypes, ","))
+	}
+	if checkOptions.filter.Type != "" {
+		query.Set("type", checkOptions.filter.Type)
+	}
+	if checkOptions.filter.ClassId != "" {
+		query.Set("classId", checkOptions.filter.ClassId)
+	}
+
+	if checkOptions.wait > 0 {
+		query.Set("wait", checkOptions.wait.String())
+	}
+
+	address := fmt.Sprintf("%s/%s", c.Address, stableState)
+	if len(query) > 0 {
+		address = fmt.Sprintf("%s?%s", address, query.Encode())
+	}
+
+	// waiting for stability may take longer than timeout of regular client
+	connector := c.getApiConnector(address)
+	if checkOptions.wait > 0 {
+		connector = c.getWatchApiConnector(address)
+	}
 	result := models.StateStability{}
 	status, err := brokerHttp.GetModel(connector, http.StatusOK, &result)
 	return result, status, err
@@ -36,3 +36,25 @@ func (c *TapCatalogApiConnector) UpdateTemplate(templateId string, patches []mod
 	status, err := brokerHttp.PatchModel(connector, patches, http.StatusOK, result)
 	return *result, status, err
 }
+
+func (c *TapCatalogApiConnector) WatchTemplates(afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, templates, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchTemplate(templateId string, afterIndex uint64) (models.StateChange, int, error) {
+	connector := c.getWatchApiConnector(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, templates, templateId, nextState, afterIndex))
+	result := &models.StateChange{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+func (c *TapCatalogApiConnector) WatchTemplatesChanges(afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s?afterIndex=%d", c.Address, templates, nextChange, afterIndex))
+}
+
+func (c *TapCatalogApiConnector) WatchTemplateChanges(templateId string, afterIndex uint64) (models.ChangeEvent, int, error) {
+	return c.watchChanges(fmt.Sprintf("%s/%s/%s/%s?afterIndex=%d", c.Address, templates, templateId, nextChange, afterIndex))
+}
@@ -0,0 +1,44 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+	brokerHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+func (c *TapCatalogApiConnector) ListTrash() ([]models.TrashEntry, int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s", c.Address, trash))
+	result := &[]models.TrashEntry{}
+	status, err := brokerHttp.GetModel(connector, http.StatusOK, result)
+	return *result, status, err
+}
+
+// RestoreFromTrash recreates deleted object - resource is a collection name, e.g. services or images
+func (c *TapCatalogApiConnector) RestoreFromTrash(resource, id string) (int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s/%s", c.Address, trash, resource, id, restore))
+	result := &json.RawMessage{}
+	return brokerHttp.PostModel(connector, nil, http.StatusOK, result)
+}
+
+func (c *TapCatalogApiConnector) PurgeFromTrash(resource, id string) (int, error) {
+	connector := c.getApiConnector(fmt.Sprintf("%s/%s/%s/%s", c.Address, trash, resource, id))
+	return brokerHttp.DeleteModel(connector, http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// auditDayFormat names day directories of the audit log, so they are sorted in the same order as entries
+const auditDayFormat = "20060102"
+
+// AddAuditEntry appends entry to the day directory of the audit log directory (the key) - etcd removes it after
+// retention ttl. Entries are named by timestamp, so they are listed in order in which they were added.
+func (t *RepositoryConnector) AddAuditEntry(key string, entry models.AuditEntry, ttl time.Duration) error {
+	entryName := fmt.Sprintf("%020d-%s-%s", entry.Timestamp, entry.EntityType, entry.EntityId)
+	return t.etcdClient.CreateWithTTL(t.mapper.ToKey(t.buildAuditDayKey(key, entry.Timestamp), entryName), entry, ttl)
+}
+
+// GetAuditEntries returns entries of the audit log directory matching the filter, the oldest first. Only day
+// directories between Since and Until are read and reading stops when Limit entries are found.
+// Day directories emptied by etcd after retention ttl are removed on the way.
+func (t *RepositoryConnector) GetAuditEntries(key string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	today := path.Base(t.buildAuditDayKey(key, time.Now().UnixNano()))
+	result := []models.AuditEntry{}
+	for _, dayNode := range node.Nodes {
+		day := path.Base(dayNode.Key)
+		if !dayNode.Dir || !isAuditDayInRange(day, filter) {
+			continue
+		}
+
+		entryNodes, err := t.etcdClient.GetKeyNodes(dayNode.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(entryNodes.Nodes) == 0 && day < today {
+			if err := t.etcdClient.DeleteDir(dayNode.Key); err != nil {
+				logger.Warningf("cannot remove empty audit log directory %q: %v", dayNode.Key, err)
+			}
+			continue
+		}
+
+		for _, entryNode := range entryNodes.Nodes {
+			entry := models.AuditEntry{}
+			if err := json.Unmarshal([]byte(entryNode.Value), &entry); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal audit entry %q: %v", entryNode.Key, err)
+			}
+			if !filter.Matches(entry) {
+				continue
+			}
+			result = append(result, entry)
+			if filter.Limit > 0 && len(result) == filter.Limit {
+				return result, nil
+			}
+		}
+	}
+	return result, nil
+}
+
+func (t *RepositoryConnector) buildAuditDayKey(key string, timestamp int64) string {
+	return t.mapper.ToKey(key, time.Unix(0, timestamp).UTC().Format(auditDayFormat))
+}
+
+func isAuditDayInRange(day string, filter models.AuditFilter) bool {
+	if filter.Since != 0 && day < time.Unix(0, filter.Since).UTC().Format(auditDayFormat) {
+		return false
+	}
+	return filter.Until == 0 || day <= time.Unix(0, filter.Until).UTC().Format(auditDayFormat)
+}
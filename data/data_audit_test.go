@@ -0,0 +1,99 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/client"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestAddAuditEntry(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+
+	Convey("When audit entry is added, it should be stored in directory of its day", t, func() {
+		timestamp := time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC).UnixNano()
+		entry := models.AuditEntry{Timestamp: timestamp, EntityType: models.EntityTypeInstance, EntityId: "1"}
+		etcdClientMock.EXPECT().CreateWithTTL("/org/Audit/20170102/01483369445000000000-INSTANCE-1", entry, time.Hour).Return(nil)
+
+		So(repository.AddAuditEntry("/org/Audit", entry, time.Hour), ShouldBeNil)
+	})
+}
+
+func TestGetAuditEntries(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	auditKey := "/org/Audit"
+
+	entryNode := func(day string, timestamp int64) *client.Node {
+		entryBytes, _ := json.Marshal(models.AuditEntry{Timestamp: timestamp})
+		return &client.Node{Key: auditKey + "/" + day + "/entry", Value: string(entryBytes)}
+	}
+	first := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC).UnixNano()
+	second := time.Date(2017, 1, 2, 12, 0, 0, 0, time.UTC).UnixNano()
+	third := time.Date(2017, 1, 3, 12, 0, 0, 0, time.UTC).UnixNano()
+	days := client.Node{Nodes: client.Nodes{
+		{Key: auditKey + "/20170101", Dir: true},
+		{Key: auditKey + "/20170102", Dir: true},
+		{Key: auditKey + "/20170103", Dir: true},
+	}}
+
+	Convey("testing GetAuditEntries", t, func() {
+		Convey("When since is set, days before it should not be read", func() {
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey).Return(days, nil),
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey+"/20170102").Return(
+					client.Node{Nodes: client.Nodes{entryNode("20170102", second)}}, nil),
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey+"/20170103").Return(
+					client.Node{Nodes: client.Nodes{entryNode("20170103", third)}}, nil),
+			)
+
+			entries, err := repository.GetAuditEntries(auditKey, models.AuditFilter{Since: second})
+			So(err, ShouldBeNil)
+			So(entries, ShouldResemble, []models.AuditEntry{{Timestamp: second}, {Timestamp: third}})
+		})
+
+		Convey("When limit is reached, next days should not be read", func() {
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey).Return(days, nil),
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey+"/20170101").Return(
+					client.Node{Nodes: client.Nodes{entryNode("20170101", first)}}, nil),
+			)
+
+			entries, err := repository.GetAuditEntries(auditKey, models.AuditFilter{Limit: 1})
+			So(err, ShouldBeNil)
+			So(entries, ShouldResemble, []models.AuditEntry{{Timestamp: first}})
+		})
+
+		Convey("When past day directory is empty, it should be removed", func() {
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey).Return(days, nil),
+				etcdClientMock.EXPECT().GetKeyNodes(auditKey+"/20170101").Return(client.Node{}, nil),
+				etcdClientMock.EXPECT().DeleteDir(auditKey+"/20170101").Return(nil),
+			)
+
+			entries, err := repository.GetAuditEntries(auditKey, models.AuditFilter{Until: first})
+			So(err, ShouldBeNil)
+			So(entries, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,105 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const healthProbeTTL = time.Minute
+
+// openWatches counts etcd watchers which are currently waiting for changes
+var openWatches int64
+
+// trackWatch marks watch as open and returns function closing it
+func trackWatch() func() {
+	atomic.AddInt64(&openWatches, 1)
+	return func() {
+		atomic.AddInt64(&openWatches, -1)
+	}
+}
+
+func (t *RepositoryConnector) GetOpenWatchesCount() int64 {
+	return atomic.LoadInt64(&openWatches)
+}
+
+// GetMissingDirs returns directories of organization layout which do not exist. Error means etcd did not answer
+// within timeout.
+func (t *RepositoryConnector) GetMissingDirs(org string, timeout time.Duration) ([]string, error) {
+	missing := []string{}
+	for _, dir := range t.getLayoutDirs(org) {
+		exists, err := t.etcdClient.Exists(dir, timeout)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, dir)
+		}
+	}
+	return missing, nil
+}
+
+func (t *RepositoryConnector) GetClusterHealth(timeout time.Duration) ([]models.EtcdMemberHealth, error) {
+	members, err := t.etcdClient.GetMembersHealth(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.EtcdMemberHealth{}
+	for _, member := range members {
+		result = append(result, models.EtcdMemberHealth{
+			Id:         member.Id,
+			Name:       member.Name,
+			ClientURLs: member.ClientURLs,
+			Healthy:    member.Healthy,
+			Error:      member.Error,
+		})
+	}
+	return result, nil
+}
+
+// MeasureRoundTrip writes probe value under the key and reads it back, each of them failing if etcd does not answer
+// within timeout. Probe key expires after healthProbeTTL.
+func (t *RepositoryConnector) MeasureRoundTrip(key string, timeout time.Duration) (models.EtcdRoundTrip, error) {
+	result := models.EtcdRoundTrip{}
+	probe := time.Now().UnixNano()
+
+	start := time.Now()
+	if err := t.etcdClient.AddOrUpdateWithTimeout(key, probe, healthProbeTTL, timeout); err != nil {
+		return result, err
+	}
+	result.WriteMs = toMilliseconds(time.Since(start))
+
+	var read int64
+	start = time.Now()
+	if err := t.etcdClient.GetKeyIntoStructWithTimeout(key, &read, timeout); err != nil {
+		return result, err
+	}
+	result.ReadMs = toMilliseconds(time.Since(start))
+
+	if read != probe {
+		return result, fmt.Errorf("health probe %q read back %d instead of written %d", key, read, probe)
+	}
+	return result, nil
+}
+
+func toMilliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
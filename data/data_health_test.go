@@ -0,0 +1,79 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetMissingDirs(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+
+	Convey("testing GetMissingDirs", t, func() {
+		Convey("When some directories do not exist, they should be returned", func() {
+			etcdClientMock.EXPECT().Exists("org/Images", time.Second).Return(false, nil)
+			etcdClientMock.EXPECT().Exists(gomock.Any(), time.Second).Return(true, nil).Times(7)
+
+			missing, err := repository.GetMissingDirs("org", time.Second)
+			So(err, ShouldBeNil)
+			So(missing, ShouldResemble, []string{"org/Images"})
+		})
+
+		Convey("When etcd does not answer, error should be returned", func() {
+			etcdClientMock.EXPECT().Exists(gomock.Any(), time.Second).Return(false, errors.New("context deadline exceeded"))
+
+			_, err := repository.GetMissingDirs("org", time.Second)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMeasureRoundTrip(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	probeKey := "/org/HealthProbe/catalog-0"
+
+	Convey("testing MeasureRoundTrip", t, func() {
+		var written int64
+		etcdClientMock.EXPECT().AddOrUpdateWithTimeout(probeKey, gomock.Any(), healthProbeTTL, time.Second).Do(
+			func(key string, value interface{}, ttl, timeout time.Duration) {
+				written = value.(int64)
+			}).Return(nil)
+
+		Convey("When probe is read back, latency should be returned", func() {
+			etcdClientMock.EXPECT().GetKeyIntoStructWithTimeout(probeKey, gomock.Any(), time.Second).Do(
+				func(key string, result interface{}, timeout time.Duration) {
+					*result.(*int64) = written
+				}).Return(nil)
+
+			roundTrip, err := repository.MeasureRoundTrip(probeKey, time.Second)
+			So(err, ShouldBeNil)
+			So(roundTrip.WriteMs, ShouldBeGreaterThanOrEqualTo, 0)
+			So(roundTrip.ReadMs, ShouldBeGreaterThanOrEqualTo, 0)
+		})
+
+		Convey("When other value is read back, error should be returned", func() {
+			etcdClientMock.EXPECT().GetKeyIntoStructWithTimeout(probeKey, gomock.Any(), time.Second).Return(nil)
+
+			_, err := repository.MeasureRoundTrip(probeKey, time.Second)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
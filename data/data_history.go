@@ -0,0 +1,73 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coreos/etcd/client"
+
+	"github.com/trustedanalytics-ng/tap-catalog/etcd"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// AddStateHistoryRecord stores record in the history directory of entity (the key) and removes the oldest
+// records above the limit. Every record is separate key named by timestamp, so concurrent changes are not lost.
+func (t *RepositoryConnector) AddStateHistoryRecord(key string, record models.StateTransitionRecord, limit int) error {
+	if err := t.etcdClient.Create(t.mapper.ToKey(key, fmt.Sprintf("%020d", record.Timestamp)), record); err != nil {
+		return err
+	}
+
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return err
+	}
+
+	// nodes are sorted by key, so the oldest records are first
+	for i := 0; i < len(node.Nodes)-limit; i++ {
+		if err := t.etcdClient.Delete(node.Nodes[i].Key, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStateHistory returns records stored in the history directory of entity, the oldest first
+func (t *RepositoryConnector) GetStateHistory(key string) ([]models.StateTransitionRecord, error) {
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.StateTransitionRecord{}
+	for _, recordNode := range node.Nodes {
+		record := models.StateTransitionRecord{}
+		if err := json.Unmarshal([]byte(recordNode.Value), &record); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal state history record %q: %v", recordNode.Key, err)
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+// DeleteStateHistory removes history directory of entity (the key), entity without history is not an error
+func (t *RepositoryConnector) DeleteStateHistory(key string) error {
+	if err := t.etcdClient.DeleteDir(key); err != nil && !client.IsKeyNotFound(etcd.ClientError(err)) {
+		return err
+	}
+	return nil
+}
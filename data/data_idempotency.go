@@ -0,0 +1,42 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// CreateIdempotencyRecord fails if record for the key already exists, so only one of concurrent requests
+// with the same Idempotency-Key is processed. Record is removed by etcd after ttl.
+func (t *RepositoryConnector) CreateIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	return t.etcdClient.CreateWithTTL(key, record, ttl)
+}
+
+func (t *RepositoryConnector) SetIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	return t.etcdClient.AddOrUpdateWithTTL(key, record, ttl)
+}
+
+func (t *RepositoryConnector) GetIdempotencyRecord(key string) (models.IdempotencyRecord, error) {
+	record := models.IdempotencyRecord{}
+	err := t.etcdClient.GetKeyIntoStruct(key, &record)
+	return record, err
+}
+
+func (t *RepositoryConnector) DeleteIdempotencyRecord(key string) error {
+	return t.etcdClient.Delete(key, 0)
+}
@@ -0,0 +1,450 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// ToPatchesByJsonPatch applies RFC 6902 operations to JSON representation of the entity
+// and translates the result into patches accepted by ToKeyValueByPatches
+func (t *DataMapper) ToPatchesByJsonPatch(entity interface{}, operations []models.JsonPatchOperation, username string) ([]models.Patch, error) {
+	document, err := toJsonDocument(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, operation := range operations {
+		if document, err = applyJsonPatchOperation(document, operation); err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %s) failed: %v", i, operation.Op, operation.Path, err)
+		}
+	}
+	return toPatchesByDiff(entity, document, username)
+}
+
+// ToPatchesByMergePatch applies RFC 7396 merge patch to JSON representation of the entity
+// and translates the result into patches accepted by ToKeyValueByPatches
+func (t *DataMapper) ToPatchesByMergePatch(entity interface{}, mergePatch []byte, username string) ([]models.Patch, error) {
+	document, err := toJsonDocument(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := decodeJsonValue(mergePatch)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal merge patch: %v", err)
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return nil, errors.New("merge patch must match JSON object")
+	}
+	return toPatchesByDiff(entity, applyMergePatch(document, patch), username)
+}
+
+// ToPatchesByReplacement translates full replacement of the entity into patches - every field missing in replacement
+// is cleared, except Id and State which are taken from the entity (State can be changed only by explicit transition)
+func (t *DataMapper) ToPatchesByReplacement(entity interface{}, replacement []byte, username string) ([]models.Patch, error) {
+	document, err := decodeJsonValue(replacement)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal replacement: %v", err)
+	}
+
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("replacement must match JSON object")
+	}
+
+	original, err := toJsonDocument(entity)
+	if err != nil {
+		return nil, err
+	}
+	entityType := unwrapPointer(reflect.ValueOf(entity)).Type()
+	for _, fieldName := range []string{idFieldName, stateFieldName} {
+		if field, ok := entityType.FieldByName(fieldName); ok {
+			jsonName := getJsonFieldName(field)
+			if value, ok := object[jsonName]; !ok || value == nil || value == "" {
+				object[jsonName] = original.(map[string]interface{})[jsonName]
+			}
+		}
+	}
+	return toPatchesByDiff(entity, object, username)
+}
+
+// toPatchesByDiff compares top-level fields of the entity with patched document. Changed elements of collections
+// of structs are identified by Id and upserted with Add, removed ones are deleted - other fields are updated as a whole.
+// Changed elements holding nested subtrees (e.g. Dependencies of plan) are deleted before they are added again,
+// so nested values removed from the document are removed from etcd as well.
+// AuditTrail is maintained by catalog, so changes made to it are ignored.
+func toPatchesByDiff(entity interface{}, document interface{}, username string) ([]models.Patch, error) {
+	documentBytes, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	original := unwrapPointer(reflect.ValueOf(entity))
+	patched := reflect.New(original.Type())
+	if err := json.Unmarshal(documentBytes, patched.Interface()); err != nil {
+		return nil, err
+	}
+	patched = patched.Elem()
+
+	patches := []models.Patch{}
+	for i := 0; i < original.NumField(); i++ {
+		field := original.Type().Field(i)
+		if field.Name == auditTrailKey || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		originalField := original.Field(i)
+		patchedField := patched.Field(i)
+		if reflect.DeepEqual(originalField.Interface(), patchedField.Interface()) {
+			continue
+		}
+
+		if isCollection(field.Type.Kind()) && field.Type.Elem().Kind() == reflect.Struct {
+			collectionPatches, err := toCollectionPatches(field, originalField, patchedField, username)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, collectionPatches...)
+			continue
+		}
+
+		patch, err := newPatch(models.OperationUpdate, field.Name, patchedField.Interface(), username)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+func toCollectionPatches(field reflect.StructField, original, patched reflect.Value, username string) ([]models.Patch, error) {
+	if _, hasId := field.Type.Elem().FieldByName(idFieldName); !hasId {
+		return nil, fmt.Errorf("field %s can not be patched - its elements have no %s", field.Name, idFieldName)
+	}
+
+	originalById := map[string]reflect.Value{}
+	for i := 0; i < original.Len(); i++ {
+		originalById[getStructID(original.Index(i))] = original.Index(i)
+	}
+
+	patches := []models.Patch{}
+	patchedIds := map[string]bool{}
+	for i := 0; i < patched.Len(); i++ {
+		element := patched.Index(i)
+		id := getStructID(element)
+		if id != "" {
+			if patchedIds[id] {
+				return nil, fmt.Errorf("field %s contains duplicated element id %q", field.Name, id)
+			}
+			patchedIds[id] = true
+		}
+
+		if originalElement, ok := originalById[id]; ok && id != "" {
+			if reflect.DeepEqual(originalElement.Interface(), element.Interface()) {
+				continue
+			}
+			if hasNestedFields(element.Type()) {
+				patch, err := newPatch(models.OperationDelete, field.Name, originalElement.Interface(), username)
+				if err != nil {
+					return nil, err
+				}
+				patches = append(patches, patch)
+			}
+		}
+		patch, err := newPatch(models.OperationAdd, field.Name, element.Interface(), username)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+
+	for i := 0; i < original.Len(); i++ {
+		element := original.Index(i)
+		if patchedIds[getStructID(element)] {
+			continue
+		}
+		patch, err := newPatch(models.OperationDelete, field.Name, element.Interface(), username)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// hasNestedFields tells if struct is stored with subdirectories - Add of such element does not remove stale nested keys
+func hasNestedFields(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		switch structType.Field(i).Type.Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr:
+			return true
+		}
+	}
+	return false
+}
+
+func newPatch(operation models.PatchOperation, fieldName string, value interface{}, username string) (models.Patch, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return models.Patch{}, err
+	}
+
+	rawValue := json.RawMessage(valueBytes)
+	return models.Patch{Operation: operation, Field: &fieldName, Value: &rawValue, Username: username}, nil
+}
+
+func applyJsonPatchOperation(document interface{}, operation models.JsonPatchOperation) (interface{}, error) {
+	path, err := parseJsonPointer(operation.Path)
+	if err != nil {
+		return document, err
+	}
+
+	switch operation.Op {
+	case models.JsonPatchAdd, models.JsonPatchReplace, models.JsonPatchTest:
+		if operation.Value == nil {
+			return document, errors.New("value is required")
+		}
+		value, err := decodeJsonValue(*operation.Value)
+		if err != nil {
+			return document, err
+		}
+
+		if operation.Op == models.JsonPatchAdd {
+			return setJsonValue(document, path, value, true)
+		}
+
+		current, err := getJsonValue(document, path)
+		if err != nil {
+			return document, err
+		}
+		if operation.Op == models.JsonPatchReplace {
+			return setJsonValue(document, path, value, false)
+		}
+		if !reflect.DeepEqual(current, value) {
+			return document, fmt.Errorf("value at %q must match tested value", operation.Path)
+		}
+		return document, nil
+	case models.JsonPatchRemove:
+		return removeJsonValue(document, path)
+	case models.JsonPatchMove, models.JsonPatchCopy:
+		from, err := parseJsonPointer(operation.From)
+		if err != nil {
+			return document, err
+		}
+		value, err := getJsonValue(document, from)
+		if err != nil {
+			return document, err
+		}
+
+		if operation.Op == models.JsonPatchMove {
+			if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+				return document, errors.New("value can not be moved into its own child")
+			}
+			if document, err = removeJsonValue(document, from); err != nil {
+				return document, err
+			}
+		} else if value, err = copyJsonValue(value); err != nil {
+			return document, err
+		}
+		return setJsonValue(document, path, value, true)
+	}
+	return document, fmt.Errorf("op %q must match one of: %v", operation.Op, []models.JsonPatchOperationType{
+		models.JsonPatchAdd, models.JsonPatchRemove, models.JsonPatchReplace, models.JsonPatchMove, models.JsonPatchCopy, models.JsonPatchTest,
+	})
+}
+
+// applyMergePatch merges patch into target according to RFC 7396 - null removes member, objects are merged recursively,
+// any other value replaces the target
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = applyMergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+func parseJsonPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must match JSON pointer starting with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func getJsonValue(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			node = value
+		case []interface{}:
+			index, err := getJsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+	}
+	return node, nil
+}
+
+// setJsonValue adds (insert = true) or replaces value under path and returns modified node
+func setJsonValue(node interface{}, path []string, value interface{}, insert bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return node, fmt.Errorf("member %q not found", token)
+		}
+		child, err := setJsonValue(child, path[1:], value, insert)
+		container[token] = child
+		return container, err
+	case []interface{}:
+		if len(path) == 1 && insert {
+			index := len(container)
+			if token != "-" {
+				var err error
+				if index, err = getJsonArrayIndex(token, len(container)); err != nil {
+					return node, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+
+		index, err := getJsonArrayIndex(token, len(container)-1)
+		if err != nil {
+			return node, err
+		}
+		if len(path) == 1 {
+			container[index] = value
+			return container, nil
+		}
+		container[index], err = setJsonValue(container[index], path[1:], value, insert)
+		return container, err
+	}
+	return node, fmt.Errorf("member %q not found", token)
+}
+
+func removeJsonValue(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return node, errors.New("whole document can not be removed")
+	}
+
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return node, fmt.Errorf("member %q not found", token)
+		}
+		if len(path) == 1 {
+			delete(container, token)
+			return container, nil
+		}
+		child, err := removeJsonValue(child, path[1:])
+		container[token] = child
+		return container, err
+	case []interface{}:
+		index, err := getJsonArrayIndex(token, len(container)-1)
+		if err != nil {
+			return node, err
+		}
+		if len(path) == 1 {
+			return append(container[:index], container[index+1:]...), nil
+		}
+		container[index], err = removeJsonValue(container[index], path[1:])
+		return container, err
+	}
+	return node, fmt.Errorf("member %q not found", token)
+}
+
+func getJsonArrayIndex(token string, maxIndex int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("array index %q must match number from 0 to %d", token, maxIndex)
+	}
+	return index, nil
+}
+
+func toJsonDocument(entity interface{}) (interface{}, error) {
+	entityBytes, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJsonValue(entityBytes)
+}
+
+func copyJsonValue(value interface{}) (interface{}, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJsonValue(valueBytes)
+}
+
+// decodeJsonValue keeps numbers as json.Number, so they are not changed by the patch round trip
+func decodeJsonValue(value []byte) (interface{}, error) {
+	var result interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	err := decoder.Decode(&result)
+	return result, err
+}
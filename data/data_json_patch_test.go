@@ -0,0 +1,185 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestToPatchesByJsonPatch(t *testing.T) {
+	mapper := DataMapper{}
+	instance := models.Instance{
+		Id:       "1",
+		Name:     "name",
+		State:    models.InstanceStateRunning,
+		Metadata: []models.Metadata{{Id: "a", Value: "1"}, {Id: "b", Value: "2"}},
+	}
+
+	Convey("Testing ToPatchesByJsonPatch", t, func() {
+		Convey("replace of simple field should be translated into Update", func() {
+			patches, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchReplace, Path: "/state", Value: rawJson(`"STOPPED"`)},
+			}, "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 1)
+			So(patches[0].Operation, ShouldEqual, models.OperationUpdate)
+			So(*patches[0].Field, ShouldEqual, "State")
+			So(string(*patches[0].Value), ShouldEqual, `"STOPPED"`)
+			So(patches[0].Username, ShouldEqual, "user")
+		})
+
+		Convey("change of single collection element should be translated into Add of this element", func() {
+			patches, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchReplace, Path: "/metadata/1/value", Value: rawJson(`"3"`)},
+			}, "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 1)
+			So(patches[0].Operation, ShouldEqual, models.OperationAdd)
+			So(string(*patches[0].Value), ShouldEqual, `{"key":"b","value":"3"}`)
+		})
+
+		Convey("removed collection element should be translated into Delete", func() {
+			patches, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchRemove, Path: "/metadata/0"},
+			}, "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 1)
+			So(patches[0].Operation, ShouldEqual, models.OperationDelete)
+			So(string(*patches[0].Value), ShouldEqual, `{"key":"a","value":"1"}`)
+		})
+
+		Convey("add of null value should clear the field", func() {
+			patches, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchAdd, Path: "/name", Value: rawJson(`null`)},
+			}, "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 1)
+			So(patches[0].Operation, ShouldEqual, models.OperationUpdate)
+			So(*patches[0].Field, ShouldEqual, "Name")
+			So(string(*patches[0].Value), ShouldEqual, `""`)
+		})
+
+		Convey("missing value should return error", func() {
+			_, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchAdd, Path: "/name"},
+			}, "user")
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "value is required")
+		})
+
+		Convey("failed test operation should return error", func() {
+			_, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchTest, Path: "/name", Value: rawJson(`"other"`)},
+			}, "user")
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("not existing path should return error", func() {
+			_, err := mapper.ToPatchesByJsonPatch(instance, []models.JsonPatchOperation{
+				{Op: models.JsonPatchReplace, Path: "/metadata/5/value", Value: rawJson(`"3"`)},
+			}, "user")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestToPatchesByJsonPatchOfNestedCollection(t *testing.T) {
+	mapper := DataMapper{}
+	service := models.Service{Id: "1", Plans: []models.ServicePlan{{
+		Id:           "plan",
+		Dependencies: []models.ServiceDependency{{Id: "a", ServiceId: "2", PlanId: "p2"}},
+	}}}
+
+	Convey("removed nested element should be translated into Delete of changed element followed by its Add", t, func() {
+		patches, err := mapper.ToPatchesByJsonPatch(service, []models.JsonPatchOperation{
+			{Op: models.JsonPatchRemove, Path: "/plans/0/dependencies/0"},
+		}, "user")
+
+		So(err, ShouldBeNil)
+		So(patches, ShouldHaveLength, 2)
+		So(patches[0].Operation, ShouldEqual, models.OperationDelete)
+		So(patches[1].Operation, ShouldEqual, models.OperationAdd)
+		So(string(*patches[1].Value), ShouldNotContainSubstring, `"p2"`)
+	})
+}
+
+func TestToPatchesByMergePatch(t *testing.T) {
+	mapper := DataMapper{}
+	service := models.Service{Id: "1", Name: "name", Description: "old", State: models.ServiceStateReady}
+
+	Convey("Testing ToPatchesByMergePatch", t, func() {
+		Convey("merged members should be translated into Update", func() {
+			patches, err := mapper.ToPatchesByMergePatch(service, []byte(`{"description":"new"}`), "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 1)
+			So(*patches[0].Field, ShouldEqual, "Description")
+			So(string(*patches[0].Value), ShouldEqual, `"new"`)
+		})
+
+		Convey("merge patch which is not an object should return error", func() {
+			_, err := mapper.ToPatchesByMergePatch(service, []byte(`["description"]`), "user")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestToPatchesByReplacement(t *testing.T) {
+	mapper := DataMapper{}
+	instance := models.Instance{
+		Id:       "1",
+		Name:     "name",
+		State:    models.InstanceStateRunning,
+		Metadata: []models.Metadata{{Id: "a", Value: "1"}},
+	}
+
+	Convey("Testing ToPatchesByReplacement", t, func() {
+		Convey("missing id and state should be kept and missing collection members removed", func() {
+			patches, err := mapper.ToPatchesByReplacement(instance, []byte(`{"name":"name","metadata":[{"key":"b","value":"2"}]}`), "user")
+
+			So(err, ShouldBeNil)
+			So(patches, ShouldHaveLength, 2)
+			So(patches[0].Operation, ShouldEqual, models.OperationAdd)
+			So(string(*patches[0].Value), ShouldEqual, `{"key":"b","value":"2"}`)
+			So(patches[1].Operation, ShouldEqual, models.OperationDelete)
+			So(string(*patches[1].Value), ShouldEqual, `{"key":"a","value":"1"}`)
+		})
+
+		Convey("replacement which is not an object should return error", func() {
+			_, err := mapper.ToPatchesByReplacement(instance, []byte(`"name"`), "user")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func rawJson(value string) *json.RawMessage {
+	raw := json.RawMessage(value)
+	return &raw
+}
@@ -213,6 +213,33 @@ func validatePatch(patchFieldName string, patch models.Patch, isUpdateOp bool) e
 	return nil
 }
 
+// ValidateEnumPatches checks patches setting fields which accept only values declared by their type
+func (t *DataMapper) ValidateEnumPatches(entity interface{}, patches []models.Patch) error {
+	entityType := unwrapPointer(reflect.ValueOf(entity)).Type()
+	for _, patch := range patches {
+		if patch.Field == nil || patch.Value == nil || patch.Operation == models.OperationDelete {
+			continue
+		}
+
+		field, ok := entityType.FieldByName(strings.Title(*patch.Field))
+		if !ok {
+			continue
+		}
+		value := reflect.New(field.Type)
+		if _, ok := value.Elem().Interface().(models.Enum); !ok {
+			continue
+		}
+
+		if err := json.Unmarshal(*patch.Value, value.Interface()); err != nil {
+			return err
+		}
+		if err := models.ValidateEnum(getJsonFieldName(field), value.Elem().Interface().(models.Enum)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *DataMapper) ToKey(prefix string, key string) string {
 	return prefix + keySeparator + key
 }
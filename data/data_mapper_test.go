@@ -49,14 +49,46 @@ func TestToKeyValueByPatches(t *testing.T) {
 	})
 	Convey("Given proper request", t, func() {
 		patches := []models.Patch{}
-		json.Unmarshal([]byte(`[{"field":"State", "value":"newTest", "op": "Update"}]`), &patches)
+		json.Unmarshal([]byte(`[{"field":"State", "value":"READY", "op": "Update"}]`), &patches)
 
 		patchedKeys, err := c.ToKeyValueByPatches("Test/State", models.Template{}, patches)
 		Convey("Should not return error", func() {
 			So(err, ShouldEqual, nil)
 		})
 		Convey("Should return updated value", func() {
-			So(patchedKeys.Update[0].Value, ShouldEqual, "newTest")
+			So(patchedKeys.Update[0].Value, ShouldEqual, models.TemplateStateReady)
+		})
+	})
+}
+
+func TestValidateEnumPatches(t *testing.T) {
+	mapper := DataMapper{}
+	instance := models.Instance{Id: "1", Type: models.InstanceTypeApplication, State: models.InstanceStateRunning}
+
+	Convey("Testing ValidateEnumPatches", t, func() {
+		Convey("declared value should be accepted", func() {
+			patch, _ := newPatch(models.OperationUpdate, "state", models.InstanceStateStopped, "user")
+			So(mapper.ValidateEnumPatches(instance, []models.Patch{patch}), ShouldBeNil)
+		})
+
+		Convey("unknown value should be rejected with list of allowed ones", func() {
+			patch, _ := newPatch(models.OperationUpdate, "type", "FOO", "user")
+
+			err := mapper.ValidateEnumPatches(instance, []models.Patch{patch})
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "must match one of")
+			So(err.Error(), ShouldContainSubstring, string(models.InstanceTypeServiceBroker))
+		})
+
+		Convey("empty value should be rejected", func() {
+			patch, _ := newPatch(models.OperationUpdate, "state", "", "user")
+			So(mapper.ValidateEnumPatches(instance, []models.Patch{patch}), ShouldNotBeNil)
+		})
+
+		Convey("fields which are not enums should be skipped", func() {
+			patch, _ := newPatch(models.OperationUpdate, "name", "", "user")
+			So(mapper.ValidateEnumPatches(instance, []models.Patch{patch}), ShouldBeNil)
 		})
 	})
 }
@@ -0,0 +1,110 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// PreviewCreate returns copy of the entity as it would be stored by CreateData of ToKeyValue result
+func (t *DataMapper) PreviewCreate(entity interface{}) interface{} {
+	preview := copyEntity(entity)
+	if auditTrail := preview.FieldByName(auditTrailKey); auditTrail.IsValid() {
+		now := time.Now().Unix()
+		auditTrail.FieldByName("CreatedOn").SetInt(now)
+		auditTrail.FieldByName("LastUpdatedOn").SetInt(now)
+	}
+	return preview.Interface()
+}
+
+// PreviewPatches returns copy of the entity with patches applied - it is what ApplyPatchedValues
+// of ToKeyValueByPatches result would store, so patches have to be validated by ToKeyValueByPatches first
+func (t *DataMapper) PreviewPatches(entity interface{}, patches []models.Patch) (interface{}, error) {
+	preview := copyEntity(entity)
+
+	username := ""
+	for _, patch := range patches {
+		if err := models.ValidatePatchStructure(patch); err != nil {
+			return nil, err
+		}
+		username = patch.Username
+
+		fieldName := strings.Title(*patch.Field)
+		field := preview.FieldByName(fieldName)
+		if !field.IsValid() {
+			return nil, errors.New("Original field not found: " + fieldName)
+		}
+
+		newValue, err := unmarshalJSON(*patch.Value, fieldName, field.Type())
+		if err != nil {
+			return nil, err
+		}
+		received := reflect.ValueOf(newValue).Elem()
+
+		switch {
+		case patch.Operation == models.OperationUpdate && received.Type().AssignableTo(field.Type()):
+			field.Set(received)
+		case isCollection(field.Kind()) && received.Type() == field.Type().Elem():
+			field.Set(previewCollectionPatch(field, received, patch.Operation == models.OperationDelete))
+		default:
+			return nil, fmt.Errorf("%s operation can not be applied to field %s", patch.Operation, fieldName)
+		}
+	}
+
+	if auditTrail := preview.FieldByName(auditTrailKey); auditTrail.IsValid() {
+		auditTrail.FieldByName("LastUpdatedOn").SetInt(time.Now().Unix())
+		if username != "" {
+			auditTrail.FieldByName("LastUpdateBy").SetString(username)
+		}
+	}
+	return preview.Interface(), nil
+}
+
+// previewCollectionPatch builds new collection, so that collection of the original entity is not modified:
+// element with the same Id is replaced (or removed) in place, element with new Id is appended
+func previewCollectionPatch(collection, element reflect.Value, remove bool) reflect.Value {
+	id := getStructID(element)
+	result := reflect.MakeSlice(collection.Type(), 0, collection.Len()+1)
+	found := false
+	for i := 0; i < collection.Len(); i++ {
+		if id != "" && getStructID(collection.Index(i)) == id {
+			found = true
+			if !remove {
+				result = reflect.Append(result, element)
+			}
+			continue
+		}
+		result = reflect.Append(result, collection.Index(i))
+	}
+
+	if !found && !remove {
+		result = reflect.Append(result, element)
+	}
+	return result
+}
+
+func copyEntity(entity interface{}) reflect.Value {
+	original := unwrapPointer(reflect.ValueOf(entity))
+	entityCopy := reflect.New(original.Type()).Elem()
+	entityCopy.Set(original)
+	return entityCopy
+}
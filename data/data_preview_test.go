@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestPreviewPatches(t *testing.T) {
+	mapper := DataMapper{}
+	instance := models.Instance{
+		Id:       "1",
+		Name:     "name",
+		State:    models.InstanceStateRunning,
+		Metadata: []models.Metadata{{Id: "a", Value: "1"}, {Id: "b", Value: "2"}},
+	}
+
+	Convey("Testing PreviewPatches", t, func() {
+		Convey("Update should replace field value and set last updater", func() {
+			patch, _ := newPatch(models.OperationUpdate, "State", models.InstanceStateStopped, "user")
+
+			preview, err := mapper.PreviewPatches(instance, []models.Patch{patch})
+
+			So(err, ShouldBeNil)
+			So(preview.(models.Instance).State, ShouldEqual, models.InstanceStateStopped)
+			So(preview.(models.Instance).AuditTrail.LastUpdateBy, ShouldEqual, "user")
+		})
+
+		Convey("Add should replace collection element with the same id and append new one", func() {
+			changed, _ := newPatch(models.OperationAdd, "Metadata", models.Metadata{Id: "b", Value: "3"}, "user")
+			added, _ := newPatch(models.OperationAdd, "Metadata", models.Metadata{Id: "c", Value: "4"}, "user")
+
+			preview, err := mapper.PreviewPatches(instance, []models.Patch{changed, added})
+
+			So(err, ShouldBeNil)
+			So(preview.(models.Instance).Metadata, ShouldResemble, []models.Metadata{{Id: "a", Value: "1"}, {Id: "b", Value: "3"}, {Id: "c", Value: "4"}})
+		})
+
+		Convey("Delete should remove collection element and keep original entity untouched", func() {
+			patch, _ := newPatch(models.OperationDelete, "Metadata", models.Metadata{Id: "a"}, "user")
+
+			preview, err := mapper.PreviewPatches(instance, []models.Patch{patch})
+
+			So(err, ShouldBeNil)
+			So(preview.(models.Instance).Metadata, ShouldResemble, []models.Metadata{{Id: "b", Value: "2"}})
+			So(instance.Metadata, ShouldHaveLength, 2)
+		})
+
+		Convey("not existing field should return error", func() {
+			patch, _ := newPatch(models.OperationUpdate, "Unknown", "value", "user")
+
+			_, err := mapper.PreviewPatches(instance, []models.Patch{patch})
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,171 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/coreos/etcd/client"
+)
+
+// FieldProjection describes which top-level fields of a model should be read and returned.
+// A nil projection selects all fields.
+type FieldProjection struct {
+	selected  map[string]bool
+	jsonNames map[string]string
+}
+
+// NewFieldProjection builds projection for model from lists of field names (json or struct names, case insensitive).
+// If fields is empty all fields are taken as base, then fields listed in exclude are removed.
+// Nil is returned when both lists are empty.
+func NewFieldProjection(model interface{}, fields, exclude []string) (*FieldProjection, error) {
+	if len(fields) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	modelType := unwrapPointer(reflect.ValueOf(model)).Type()
+	if modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("field projection is not supported for type %v", modelType)
+	}
+
+	projection := &FieldProjection{
+		selected:  make(map[string]bool),
+		jsonNames: make(map[string]string),
+	}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		projection.jsonNames[field.Name] = getJsonFieldName(field)
+		projection.selected[field.Name] = len(fields) == 0
+	}
+
+	for _, name := range fields {
+		fieldName, err := projection.resolveFieldName(name)
+		if err != nil {
+			return nil, err
+		}
+		projection.selected[fieldName] = true
+	}
+
+	for _, name := range exclude {
+		fieldName, err := projection.resolveFieldName(name)
+		if err != nil {
+			return nil, err
+		}
+		projection.selected[fieldName] = false
+	}
+	return projection, nil
+}
+
+func (p *FieldProjection) resolveFieldName(name string) (string, error) {
+	allowed := []string{}
+	for fieldName, jsonName := range p.jsonNames {
+		if strings.EqualFold(name, fieldName) || strings.EqualFold(name, jsonName) {
+			return fieldName, nil
+		}
+		allowed = append(allowed, jsonName)
+	}
+	sort.Strings(allowed)
+	return "", fmt.Errorf("unknown field %q, field name must match one of: %s", name, strings.Join(allowed, ", "))
+}
+
+// WithFields returns copy of projection which selects also given fields (struct names), e.g. fields needed
+// to filter read objects before the original projection is applied to them
+func (p *FieldProjection) WithFields(fieldNames ...string) *FieldProjection {
+	if p == nil {
+		return nil
+	}
+
+	result := &FieldProjection{
+		selected:  make(map[string]bool),
+		jsonNames: p.jsonNames,
+	}
+	for fieldName, selected := range p.selected {
+		result.selected[fieldName] = selected
+	}
+	for _, fieldName := range fieldNames {
+		result.selected[fieldName] = true
+	}
+	return result
+}
+
+func (p *FieldProjection) IsSelected(fieldName string) bool {
+	if p == nil {
+		return true
+	}
+	return p.selected[fieldName]
+}
+
+// Apply converts entity (or slice of entities) into maps holding only selected fields, keyed by their json names
+func (p *FieldProjection) Apply(entity interface{}) interface{} {
+	if p == nil {
+		return entity
+	}
+
+	value := unwrapPointer(reflect.ValueOf(entity))
+	if isCollection(value.Kind()) {
+		result := []map[string]interface{}{}
+		for i := 0; i < value.Len(); i++ {
+			result = append(result, p.structToProjectedMap(value.Index(i)))
+		}
+		return result
+	} else if value.Kind() != reflect.Struct {
+		return entity
+	}
+	return p.structToProjectedMap(value)
+}
+
+func (p *FieldProjection) structToProjectedMap(structObject reflect.Value) map[string]interface{} {
+	// elements of []interface{} returned by repository have to be unwrapped first
+	if structObject.Kind() == reflect.Interface {
+		structObject = structObject.Elem()
+	}
+	structObject = unwrapPointer(structObject)
+
+	result := map[string]interface{}{}
+	for i := 0; i < structObject.NumField(); i++ {
+		fieldName := structObject.Type().Field(i).Name
+		if p.IsSelected(fieldName) {
+			result[getJsonFieldName(structObject.Type().Field(i))] = structObject.Field(i).Interface()
+		}
+	}
+	return result
+}
+
+// filterNodes removes nodes of not selected fields so they are not parsed into model at all
+func (p *FieldProjection) filterNodes(nodes client.Nodes) client.Nodes {
+	if p == nil {
+		return nodes
+	}
+
+	result := client.Nodes{}
+	for _, node := range nodes {
+		if p.IsSelected(getNodeName(node.Key)) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func getJsonFieldName(field reflect.StructField) string {
+	jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+	if jsonName == "" || jsonName == "-" {
+		return field.Name
+	}
+	return jsonName
+}
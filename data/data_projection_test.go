@@ -0,0 +1,129 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"testing"
+
+	"github.com/coreos/etcd/client"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestNewFieldProjection(t *testing.T) {
+	Convey("Testing NewFieldProjection", t, func() {
+		Convey("should return nil projection when no fields are provided", func() {
+			projection, err := NewFieldProjection(models.Instance{}, []string{}, []string{})
+			So(err, ShouldBeNil)
+			So(projection, ShouldBeNil)
+			So(projection.IsSelected("Bindings"), ShouldBeTrue)
+		})
+
+		Convey("should select only provided fields by json or struct name", func() {
+			projection, err := NewFieldProjection(models.Instance{}, []string{"id", "Name", "STATE"}, []string{})
+			So(err, ShouldBeNil)
+			So(projection.IsSelected("Id"), ShouldBeTrue)
+			So(projection.IsSelected("Name"), ShouldBeTrue)
+			So(projection.IsSelected("State"), ShouldBeTrue)
+			So(projection.IsSelected("Bindings"), ShouldBeFalse)
+		})
+
+		Convey("should select all but excluded fields", func() {
+			projection, err := NewFieldProjection(models.Instance{}, []string{}, []string{"bindings", "metadata"})
+			So(err, ShouldBeNil)
+			So(projection.IsSelected("Id"), ShouldBeTrue)
+			So(projection.IsSelected("Bindings"), ShouldBeFalse)
+			So(projection.IsSelected("Metadata"), ShouldBeFalse)
+		})
+
+		Convey("should return error for unknown field", func() {
+			_, err := NewFieldProjection(models.Instance{}, []string{"unknown"}, []string{})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "must match one of")
+		})
+	})
+}
+
+func TestFieldProjectionApply(t *testing.T) {
+	Convey("Testing FieldProjection Apply", t, func() {
+		projection, _ := NewFieldProjection(models.Template{}, []string{"templateId"}, []string{})
+
+		Convey("single entity should be trimmed to selected json fields", func() {
+			result := projection.Apply(models.Template{Id: "1", State: models.TemplateStateReady})
+			So(result, ShouldResemble, map[string]interface{}{"templateId": "1"})
+		})
+
+		Convey("list of entities should be trimmed to selected json fields", func() {
+			result := projection.Apply([]interface{}{models.Template{Id: "1"}, models.Template{Id: "2"}})
+			So(result, ShouldResemble, []map[string]interface{}{{"templateId": "1"}, {"templateId": "2"}})
+		})
+	})
+}
+
+func TestGetDataWithProjection(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+
+	Convey("GetDataWithProjection should not read subtrees of not selected fields", t, func() {
+		const instanceKey = "/org/Instances/1"
+		projection, _ := NewFieldProjection(models.Instance{}, []string{"name", "metadata"}, []string{})
+
+		etcdClientMock.EXPECT().GetKeyNodes(instanceKey).Return(client.Node{Key: instanceKey, Dir: true, Nodes: client.Nodes{
+			{Key: instanceKey + "/Name", Value: `"name"`},
+			{Key: instanceKey + "/State", Value: `"RUNNING"`},
+			{Key: instanceKey + "/Bindings", Dir: true},
+			{Key: instanceKey + "/Metadata", Dir: true},
+		}}, nil)
+		etcdClientMock.EXPECT().GetKeyNodesRecursively(instanceKey+"/Metadata").Return(client.Node{Key: instanceKey + "/Metadata", Dir: true}, nil)
+
+		result, err := repository.GetDataWithProjection(instanceKey, models.Instance{}, projection)
+
+		So(err, ShouldBeNil)
+		So(result.(models.Instance).Name, ShouldEqual, "name")
+		So(result.(models.Instance).State, ShouldEqual, "")
+	})
+}
+
+func TestGetListOfDataWithProjection(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+
+	Convey("GetListOfDataWithProjection should read directory once and drop excluded fields", t, func() {
+		const instancesKey = "/org/Instances"
+		projection, _ := NewFieldProjection(models.Instance{}, []string{}, []string{"bindings", "metadata"})
+
+		instanceNodes := client.Nodes{}
+		for _, id := range []string{"1", "2"} {
+			instanceKey := instancesKey + "/" + id
+			instanceNodes = append(instanceNodes, &client.Node{Key: instanceKey, Dir: true, Nodes: client.Nodes{
+				{Key: instanceKey + "/Id", Value: `"` + id + `"`},
+				{Key: instanceKey + "/Bindings", Dir: true, Nodes: client.Nodes{
+					{Key: instanceKey + "/Bindings/b1", Dir: true, Nodes: client.Nodes{{Key: instanceKey + "/Bindings/b1/Id", Value: `"b1"`}}},
+				}},
+				{Key: instanceKey + "/Metadata", Dir: true},
+			}})
+		}
+		etcdClientMock.EXPECT().GetKeyNodesRecursively(instancesKey).Return(client.Node{Key: instancesKey, Dir: true, Nodes: instanceNodes}, nil)
+
+		result, err := repository.GetListOfDataWithProjection(instancesKey, models.Instance{}, projection)
+
+		So(err, ShouldBeNil)
+		So(result, ShouldHaveLength, 2)
+		So(result[0].(models.Instance).Id, ShouldEqual, "1")
+		So(result[0].(models.Instance).Bindings, ShouldBeEmpty)
+		So(result[1].(models.Instance).Id, ShouldEqual, "2")
+		So(result[1].(models.Instance).Bindings, ShouldBeEmpty)
+	})
+}
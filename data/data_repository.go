@@ -19,7 +19,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
+	"github.com/coreos/etcd/client"
 	"golang.org/x/net/context"
 
 	"github.com/trustedanalytics-ng/tap-catalog/etcd"
@@ -35,12 +37,42 @@ type RepositoryApi interface {
 	CreateDir(key string) error
 	GetLatestIndex(key string) (uint64, error)
 	GetData(key string, model interface{}) (interface{}, error)
+	GetDataWithProjection(key string, model interface{}, projection *FieldProjection) (interface{}, error)
 	GetListOfData(key string, model interface{}) ([]interface{}, error)
+	GetListOfDataWithProjection(key string, model interface{}, projection *FieldProjection) ([]interface{}, error)
 	GetListOfDataFlat(key string, model interface{}) ([]interface{}, error)
 	GetDataCounter(key string, model interface{}) (int, error)
 	CreateDirs(org string) error
 	IsExistByName(expectedName string, model interface{}, key string) (bool, error)
 	MonitorObjectsStates(key string, afterIndex uint64) (models.StateChange, error)
+	MonitorObjectsChanges(key string, afterIndex uint64) (models.ChangeEvent, error)
+	MonitorInstancesStatesOfClass(key string, instanceType models.InstanceType, classId string, afterIndex uint64) (models.StateChange, error)
+	WatchStateChanges(ctx context.Context, org string, afterIndex uint64, events chan<- models.StateChangeEvent) error
+	CreateIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error
+	SetIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error
+	GetIdempotencyRecord(key string) (models.IdempotencyRecord, error)
+	DeleteIdempotencyRecord(key string) error
+	SetTrashEntry(key string, entry models.TrashEntry, ttl time.Duration) error
+	GetTrashEntry(key string) (string, models.TrashEntry, error)
+	GetTrashEntries(key string) ([]models.TrashEntry, error)
+	DeleteTrashEntry(key string) error
+	GetEmptyTrashDirs(key string) ([]string, error)
+	DeleteEmptyTrashDir(key string) error
+	GetOrganizationSettings(key string) (models.OrganizationSettings, error)
+	SetOrganizationSettings(key string, settings models.OrganizationSettings) error
+	AddStateHistoryRecord(key string, record models.StateTransitionRecord, limit int) error
+	GetStateHistory(key string) ([]models.StateTransitionRecord, error)
+	DeleteStateHistory(key string) error
+	AddAuditEntry(key string, entry models.AuditEntry, ttl time.Duration) error
+	GetAuditEntries(key string, filter models.AuditFilter) ([]models.AuditEntry, error)
+	AddRevision(key string, revision models.Revision, limit int) (models.Revision, error)
+	GetRevisions(key string) ([]models.Revision, error)
+	GetRevision(key string, number uint64) (models.Revision, error)
+	DeleteRevisions(key string) error
+	GetMissingDirs(org string, timeout time.Duration) ([]string, error)
+	GetClusterHealth(timeout time.Duration) ([]models.EtcdMemberHealth, error)
+	MeasureRoundTrip(key string, timeout time.Duration) (models.EtcdRoundTrip, error)
+	GetOpenWatchesCount() int64
 }
 
 type RepositoryConnector struct {
@@ -52,11 +84,24 @@ func NewRepositoryAPI(etcdKVStore etcd.EtcdKVStore, dataMapper DataMapper) Repos
 	return &RepositoryConnector{etcdClient: etcdKVStore, mapper: dataMapper}
 }
 
-// TAP flow requires that the State field should be saved as last - when the rest of the object is ready
+// TAP flow requires that the State field should be saved as last - when the rest of the object is ready.
+// Entities without State (applications and plans) get their Id saved last instead, before any State,
+// so watchers see every entity as created only when all its fields are written
 func (t *RepositoryConnector) CreateData(keyStore map[string]interface{}) error {
 	stateKey, stateValue := getStateKeyValueAndRemoveItFromMap(keyStore)
+	idKeys := getCompletionIdKeys(keyStore)
 
 	for k, v := range keyStore {
+		if _, isIdKey := idKeys[k]; isIdKey {
+			continue
+		}
+		err := t.etcdClient.Create(k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range idKeys {
 		err := t.etcdClient.Create(k, v)
 		if err != nil {
 			return err
@@ -72,6 +117,19 @@ func (t *RepositoryConnector) CreateData(keyStore map[string]interface{}) error
 	return nil
 }
 
+// getCompletionIdKeys returns Id keys of entities which are completed by Id, see getCompletionFieldName
+func getCompletionIdKeys(keyStore map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range keyStore {
+		key, ok := parseEntityKey(k)
+		if ok && key.field == idFieldName && getCompletionFieldName(key.entityType) == idFieldName &&
+			k == key.path+keySeparator+idFieldName {
+			result[k] = v
+		}
+	}
+	return result
+}
+
 func getStateKeyValueAndRemoveItFromMap(keyStore map[string]interface{}) (string, interface{}) {
 	for k, v := range keyStore {
 		if isStateField(k) {
@@ -122,24 +180,21 @@ func (t *RepositoryConnector) UpdateData(updates []PatchSingleUpdate) error {
 	return err
 }
 
+// ApplyPatchedValues removes deleted subtrees first, so collection element can be deleted and added again in one patch
 func (t *RepositoryConnector) ApplyPatchedValues(patchedKeyValues PatchedKeyValues) error {
-	err := t.SetData(patchedKeyValues.Add)
-	if err != nil {
-		return err
+	for k, _ := range patchedKeyValues.Delete {
+		err := t.DeleteData(k)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = t.UpdateData(patchedKeyValues.Update)
+	err := t.SetData(patchedKeyValues.Add)
 	if err != nil {
 		return err
 	}
 
-	for k, _ := range patchedKeyValues.Delete {
-		err = t.DeleteData(k)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return t.UpdateData(patchedKeyValues.Update)
 }
 
 func (t *RepositoryConnector) DeleteData(key string) error {
@@ -163,6 +218,33 @@ func (t *RepositoryConnector) GetData(key string, model interface{}) (interface{
 	return t.mapper.ToModelInstance(key, node, model)
 }
 
+// Only the object directory is read at first - subtrees of selected collection/struct fields are fetched afterwards,
+// so excluded subtrees (e.g. Bindings or Metadata) are never read from etcd
+func (t *RepositoryConnector) GetDataWithProjection(key string, model interface{}, projection *FieldProjection) (interface{}, error) {
+	if projection == nil {
+		return t.GetData(key, model)
+	}
+
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return "", err
+	}
+
+	selectedNodes := client.Nodes{}
+	for _, childNode := range projection.filterNodes(node.Nodes) {
+		if childNode.Dir {
+			subtree, err := t.etcdClient.GetKeyNodesRecursively(childNode.Key)
+			if err != nil {
+				return "", err
+			}
+			childNode = &subtree
+		}
+		selectedNodes = append(selectedNodes, childNode)
+	}
+	node.Nodes = selectedNodes
+	return t.mapper.ToModelInstance(key, node, model)
+}
+
 func (t *RepositoryConnector) GetListOfData(key string, model interface{}) ([]interface{}, error) {
 	node, err := t.etcdClient.GetKeyNodesRecursively(key)
 
@@ -182,6 +264,33 @@ func (t *RepositoryConnector) GetListOfData(key string, model interface{}) ([]in
 	return result, nil
 }
 
+// The whole directory is read once, as in GetListOfData - nodes of not selected fields are dropped from every object
+// before it is parsed, so excluded subtrees (e.g. bindings or metadata) are not converted into model at all
+func (t *RepositoryConnector) GetListOfDataWithProjection(key string, model interface{}, projection *FieldProjection) ([]interface{}, error) {
+	if projection == nil {
+		return t.GetListOfData(key, model)
+	}
+
+	node, err := t.etcdClient.GetKeyNodesRecursively(key)
+
+	result := []interface{}{}
+
+	if err != nil {
+		return result, err
+	}
+
+	for _, childNode := range node.Nodes {
+		projectedNode := *childNode
+		projectedNode.Nodes = projection.filterNodes(childNode.Nodes)
+		elem, err := t.mapper.ToModelInstance(childNode.Key, projectedNode, model)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, elem)
+	}
+	return result, nil
+}
+
 func (t *RepositoryConnector) GetListOfDataFlat(key string, model interface{}) ([]interface{}, error) {
 	node, err := t.etcdClient.GetKeyNodes(key)
 
@@ -210,15 +319,7 @@ func (t *RepositoryConnector) GetDataCounter(key string, model interface{}) (int
 }
 
 func (t *RepositoryConnector) CreateDirs(org string) error {
-	dirs := []string{
-		org,
-		t.mapper.ToKey(org, Templates),
-		t.mapper.ToKey(org, Instances),
-		t.mapper.ToKey(org, Applications),
-		t.mapper.ToKey(org, Services),
-		t.mapper.ToKey(org, Images)}
-
-	for _, dir := range dirs {
+	for _, dir := range t.getLayoutDirs(org) {
 		if _, err := t.etcdClient.GetKeyNodesRecursively(dir); err != nil {
 			err := t.etcdClient.AddOrUpdateDir(dir)
 			if err != nil {
@@ -229,6 +330,18 @@ func (t *RepositoryConnector) CreateDirs(org string) error {
 	return nil
 }
 
+func (t *RepositoryConnector) getLayoutDirs(org string) []string {
+	return []string{
+		org,
+		t.mapper.ToKey(org, Templates),
+		t.mapper.ToKey(org, Instances),
+		t.mapper.ToKey(org, Applications),
+		t.mapper.ToKey(org, Services),
+		t.mapper.ToKey(org, Images),
+		t.mapper.ToKey(org, Trash),
+		t.mapper.ToKey(org, Audit)}
+}
+
 func (t *RepositoryConnector) IsExistByName(expectedName string, model interface{}, key string) (bool, error) {
 
 	result, err := t.GetListOfData(key, model)
@@ -254,6 +367,7 @@ func (t *RepositoryConnector) MonitorObjectsStates(basePath string, afterIndex u
 	if err != nil {
 		return result, err
 	}
+	defer trackWatch()()
 
 	for {
 		resp, err := watcher.Next(context.Background())
@@ -272,3 +386,40 @@ func (t *RepositoryConnector) MonitorObjectsStates(basePath string, afterIndex u
 		}
 	}
 }
+
+// MonitorInstancesStatesOfClass waits for the first State change of instance with given type and ClassId (any ClassId if empty).
+// Id of returned StateChange is ClassId of the instance, e.g. applicationId for application instances.
+func (t *RepositoryConnector) MonitorInstancesStatesOfClass(basePath string, instanceType models.InstanceType, classId string, afterIndex uint64) (models.StateChange, error) {
+	watcher, err := t.etcdClient.GetLongPollWatcherForKey(basePath, true, afterIndex)
+	if err != nil {
+		return models.StateChange{}, err
+	}
+	defer trackWatch()()
+
+	for {
+		resp, err := watcher.Next(context.Background())
+		if err != nil {
+			logger.Error("watcher.Next error:", err)
+			return models.StateChange{}, err
+		}
+		if !isStateField(resp.Node.Key) {
+			continue
+		}
+
+		instanceKey := strings.TrimSuffix(resp.Node.Key, keySeparator+stateFieldName)
+		result, err := t.GetData(instanceKey, models.Instance{})
+		if err != nil {
+			logger.Warningf("cannot get instance of changed state, key: %s, error: %v", instanceKey, err)
+			continue
+		}
+
+		instance := result.(models.Instance)
+		if instance.Type == instanceType && (classId == "" || instance.ClassId == classId) {
+			return models.StateChange{
+				Id:    instance.ClassId,
+				State: strings.Trim(resp.Node.Value, `"`),
+				Index: resp.Node.ModifiedIndex,
+			}, nil
+		}
+	}
+}
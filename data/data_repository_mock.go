@@ -21,6 +21,8 @@ package data
 import (
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/trustedanalytics-ng/tap-catalog/models"
+	context "golang.org/x/net/context"
+	time "time"
 )
 
 // Mock of RepositoryApi interface
@@ -126,6 +128,17 @@ func (_mr *_MockRepositoryApiRecorder) GetData(arg0, arg1 interface{}) *gomock.C
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetData", arg0, arg1)
 }
 
+func (_m *MockRepositoryApi) GetDataWithProjection(key string, model interface{}, projection *FieldProjection) (interface{}, error) {
+	ret := _m.ctrl.Call(_m, "GetDataWithProjection", key, model, projection)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetDataWithProjection(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetDataWithProjection", arg0, arg1, arg2)
+}
+
 func (_m *MockRepositoryApi) GetListOfData(key string, model interface{}) ([]interface{}, error) {
 	ret := _m.ctrl.Call(_m, "GetListOfData", key, model)
 	ret0, _ := ret[0].([]interface{})
@@ -137,6 +150,17 @@ func (_mr *_MockRepositoryApiRecorder) GetListOfData(arg0, arg1 interface{}) *go
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetListOfData", arg0, arg1)
 }
 
+func (_m *MockRepositoryApi) GetListOfDataWithProjection(key string, model interface{}, projection *FieldProjection) ([]interface{}, error) {
+	ret := _m.ctrl.Call(_m, "GetListOfDataWithProjection", key, model, projection)
+	ret0, _ := ret[0].([]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetListOfDataWithProjection(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetListOfDataWithProjection", arg0, arg1, arg2)
+}
+
 func (_m *MockRepositoryApi) GetListOfDataFlat(key string, model interface{}) ([]interface{}, error) {
 	ret := _m.ctrl.Call(_m, "GetListOfDataFlat", key, model)
 	ret0, _ := ret[0].([]interface{})
@@ -190,3 +214,299 @@ func (_m *MockRepositoryApi) MonitorObjectsStates(key string, afterIndex uint64)
 func (_mr *_MockRepositoryApiRecorder) MonitorObjectsStates(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "MonitorObjectsStates", arg0, arg1)
 }
+
+func (_m *MockRepositoryApi) MonitorObjectsChanges(key string, afterIndex uint64) (models.ChangeEvent, error) {
+	ret := _m.ctrl.Call(_m, "MonitorObjectsChanges", key, afterIndex)
+	ret0, _ := ret[0].(models.ChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) MonitorObjectsChanges(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MonitorObjectsChanges", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) MonitorInstancesStatesOfClass(key string, instanceType models.InstanceType, classId string, afterIndex uint64) (models.StateChange, error) {
+	ret := _m.ctrl.Call(_m, "MonitorInstancesStatesOfClass", key, instanceType, classId, afterIndex)
+	ret0, _ := ret[0].(models.StateChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) MonitorInstancesStatesOfClass(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MonitorInstancesStatesOfClass", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockRepositoryApi) WatchStateChanges(ctx context.Context, org string, afterIndex uint64, events chan<- models.StateChangeEvent) error {
+	ret := _m.ctrl.Call(_m, "WatchStateChanges", ctx, org, afterIndex, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) WatchStateChanges(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WatchStateChanges", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockRepositoryApi) CreateIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "CreateIdempotencyRecord", key, record, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) CreateIdempotencyRecord(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateIdempotencyRecord", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) SetIdempotencyRecord(key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "SetIdempotencyRecord", key, record, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) SetIdempotencyRecord(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetIdempotencyRecord", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) GetIdempotencyRecord(key string) (models.IdempotencyRecord, error) {
+	ret := _m.ctrl.Call(_m, "GetIdempotencyRecord", key)
+	ret0, _ := ret[0].(models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetIdempotencyRecord(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetIdempotencyRecord", arg0)
+}
+
+func (_m *MockRepositoryApi) DeleteIdempotencyRecord(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteIdempotencyRecord", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) DeleteIdempotencyRecord(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteIdempotencyRecord", arg0)
+}
+
+func (_m *MockRepositoryApi) SetTrashEntry(key string, entry models.TrashEntry, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "SetTrashEntry", key, entry, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) SetTrashEntry(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetTrashEntry", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) GetTrashEntry(key string) (string, models.TrashEntry, error) {
+	ret := _m.ctrl.Call(_m, "GetTrashEntry", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(models.TrashEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetTrashEntry(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetTrashEntry", arg0)
+}
+
+func (_m *MockRepositoryApi) GetTrashEntries(key string) ([]models.TrashEntry, error) {
+	ret := _m.ctrl.Call(_m, "GetTrashEntries", key)
+	ret0, _ := ret[0].([]models.TrashEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetTrashEntries(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetTrashEntries", arg0)
+}
+
+func (_m *MockRepositoryApi) DeleteTrashEntry(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteTrashEntry", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) DeleteTrashEntry(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteTrashEntry", arg0)
+}
+
+func (_m *MockRepositoryApi) GetEmptyTrashDirs(key string) ([]string, error) {
+	ret := _m.ctrl.Call(_m, "GetEmptyTrashDirs", key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetEmptyTrashDirs(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetEmptyTrashDirs", arg0)
+}
+
+func (_m *MockRepositoryApi) DeleteEmptyTrashDir(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteEmptyTrashDir", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) DeleteEmptyTrashDir(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteEmptyTrashDir", arg0)
+}
+
+func (_m *MockRepositoryApi) GetOrganizationSettings(key string) (models.OrganizationSettings, error) {
+	ret := _m.ctrl.Call(_m, "GetOrganizationSettings", key)
+	ret0, _ := ret[0].(models.OrganizationSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetOrganizationSettings(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetOrganizationSettings", arg0)
+}
+
+func (_m *MockRepositoryApi) SetOrganizationSettings(key string, settings models.OrganizationSettings) error {
+	ret := _m.ctrl.Call(_m, "SetOrganizationSettings", key, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) SetOrganizationSettings(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetOrganizationSettings", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) AddStateHistoryRecord(key string, record models.StateTransitionRecord, limit int) error {
+	ret := _m.ctrl.Call(_m, "AddStateHistoryRecord", key, record, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) AddStateHistoryRecord(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddStateHistoryRecord", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) DeleteRevisions(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteRevisions", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) DeleteRevisions(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteRevisions", arg0)
+}
+
+func (_m *MockRepositoryApi) DeleteStateHistory(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteStateHistory", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) DeleteStateHistory(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteStateHistory", arg0)
+}
+
+func (_m *MockRepositoryApi) GetStateHistory(key string) ([]models.StateTransitionRecord, error) {
+	ret := _m.ctrl.Call(_m, "GetStateHistory", key)
+	ret0, _ := ret[0].([]models.StateTransitionRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetStateHistory(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetStateHistory", arg0)
+}
+
+func (_m *MockRepositoryApi) AddAuditEntry(key string, entry models.AuditEntry, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "AddAuditEntry", key, entry, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) AddAuditEntry(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddAuditEntry", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) GetAuditEntries(key string, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	ret := _m.ctrl.Call(_m, "GetAuditEntries", key, filter)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetAuditEntries(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetAuditEntries", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) AddRevision(key string, revision models.Revision, limit int) (models.Revision, error) {
+	ret := _m.ctrl.Call(_m, "AddRevision", key, revision, limit)
+	ret0, _ := ret[0].(models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) AddRevision(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddRevision", arg0, arg1, arg2)
+}
+
+func (_m *MockRepositoryApi) GetRevisions(key string) ([]models.Revision, error) {
+	ret := _m.ctrl.Call(_m, "GetRevisions", key)
+	ret0, _ := ret[0].([]models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetRevisions(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetRevisions", arg0)
+}
+
+func (_m *MockRepositoryApi) GetRevision(key string, number uint64) (models.Revision, error) {
+	ret := _m.ctrl.Call(_m, "GetRevision", key, number)
+	ret0, _ := ret[0].(models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetRevision(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetRevision", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) GetMissingDirs(org string, timeout time.Duration) ([]string, error) {
+	ret := _m.ctrl.Call(_m, "GetMissingDirs", org, timeout)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetMissingDirs(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetMissingDirs", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) GetClusterHealth(timeout time.Duration) ([]models.EtcdMemberHealth, error) {
+	ret := _m.ctrl.Call(_m, "GetClusterHealth", timeout)
+	ret0, _ := ret[0].([]models.EtcdMemberHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetClusterHealth(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetClusterHealth", arg0)
+}
+
+func (_m *MockRepositoryApi) MeasureRoundTrip(key string, timeout time.Duration) (models.EtcdRoundTrip, error) {
+	ret := _m.ctrl.Call(_m, "MeasureRoundTrip", key, timeout)
+	ret0, _ := ret[0].(models.EtcdRoundTrip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockRepositoryApiRecorder) MeasureRoundTrip(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MeasureRoundTrip", arg0, arg1)
+}
+
+func (_m *MockRepositoryApi) GetOpenWatchesCount() int64 {
+	ret := _m.ctrl.Call(_m, "GetOpenWatchesCount")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+func (_mr *_MockRepositoryApiRecorder) GetOpenWatchesCount() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetOpenWatchesCount")
+}
@@ -17,7 +17,9 @@
 package data
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/coreos/etcd/client"
@@ -25,6 +27,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/trustedanalytics-ng/tap-catalog/etcd"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
 const (
@@ -74,6 +77,22 @@ func TestCreateData(t *testing.T) {
 			})
 		})
 
+		Convey("Id field of application should be saved last", func() {
+			applicationKey := "/org/" + Applications + "/1"
+			gomock.InOrder(
+				etcdClientMock.EXPECT().Create(applicationKey+"/Name", data1).Return(nil),
+				etcdClientMock.EXPECT().Create(applicationKey+keySeparator+idFieldName, data2).Return(nil),
+			)
+			input := map[string]interface{}{
+				applicationKey + keySeparator + idFieldName: data2,
+				applicationKey + "/Name":                    data1,
+			}
+			err := repository.CreateData(input)
+			Convey("response error should be nil", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
 		Convey("When data is not created successfuly", func() {
 			etcdClientMock.EXPECT().Create(key1, data1).Return(errors.New(""))
 
@@ -147,6 +166,79 @@ func TestApplyPatchedValues(t *testing.T) {
 	})
 }
 
+func TestApplyPatchedValuesOfNestedCollection(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	mapper := DataMapper{}
+
+	Convey("Nested collection element removed by JSON Patch should not be read back", t, func() {
+		const servicesKey = "/org/Services"
+		serviceKey := servicesKey + "/1"
+		service := models.Service{Id: "1", Name: "service", Plans: []models.ServicePlan{{
+			Id:   "plan",
+			Name: "free",
+			Dependencies: []models.ServiceDependency{
+				{Id: "a", ServiceId: "2", PlanId: "p2"},
+				{Id: "b", ServiceId: "3", PlanId: "p3"},
+			},
+		}}}
+
+		store := map[string]string{}
+		storeValue := func(key string, value interface{}) {
+			valueBytes, _ := json.Marshal(value)
+			store[key] = string(valueBytes)
+		}
+		for key, value := range mapper.ToKeyValue(servicesKey, service, true) {
+			storeValue(key, value)
+		}
+		etcdClientMock.EXPECT().GetKeyNodesRecursively(gomock.Any()).Return(client.Node{}, nil).AnyTimes()
+		etcdClientMock.EXPECT().Create(gomock.Any(), gomock.Any()).Do(storeValue).Return(nil).AnyTimes()
+		etcdClientMock.EXPECT().AddOrUpdate(gomock.Any(), gomock.Any()).Do(storeValue).Return(nil).AnyTimes()
+		etcdClientMock.EXPECT().DeleteDir(gomock.Any()).Do(func(key string) {
+			for storedKey := range store {
+				if storedKey == key || strings.HasPrefix(storedKey, key+"/") {
+					delete(store, storedKey)
+				}
+			}
+		}).Return(nil).AnyTimes()
+
+		patches, err := mapper.ToPatchesByJsonPatch(service, []models.JsonPatchOperation{
+			{Op: models.JsonPatchRemove, Path: "/plans/0/dependencies/0"},
+		}, "user")
+		So(err, ShouldBeNil)
+		patchedValues, err := mapper.ToKeyValueByPatches(serviceKey, models.Service{}, patches)
+		So(err, ShouldBeNil)
+
+		So(repository.ApplyPatchedValues(patchedValues), ShouldBeNil)
+
+		result, err := mapper.ToModelInstance(serviceKey, toStoredNode(store, serviceKey), models.Service{})
+		So(err, ShouldBeNil)
+		So(result.(models.Service).Plans, ShouldHaveLength, 1)
+		So(result.(models.Service).Plans[0].Dependencies, ShouldHaveLength, 1)
+		So(result.(models.Service).Plans[0].Dependencies[0].Id, ShouldEqual, "b")
+	})
+}
+
+// toStoredNode builds etcd directory node of key from flat map of stored values
+func toStoredNode(store map[string]string, key string) client.Node {
+	childKeys := map[string]bool{}
+	for storedKey := range store {
+		if strings.HasPrefix(storedKey, key+"/") {
+			childKeys[key+"/"+strings.SplitN(strings.TrimPrefix(storedKey, key+"/"), "/", 2)[0]] = true
+		}
+	}
+
+	node := client.Node{Key: key, Dir: true}
+	for childKey := range childKeys {
+		if value, ok := store[childKey]; ok {
+			node.Nodes = append(node.Nodes, &client.Node{Key: childKey, Value: value})
+		} else {
+			childNode := toStoredNode(store, childKey)
+			node.Nodes = append(node.Nodes, &childNode)
+		}
+	}
+	return node
+}
+
 func prepareDataRepositoryWithMocks(t *testing.T) (RepositoryApi, *etcd.MockEtcdKVStore) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -0,0 +1,96 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/coreos/etcd/client"
+
+	"github.com/trustedanalytics-ng/tap-catalog/etcd"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// AddRevision stores revision in the revisions directory of entity (the key) with number following the latest one
+// and removes the oldest revisions above the limit. Missing directory means there are no revisions yet, other read
+// errors are reported by Create. Concurrent change of the same entity fails on Create, as the number is already used.
+func (t *RepositoryConnector) AddRevision(key string, revision models.Revision, limit int) (models.Revision, error) {
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		node.Nodes = nil
+	}
+
+	revision.Revision = 1
+	if len(node.Nodes) > 0 {
+		latest, err := strconv.ParseUint(path.Base(node.Nodes[len(node.Nodes)-1].Key), 10, 64)
+		if err != nil {
+			return revision, fmt.Errorf("cannot read number of revision %q: %v", node.Nodes[len(node.Nodes)-1].Key, err)
+		}
+		revision.Revision = latest + 1
+	}
+
+	if err := t.etcdClient.Create(t.buildRevisionKey(key, revision.Revision), revision); err != nil {
+		return revision, err
+	}
+
+	// nodes are sorted by key, so the oldest revisions are first
+	for i := 0; i < len(node.Nodes)+1-limit; i++ {
+		if err := t.etcdClient.Delete(node.Nodes[i].Key, 0); err != nil {
+			return revision, err
+		}
+	}
+	return revision, nil
+}
+
+// GetRevisions returns revisions stored in the revisions directory of entity, the oldest first
+func (t *RepositoryConnector) GetRevisions(key string) ([]models.Revision, error) {
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.Revision{}
+	for _, revisionNode := range node.Nodes {
+		revision := models.Revision{}
+		if err := json.Unmarshal([]byte(revisionNode.Value), &revision); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal revision %q: %v", revisionNode.Key, err)
+		}
+		result = append(result, revision)
+	}
+	return result, nil
+}
+
+func (t *RepositoryConnector) GetRevision(key string, number uint64) (models.Revision, error) {
+	revision := models.Revision{}
+	err := t.etcdClient.GetKeyIntoStruct(t.buildRevisionKey(key, number), &revision)
+	return revision, err
+}
+
+// DeleteRevisions removes revisions directory (the key), object without revisions is not an error
+func (t *RepositoryConnector) DeleteRevisions(key string) error {
+	if err := t.etcdClient.DeleteDir(key); err != nil && !client.IsKeyNotFound(etcd.ClientError(err)) {
+		return err
+	}
+	return nil
+}
+
+func (t *RepositoryConnector) buildRevisionKey(key string, number uint64) string {
+	return t.mapper.ToKey(key, fmt.Sprintf("%020d", number))
+}
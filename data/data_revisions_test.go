@@ -0,0 +1,72 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coreos/etcd/client"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestAddRevision(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	revisionsKey := "/org/Revisions/Services/1"
+
+	Convey("testing AddRevision", t, func() {
+		Convey("When entity has no revisions yet, first revision should be created", func() {
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(revisionsKey).Return(client.Node{}, errors.New("Key not found")),
+				etcdClientMock.EXPECT().Create(revisionsKey+"/00000000000000000001", gomock.Any()).Return(nil),
+			)
+
+			revision, err := repository.AddRevision(revisionsKey, models.Revision{}, 2)
+			So(err, ShouldBeNil)
+			So(revision.Revision, ShouldEqual, 1)
+		})
+
+		Convey("When limit is reached, the oldest revision should be removed", func() {
+			nodes := client.Node{Nodes: client.Nodes{
+				{Key: revisionsKey + "/00000000000000000003"},
+				{Key: revisionsKey + "/00000000000000000004"},
+			}}
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(revisionsKey).Return(nodes, nil),
+				etcdClientMock.EXPECT().Create(revisionsKey+"/00000000000000000005", gomock.Any()).Return(nil),
+				etcdClientMock.EXPECT().Delete(revisionsKey+"/00000000000000000003", uint64(0)).Return(nil),
+			)
+
+			revision, err := repository.AddRevision(revisionsKey, models.Revision{}, 2)
+			So(err, ShouldBeNil)
+			So(revision.Revision, ShouldEqual, 5)
+		})
+
+		Convey("When revision number is already used, error should be returned", func() {
+			gomock.InOrder(
+				etcdClientMock.EXPECT().GetKeyNodes(revisionsKey).Return(client.Node{}, nil),
+				etcdClientMock.EXPECT().Create(revisionsKey+"/00000000000000000001", gomock.Any()).Return(errors.New("Key already exists")),
+			)
+
+			_, err := repository.AddRevision(revisionsKey, models.Revision{}, 2)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,28 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import "github.com/trustedanalytics-ng/tap-catalog/models"
+
+func (t *RepositoryConnector) GetOrganizationSettings(key string) (models.OrganizationSettings, error) {
+	settings := models.OrganizationSettings{}
+	err := t.etcdClient.GetKeyIntoStruct(key, &settings)
+	return settings, err
+}
+
+func (t *RepositoryConnector) SetOrganizationSettings(key string, settings models.OrganizationSettings) error {
+	return t.etcdClient.AddOrUpdate(key, settings)
+}
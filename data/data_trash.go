@@ -0,0 +1,99 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+// SetTrashEntry stores deleted object as a single value - etcd removes it after retention ttl.
+// Entries are kept in directory of object id and named by deletion time, so the same id can be deleted repeatedly.
+func (t *RepositoryConnector) SetTrashEntry(key string, entry models.TrashEntry, ttl time.Duration) error {
+	return t.etcdClient.AddOrUpdateWithTTL(key, entry, ttl)
+}
+
+// GetTrashEntry returns the latest entry stored in directory of object id (the key) together with its key
+func (t *RepositoryConnector) GetTrashEntry(key string) (string, models.TrashEntry, error) {
+	entry := models.TrashEntry{}
+	node, err := t.etcdClient.GetKeyNodes(key)
+	if err != nil {
+		return "", entry, err
+	}
+	// expired entries leave empty directory
+	if len(node.Nodes) == 0 {
+		return "", entry, fmt.Errorf("trash entry %q: Key not found", key)
+	}
+
+	// nodes are sorted by key, so the latest entry is the last one
+	entryNode := node.Nodes[len(node.Nodes)-1]
+	if err := json.Unmarshal([]byte(entryNode.Value), &entry); err != nil {
+		return "", entry, fmt.Errorf("cannot unmarshal trash entry %q: %v", entryNode.Key, err)
+	}
+	return entryNode.Key, entry, nil
+}
+
+// GetTrashEntries returns all entries stored under the key, trash of every entity type is kept in separate directory
+func (t *RepositoryConnector) GetTrashEntries(key string) ([]models.TrashEntry, error) {
+	node, err := t.etcdClient.GetKeyNodesRecursively(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.TrashEntry{}
+	for _, typeNode := range node.Nodes {
+		for _, idNode := range typeNode.Nodes {
+			for _, entryNode := range idNode.Nodes {
+				entry := models.TrashEntry{}
+				if err := json.Unmarshal([]byte(entryNode.Value), &entry); err != nil {
+					return nil, fmt.Errorf("cannot unmarshal trash entry %q: %v", entryNode.Key, err)
+				}
+				result = append(result, entry)
+			}
+		}
+	}
+	return result, nil
+}
+
+// DeleteTrashEntry removes single entry or, given directory of object id, all entries of the object
+func (t *RepositoryConnector) DeleteTrashEntry(key string) error {
+	return t.etcdClient.Delete(key, 0)
+}
+
+// GetEmptyTrashDirs returns directories of object ids stored under the key (trash of single entity type)
+// which have no entries left - etcd removes expired entries, but not their directories
+func (t *RepositoryConnector) GetEmptyTrashDirs(key string) ([]string, error) {
+	node, err := t.etcdClient.GetKeyNodesRecursively(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, idNode := range node.Nodes {
+		if idNode.Dir && len(idNode.Nodes) == 0 {
+			result = append(result, idNode.Key)
+		}
+	}
+	return result, nil
+}
+
+// DeleteEmptyTrashDir fails if object was moved to trash again after the directory was read
+func (t *RepositoryConnector) DeleteEmptyTrashDir(key string) error {
+	return t.etcdClient.DeleteEmptyDir(key)
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/coreos/etcd/client"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestGetTrashEntry(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	trashKey := "/org/Trash/Services/1"
+
+	Convey("testing GetTrashEntry", t, func() {
+		Convey("When object was deleted repeatedly, the latest entry should be returned", func() {
+			older, _ := json.Marshal(models.TrashEntry{Id: "1", DeletedOn: 1})
+			latest, _ := json.Marshal(models.TrashEntry{Id: "1", DeletedOn: 2})
+			etcdClientMock.EXPECT().GetKeyNodes(trashKey).Return(client.Node{Nodes: client.Nodes{
+				{Key: trashKey + "/00000000001000000000", Value: string(older)},
+				{Key: trashKey + "/00000000002000000000", Value: string(latest)},
+			}}, nil)
+
+			key, entry, err := repository.GetTrashEntry(trashKey)
+			So(err, ShouldBeNil)
+			So(key, ShouldEqual, trashKey+"/00000000002000000000")
+			So(entry.DeletedOn, ShouldEqual, 2)
+		})
+
+		Convey("When all entries expired, not found error should be returned", func() {
+			etcdClientMock.EXPECT().GetKeyNodes(trashKey).Return(client.Node{Key: trashKey, Dir: true}, nil)
+
+			_, _, err := repository.GetTrashEntry(trashKey)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "not found")
+		})
+	})
+}
+
+func TestGetEmptyTrashDirs(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+	trashKey := "/org/Trash/Services"
+
+	Convey("testing GetEmptyTrashDirs", t, func() {
+		Convey("Only directories of ids without any trash entry should be returned", func() {
+			etcdClientMock.EXPECT().GetKeyNodesRecursively(trashKey).Return(client.Node{Key: trashKey, Dir: true, Nodes: client.Nodes{
+				{Key: trashKey + "/1", Dir: true},
+				{Key: trashKey + "/2", Dir: true, Nodes: client.Nodes{{Key: trashKey + "/2/00000000001000000000", Value: "{}"}}},
+			}}, nil)
+
+			dirKeys, err := repository.GetEmptyTrashDirs(trashKey)
+			So(err, ShouldBeNil)
+			So(dirKeys, ShouldResemble, []string{trashKey + "/1"})
+		})
+	})
+}
@@ -16,12 +16,19 @@
 package data
 
 const (
-	Templates    = "Templates"
-	Instances    = "Instances"
-	Applications = "Applications"
-	Services     = "Services"
-	Plans        = "Plans"
-	Bindings     = "Bindings"
-	Metadata     = "Metadata"
-	Images       = "Images"
+	Templates       = "Templates"
+	Instances       = "Instances"
+	Applications    = "Applications"
+	Services        = "Services"
+	Plans           = "Plans"
+	Bindings        = "Bindings"
+	Metadata        = "Metadata"
+	Images          = "Images"
+	Trash           = "Trash"
+	Audit           = "Audit"
+	History         = "History"
+	Revisions       = "Revisions"
+	IdempotencyKeys = "IdempotencyKeys"
+	Settings        = "Settings"
+	HealthProbe     = "HealthProbe"
 )
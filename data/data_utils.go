@@ -69,15 +69,32 @@ func GetEntityKey(organization string, entity string) string {
 }
 
 func GetFilteredInstances(expectedInstanceType models.InstanceType, expectedClassId string, org string, repositoryApi RepositoryApi) ([]models.Instance, error) {
-	filteredInstances := []models.Instance{}
-
 	result, err := repositoryApi.GetListOfData(GetEntityKey(org, Instances), models.Instance{})
+	if err != nil {
+		return []models.Instance{}, err
+	}
+	return filterInstances(result, expectedInstanceType, expectedClassId)
+}
 
+// GetFilteredInstancesWithProjection reads only fields selected by projection - Type and ClassId are read anyway,
+// as instances are filtered by them
+func GetFilteredInstancesWithProjection(expectedInstanceType models.InstanceType, expectedClassId string, org string,
+	repositoryApi RepositoryApi, projection *FieldProjection) ([]models.Instance, error) {
+	if projection == nil {
+		return GetFilteredInstances(expectedInstanceType, expectedClassId, org, repositoryApi)
+	}
+
+	result, err := repositoryApi.GetListOfDataWithProjection(GetEntityKey(org, Instances), models.Instance{},
+		projection.WithFields("Type", "ClassId"))
 	if err != nil {
-		return filteredInstances, err
+		return []models.Instance{}, err
 	}
+	return filterInstances(result, expectedInstanceType, expectedClassId)
+}
 
-	for _, el := range result {
+func filterInstances(instances []interface{}, expectedInstanceType models.InstanceType, expectedClassId string) ([]models.Instance, error) {
+	filteredInstances := []models.Instance{}
+	for _, el := range instances {
 		instance, ok := el.(models.Instance)
 		if !ok {
 			return filteredInstances, errors.New("Cannot convert element to models.Instance")
@@ -0,0 +1,184 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"strings"
+
+	"github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const (
+	etcdActionCreate           = "create"
+	etcdActionDelete           = "delete"
+	etcdActionCompareAndDelete = "compareAndDelete"
+	etcdActionExpire           = "expire"
+)
+
+var entityTypesByDir = map[string]models.EntityType{
+	Applications: models.EntityTypeApplication,
+	Images:       models.EntityTypeImage,
+	Instances:    models.EntityTypeInstance,
+	Services:     models.EntityTypeService,
+	Templates:    models.EntityTypeTemplate,
+}
+
+// WatchStateChanges sends every State change of organization entities to events channel.
+// It blocks until ctx is cancelled or watcher returns error (e.g. when afterIndex was already cleared from etcd history).
+func (t *RepositoryConnector) WatchStateChanges(ctx context.Context, org string, afterIndex uint64, events chan<- models.StateChangeEvent) error {
+	watcher, err := t.etcdClient.GetLongPollWatcherForKey(t.mapper.ToKey("", org), true, afterIndex)
+	if err != nil {
+		return err
+	}
+	defer trackWatch()()
+
+	for {
+		resp, err := watcher.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.Error("watcher.Next error:", err)
+			return err
+		}
+
+		key, ok := parseEntityKey(resp.Node.Key)
+		if !ok || key.field != stateFieldName || resp.Node.Dir {
+			continue
+		}
+
+		event := models.StateChangeEvent{
+			EntityType: key.entityType,
+			Id:         key.id,
+			State:      strings.Trim(resp.Node.Value, `"`),
+			Index:      resp.Node.ModifiedIndex,
+		}
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// MonitorObjectsChanges waits for the first change of any entity stored under basePath
+func (t *RepositoryConnector) MonitorObjectsChanges(basePath string, afterIndex uint64) (models.ChangeEvent, error) {
+	watcher, err := t.etcdClient.GetLongPollWatcherForKey(basePath, true, afterIndex)
+	if err != nil {
+		return models.ChangeEvent{}, err
+	}
+	defer trackWatch()()
+
+	for {
+		resp, err := watcher.Next(context.Background())
+		if err != nil {
+			logger.Error("watcher.Next error:", err)
+			return models.ChangeEvent{}, err
+		}
+
+		if event, ok := t.toChangeEvent(resp); ok {
+			return event, nil
+		}
+	}
+}
+
+// toChangeEvent maps single etcd key change to entity change. Entity is created when its completion field
+// is stored (State is saved as the last one, entities without State are completed by Id) - writes done
+// before that are part of the creation and are not reported.
+func (t *RepositoryConnector) toChangeEvent(resp *client.Response) (models.ChangeEvent, bool) {
+	key, ok := parseEntityKey(resp.Node.Key)
+	if !ok {
+		return models.ChangeEvent{}, false
+	}
+
+	event := models.ChangeEvent{
+		EntityType: key.entityType,
+		Id:         key.id,
+		ParentId:   key.parentId,
+		Fields:     []string{},
+		Index:      resp.Node.ModifiedIndex,
+	}
+
+	switch {
+	case isDeleteAction(resp.Action) && key.field == "":
+		event.Type = models.ChangeEventTypeDeleted
+	case key.field == "":
+		return event, false
+	case resp.Action == etcdActionCreate && key.field == getCompletionFieldName(key.entityType):
+		event.Type = models.ChangeEventTypeCreated
+	case resp.Action == etcdActionCreate && !t.isEntityComplete(key):
+		return event, false
+	default:
+		event.Type = models.ChangeEventTypeUpdated
+		event.Fields = []string{key.field}
+	}
+	return event, true
+}
+
+func (t *RepositoryConnector) isEntityComplete(key entityKey) bool {
+	node, _ := t.etcdClient.GetKeyNodes(t.mapper.ToKey(key.path, getCompletionFieldName(key.entityType)))
+	return node.Key != ""
+}
+
+func getCompletionFieldName(entityType models.EntityType) string {
+	if entityType == models.EntityTypeApplication || entityType == models.EntityTypePlan {
+		return idFieldName
+	}
+	return stateFieldName
+}
+
+func isDeleteAction(action string) bool {
+	return action == etcdActionDelete || action == etcdActionCompareAndDelete || action == etcdActionExpire
+}
+
+type entityKey struct {
+	entityType models.EntityType
+	id         string
+	parentId   string
+	field      string
+	path       string
+}
+
+// parseEntityKey splits key in form /<org>/<EntityDir>/<id>/<Field>/... into its parts.
+// Plans are nested in services: /<org>/Services/<serviceId>/Plans/<planId>/<Field>/...
+// Field is empty if key points to the entity directory itself.
+func parseEntityKey(key string) (entityKey, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, keySeparator), keySeparator)
+	if len(parts) < 3 {
+		return entityKey{}, false
+	}
+
+	entityType, ok := entityTypesByDir[parts[1]]
+	if !ok {
+		return entityKey{}, false
+	}
+
+	result := entityKey{entityType: entityType, id: parts[2]}
+	entityPathLength := 3
+	if entityType == models.EntityTypeService && len(parts) > 4 && parts[3] == Plans {
+		result = entityKey{entityType: models.EntityTypePlan, id: parts[4], parentId: parts[2]}
+		entityPathLength = 5
+	}
+
+	result.path = keySeparator + strings.Join(parts[:entityPathLength], keySeparator)
+	if len(parts) > entityPathLength {
+		result.field = parts[entityPathLength]
+	}
+	return result, true
+}
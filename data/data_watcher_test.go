@@ -0,0 +1,139 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coreos/etcd/client"
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+func TestParseEntityKey(t *testing.T) {
+	Convey("Testing parseEntityKey", t, func() {
+		Convey("should return entity type, id and field for entity field key", func() {
+			key, ok := parseEntityKey("/org/Instances/1/State")
+			So(ok, ShouldBeTrue)
+			So(key.entityType, ShouldEqual, models.EntityTypeInstance)
+			So(key.id, ShouldEqual, "1")
+			So(key.field, ShouldEqual, "State")
+			So(key.path, ShouldEqual, "/org/Instances/1")
+		})
+
+		Convey("should return empty field for entity directory key", func() {
+			key, ok := parseEntityKey("/org/Templates/2")
+			So(ok, ShouldBeTrue)
+			So(key.id, ShouldEqual, "2")
+			So(key.field, ShouldEqual, "")
+		})
+
+		Convey("should return plan with parent service id for keys nested in service plans", func() {
+			key, ok := parseEntityKey("/org/Services/1/Plans/2/Name")
+			So(ok, ShouldBeTrue)
+			So(key.entityType, ShouldEqual, models.EntityTypePlan)
+			So(key.id, ShouldEqual, "2")
+			So(key.parentId, ShouldEqual, "1")
+			So(key.field, ShouldEqual, "Name")
+		})
+
+		Convey("should ignore keys outside of known entity directories", func() {
+			_, ok := parseEntityKey("/org/Bindings/1/State")
+			So(ok, ShouldBeFalse)
+
+			_, ok = parseEntityKey("/org/Instances")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestMonitorObjectsChanges(t *testing.T) {
+	repository, etcdClientMock := prepareDataRepositoryWithMocks(t)
+
+	Convey("Testing MonitorObjectsChanges", t, func() {
+		const instanceKey = "/org/Instances/1"
+		watcherMock := &watcherStub{}
+		etcdClientMock.EXPECT().GetLongPollWatcherForKey("/org/Instances", true, uint64(5)).Return(watcherMock, nil)
+
+		Convey("fields written during creation should be skipped and State creation reported as CREATED", func() {
+			watcherMock.responses = []*client.Response{
+				{Action: "create", Node: &client.Node{Key: instanceKey + "/Name", ModifiedIndex: 6}},
+				{Action: "create", Node: &client.Node{Key: instanceKey + "/State", ModifiedIndex: 7}},
+			}
+			etcdClientMock.EXPECT().GetKeyNodes(instanceKey+"/State").Return(client.Node{}, errors.New("Key not found"))
+
+			event, err := repository.MonitorObjectsChanges("/org/Instances", 5)
+
+			So(err, ShouldBeNil)
+			So(event, ShouldResemble, models.ChangeEvent{Type: models.ChangeEventTypeCreated, EntityType: models.EntityTypeInstance,
+				Id: "1", Fields: []string{}, Index: 7})
+		})
+
+		Convey("change of nested key should be reported as UPDATED top-level field", func() {
+			watcherMock.responses = []*client.Response{
+				{Action: "delete", Node: &client.Node{Key: instanceKey + "/Bindings/0", ModifiedIndex: 8}},
+			}
+
+			event, err := repository.MonitorObjectsChanges("/org/Instances", 5)
+
+			So(err, ShouldBeNil)
+			So(event.Type, ShouldEqual, models.ChangeEventTypeUpdated)
+			So(event.Fields, ShouldResemble, []string{"Bindings"})
+		})
+
+		Convey("removal of entity directory should be reported as DELETED", func() {
+			watcherMock.responses = []*client.Response{
+				{Action: "delete", Node: &client.Node{Key: instanceKey, Dir: true, ModifiedIndex: 9}},
+			}
+
+			event, err := repository.MonitorObjectsChanges("/org/Instances", 5)
+
+			So(err, ShouldBeNil)
+			So(event.Type, ShouldEqual, models.ChangeEventTypeDeleted)
+		})
+	})
+}
+
+type watcherStub struct {
+	responses []*client.Response
+}
+
+func (w *watcherStub) Next(ctx context.Context) (*client.Response, error) {
+	if len(w.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	resp := w.responses[0]
+	w.responses = w.responses[1:]
+	return resp, nil
+}
+
+func TestEventsFilterMatches(t *testing.T) {
+	Convey("Testing EventsFilter Matches", t, func() {
+		Convey("empty filter should match every event", func() {
+			So(models.EventsFilter{}.Matches(models.EntityTypeImage, "1"), ShouldBeTrue)
+		})
+
+		Convey("filter should match only provided entity types and id", func() {
+			filter := models.EventsFilter{EntityTypes: []models.EntityType{models.EntityTypeInstance}, Id: "1"}
+			So(filter.Matches(models.EntityTypeInstance, "1"), ShouldBeTrue)
+			So(filter.Matches(models.EntityTypeInstance, "2"), ShouldBeFalse)
+			So(filter.Matches(models.EntityTypeImage, "1"), ShouldBeFalse)
+		})
+	})
+}
@@ -36,17 +36,25 @@ type EtcdKVStore interface {
 	GetKeyNodes(key string) (client.Node, error)
 	GetKeyNodesRecursively(key string) (client.Node, error)
 	Create(key string, value interface{}) error
+	CreateWithTTL(key string, value interface{}, ttl time.Duration) error
 	CreateDir(key string) error
 	AddOrUpdate(key string, value interface{}) error
+	AddOrUpdateWithTTL(key string, value interface{}, ttl time.Duration) error
 	AddOrUpdateDir(key string) error
 	Update(key string, value, prevValue interface{}, prevIndex uint64) error
 	Delete(key string, prevIndex uint64) error
 	DeleteDir(key string) error
+	DeleteEmptyDir(key string) error
 	GetLongPollWatcherForKey(key string, monitorSubNodes bool, afterIndex uint64) (client.Watcher, error)
+	Exists(key string, timeout time.Duration) (bool, error)
+	GetMembersHealth(timeout time.Duration) ([]MemberHealth, error)
+	AddOrUpdateWithTimeout(key string, value interface{}, ttl, timeout time.Duration) error
+	GetKeyIntoStructWithTimeout(key string, result interface{}, timeout time.Duration) error
 }
 
 type EtcdConnector struct {
 	addresses []string
+	client    client.Client
 	keysAPI   client.KeysAPI
 }
 
@@ -74,6 +82,7 @@ func (c *EtcdConnector) Connect() error {
 		err := fmt.Errorf("connection error: %v", err)
 		return err
 	}
+	c.client = newClient
 	c.keysAPI = client.NewKeysAPI(newClient)
 	return nil
 }
@@ -90,7 +99,7 @@ func (c *EtcdConnector) GetKeyIntoStruct(key string, result interface{}) error {
 
 	resp, err := c.keysAPI.Get(context.Background(), key, nil)
 	if err != nil {
-		return fmt.Errorf("getting key %q error: %v", key, err)
+		return wrapClientError(err, "getting key %q error: %v", key)
 	}
 	return json.Unmarshal([]byte(resp.Node.Value), result)
 }
@@ -116,6 +125,15 @@ func (c *EtcdConnector) Create(key string, value interface{}) error {
 	return c.set(key, value, options)
 }
 
+// CreateWithTTL creates key which is removed by etcd after ttl
+func (c *EtcdConnector) CreateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	logger.Debug("Creating value of key: ", key)
+
+	options := &client.SetOptions{PrevExist: client.PrevNoExist, TTL: ttl}
+
+	return c.set(key, value, options)
+}
+
 func (c *EtcdConnector) CreateDir(key string) error {
 	logger.Debug("Creating value of key: ", key)
 
@@ -132,6 +150,14 @@ func (c *EtcdConnector) AddOrUpdate(key string, value interface{}) error {
 	return c.set(key, value, options)
 }
 
+func (c *EtcdConnector) AddOrUpdateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	logger.Debug("Setting value of key: ", key)
+
+	options := &client.SetOptions{PrevExist: client.PrevIgnore, TTL: ttl}
+
+	return c.set(key, value, options)
+}
+
 func (c *EtcdConnector) Update(key string, value, prevValue interface{}, prevIndex uint64) error {
 	logger.Debug("Updating value of key: ", key)
 
@@ -168,8 +194,7 @@ func (c *EtcdConnector) set(key string, value interface{}, options *client.SetOp
 
 	_, err = c.keysAPI.Set(context.Background(), key, string(valueByte), options)
 	if err != nil {
-		err = fmt.Errorf("setting key %s error: %v", key, err)
-		return err
+		return wrapClientError(err, "setting key %s error: %v", key)
 	}
 	return nil
 }
@@ -191,11 +216,17 @@ func (c *EtcdConnector) DeleteDir(key string) error {
 	return c.delete(key, &options)
 }
 
+// DeleteEmptyDir fails if directory is not empty, so keys added to it concurrently are not lost
+func (c *EtcdConnector) DeleteEmptyDir(key string) error {
+	options := client.DeleteOptions{Dir: true}
+	return c.delete(key, &options)
+}
+
 func (c *EtcdConnector) AddOrUpdateDir(key string) error {
 	logger.Debugf("Adding or updating directory of key %s", key)
 
 	if _, err := c.keysAPI.Set(context.Background(), key, "", &client.SetOptions{Dir: true, PrevExist: client.PrevIgnore}); err != nil {
-		return fmt.Errorf("setting key value error: %v", err)
+		return wrapClientError(err, "setting key value error: %v")
 	}
 	return nil
 }
@@ -205,7 +236,7 @@ func (c *EtcdConnector) delete(key string, options *client.DeleteOptions) error
 
 	_, err := c.keysAPI.Delete(context.Background(), key, options)
 	if err != nil {
-		return fmt.Errorf("getting key value error: %v", err)
+		return wrapClientError(err, "getting key value error: %v")
 	}
 	return nil
 }
@@ -216,7 +247,7 @@ func (c *EtcdConnector) getKeyNodes(key string, getOptions client.GetOptions) (c
 	resultNode := client.Node{}
 	resp, err := c.keysAPI.Get(context.Background(), key, &getOptions)
 	if err != nil {
-		return resultNode, fmt.Errorf("getting key %q error: %v", key, err)
+		return resultNode, wrapClientError(err, "getting key %q error: %v", key)
 	}
 
 	return *resp.Node, nil
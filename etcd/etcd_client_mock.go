@@ -21,6 +21,7 @@ package etcd
 import (
 	client "github.com/coreos/etcd/client"
 	gomock "github.com/golang/mock/gomock"
+	time "time"
 )
 
 // Mock of EtcdKVStore interface
@@ -118,6 +119,16 @@ func (_mr *_MockEtcdKVStoreRecorder) Create(arg0, arg1 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Create", arg0, arg1)
 }
 
+func (_m *MockEtcdKVStore) CreateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "CreateWithTTL", key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) CreateWithTTL(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CreateWithTTL", arg0, arg1, arg2)
+}
+
 func (_m *MockEtcdKVStore) CreateDir(key string) error {
 	ret := _m.ctrl.Call(_m, "CreateDir", key)
 	ret0, _ := ret[0].(error)
@@ -138,6 +149,16 @@ func (_mr *_MockEtcdKVStoreRecorder) AddOrUpdate(arg0, arg1 interface{}) *gomock
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddOrUpdate", arg0, arg1)
 }
 
+func (_m *MockEtcdKVStore) AddOrUpdateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	ret := _m.ctrl.Call(_m, "AddOrUpdateWithTTL", key, value, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) AddOrUpdateWithTTL(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddOrUpdateWithTTL", arg0, arg1, arg2)
+}
+
 func (_m *MockEtcdKVStore) AddOrUpdateDir(key string) error {
 	ret := _m.ctrl.Call(_m, "AddOrUpdateDir", key)
 	ret0, _ := ret[0].(error)
@@ -178,6 +199,16 @@ func (_mr *_MockEtcdKVStoreRecorder) DeleteDir(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteDir", arg0)
 }
 
+func (_m *MockEtcdKVStore) DeleteEmptyDir(key string) error {
+	ret := _m.ctrl.Call(_m, "DeleteEmptyDir", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) DeleteEmptyDir(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteEmptyDir", arg0)
+}
+
 func (_m *MockEtcdKVStore) GetLongPollWatcherForKey(key string, monitorSubNodes bool, afterIndex uint64) (client.Watcher, error) {
 	ret := _m.ctrl.Call(_m, "GetLongPollWatcherForKey", key, monitorSubNodes, afterIndex)
 	ret0, _ := ret[0].(client.Watcher)
@@ -188,3 +219,45 @@ func (_m *MockEtcdKVStore) GetLongPollWatcherForKey(key string, monitorSubNodes
 func (_mr *_MockEtcdKVStoreRecorder) GetLongPollWatcherForKey(arg0, arg1, arg2 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetLongPollWatcherForKey", arg0, arg1, arg2)
 }
+
+func (_m *MockEtcdKVStore) Exists(key string, timeout time.Duration) (bool, error) {
+	ret := _m.ctrl.Call(_m, "Exists", key, timeout)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) Exists(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Exists", arg0, arg1)
+}
+
+func (_m *MockEtcdKVStore) GetMembersHealth(timeout time.Duration) ([]MemberHealth, error) {
+	ret := _m.ctrl.Call(_m, "GetMembersHealth", timeout)
+	ret0, _ := ret[0].([]MemberHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) GetMembersHealth(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetMembersHealth", arg0)
+}
+
+func (_m *MockEtcdKVStore) AddOrUpdateWithTimeout(key string, value interface{}, ttl time.Duration, timeout time.Duration) error {
+	ret := _m.ctrl.Call(_m, "AddOrUpdateWithTimeout", key, value, ttl, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) AddOrUpdateWithTimeout(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddOrUpdateWithTimeout", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockEtcdKVStore) GetKeyIntoStructWithTimeout(key string, result interface{}, timeout time.Duration) error {
+	ret := _m.ctrl.Call(_m, "GetKeyIntoStructWithTimeout", key, result, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockEtcdKVStoreRecorder) GetKeyIntoStructWithTimeout(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetKeyIntoStructWithTimeout", arg0, arg1, arg2)
+}
@@ -146,6 +146,21 @@ func TestGetKeyNodes(t *testing.T) {
 	})
 }
 
+func TestClientError(t *testing.T) {
+	etcdKVStore, keysAPI := prepareEtcdKVStoreAndKeysAPIMock(t)
+	Convey("Test GetKeyNodes in case key is missing, error of etcd client should be kept", t, func() {
+		getOptions := client.GetOptions{Recursive: false, Sort: true}
+		keysAPI.EXPECT().Get(gomock.Any(), key1, &getOptions).Return(nil, client.Error{Code: client.ErrorCodeKeyNotFound})
+
+		_, err := etcdKVStore.GetKeyNodes(key1)
+
+		Convey("err should contain key and be recognized as missing key", func() {
+			So(err.Error(), ShouldContainSubstring, key1)
+			So(client.IsKeyNotFound(ClientError(err)), ShouldBeTrue)
+		})
+	})
+}
+
 func TestGetKeyNodesRecursively(t *testing.T) {
 	etcdKVStore, keysAPI := prepareEtcdKVStoreAndKeysAPIMock(t)
 	Convey("Test GetKeyNodesRecursively provided with proper key", t, func() {
@@ -0,0 +1,42 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import "fmt"
+
+// clientError adds context to error of etcd client and keeps the error itself, so that it can still be checked
+// with etcd client functions like client.IsKeyNotFound
+type clientError struct {
+	message string
+	err     error
+}
+
+func (e clientError) Error() string {
+	return e.message
+}
+
+func wrapClientError(err error, format string, args ...interface{}) error {
+	return clientError{message: fmt.Sprintf(format, append(args, err)...), err: err}
+}
+
+// ClientError returns error of etcd client wrapped by EtcdConnector, other errors are returned unchanged
+func ClientError(err error) error {
+	if wrapped, ok := err.(clientError); ok {
+		return wrapped.err
+	}
+	return err
+}
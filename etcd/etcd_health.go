@@ -0,0 +1,126 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package etcd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/client"
+	"golang.org/x/net/context"
+)
+
+// MemberHealth is health of single etcd cluster member as reported by its /health endpoint
+type MemberHealth struct {
+	Id         string
+	Name       string
+	ClientURLs []string
+	Healthy    bool
+	Error      string
+}
+
+// Exists checks presence of the key, failing if etcd does not answer within timeout
+func (c *EtcdConnector) Exists(key string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, err := c.keysAPI.Get(ctx, key, &client.GetOptions{}); err != nil {
+		if client.IsKeyNotFound(err) {
+			return false, nil
+		}
+		return false, wrapClientError(err, "getting key %q error: %v", key)
+	}
+	return true, nil
+}
+
+// AddOrUpdateWithTimeout sets value of the key expiring after ttl, failing if etcd does not answer within timeout
+func (c *EtcdConnector) AddOrUpdateWithTimeout(key string, value interface{}, ttl, timeout time.Duration) error {
+	valueByte, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot marshal etcd key value: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	options := &client.SetOptions{PrevExist: client.PrevIgnore, TTL: ttl}
+	if _, err := c.keysAPI.Set(ctx, key, string(valueByte), options); err != nil {
+		return wrapClientError(err, "setting key %s error: %v", key)
+	}
+	return nil
+}
+
+// GetKeyIntoStructWithTimeout reads value of the key, failing if etcd does not answer within timeout
+func (c *EtcdConnector) GetKeyIntoStructWithTimeout(key string, result interface{}, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := c.keysAPI.Get(ctx, key, nil)
+	if err != nil {
+		return wrapClientError(err, "getting key %q error: %v", key)
+	}
+	return json.Unmarshal([]byte(resp.Node.Value), result)
+}
+
+func (c *EtcdConnector) GetMembersHealth(timeout time.Duration) ([]MemberHealth, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	members, err := client.NewMembersAPI(c.client).List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster members error: %v", err)
+	}
+
+	httpClient := &http.Client{Transport: client.DefaultTransport, Timeout: timeout}
+	result := []MemberHealth{}
+	for _, member := range members {
+		health := MemberHealth{Id: member.ID, Name: member.Name, ClientURLs: member.ClientURLs}
+		if err := checkMemberHealth(httpClient, member.ClientURLs); err != nil {
+			health.Error = err.Error()
+		} else {
+			health.Healthy = true
+		}
+		result = append(result, health)
+	}
+	return result, nil
+}
+
+func checkMemberHealth(httpClient *http.Client, clientURLs []string) error {
+	if len(clientURLs) == 0 {
+		return errors.New("member has no client URLs")
+	}
+
+	resp, err := httpClient.Get(strings.TrimSuffix(clientURLs[0], "/") + "/health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	health := struct {
+		Health string `json:"health"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return fmt.Errorf("cannot decode health response: %v", err)
+	}
+	if health.Health != "true" {
+		return fmt.Errorf("member reports health %q", health.Health)
+	}
+	return nil
+}
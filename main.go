@@ -49,6 +49,7 @@ func main() {
 	r := setupRouter(context)
 
 	startMetrics(repository)
+	go context.SweepTrashPeriodically()
 
 	httpGoCommon.StartServer(r)
 }
@@ -77,7 +78,7 @@ func setupRepository() data.RepositoryApi {
 	if err != nil {
 		logger.Fatalf("Cannot connect to ETCD on %s: %v", etcdAddresses, err)
 	}
-	return data.NewRepositoryAPI(etcdKVStore, data.DataMapper{})
+	return data.NewRepositoryAPI(metrics.InstrumentEtcdKVStore(etcdKVStore), data.DataMapper{})
 }
 
 func getDefaultOrganization() string {
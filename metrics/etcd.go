@@ -0,0 +1,215 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package metrics
+
+import (
+	"time"
+
+	"github.com/coreos/etcd/client"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trustedanalytics-ng/tap-catalog/etcd"
+)
+
+var etcdOperationDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "etcd_operation_duration_seconds",
+		Help:      "Duration of etcd operations",
+	}, []string{"operation"})
+
+var etcdOperationErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "etcd_operation_errors_total",
+		Help:      "Count of failed etcd operations - missing keys are not counted",
+	}, []string{"operation"})
+
+var watchesStarted = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "watches_started_total",
+		Help:      "Count of long-poll watches started on etcd",
+	})
+
+type instrumentedEtcdKVStore struct {
+	store etcd.EtcdKVStore
+}
+
+// InstrumentEtcdKVStore wraps store, so that latency and errors of its operations are observed
+func InstrumentEtcdKVStore(store etcd.EtcdKVStore) etcd.EtcdKVStore {
+	return &instrumentedEtcdKVStore{store: store}
+}
+
+func observeEtcdOperation(operation string, startTime time.Time, err error) {
+	etcdOperationDuration.WithLabelValues(operation).Observe(time.Since(startTime).Seconds())
+	if err != nil && !isKeyNotFound(err) {
+		etcdOperationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func isKeyNotFound(err error) bool {
+	return client.IsKeyNotFound(etcd.ClientError(err))
+}
+
+func (s *instrumentedEtcdKVStore) Connect() error {
+	return s.store.Connect()
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyValue(key string) (string, error) {
+	startTime := time.Now()
+	result, err := s.store.GetKeyValue(key)
+	observeEtcdOperation("get", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyIntoStruct(key string, result interface{}) error {
+	startTime := time.Now()
+	err := s.store.GetKeyIntoStruct(key, result)
+	observeEtcdOperation("get", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyRawResponse(key string) (*client.Response, error) {
+	startTime := time.Now()
+	result, err := s.store.GetKeyRawResponse(key)
+	observeEtcdOperation("get_raw", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyNodes(key string) (client.Node, error) {
+	startTime := time.Now()
+	result, err := s.store.GetKeyNodes(key)
+	observeEtcdOperation("get_nodes", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyNodesRecursively(key string) (client.Node, error) {
+	startTime := time.Now()
+	result, err := s.store.GetKeyNodesRecursively(key)
+	observeEtcdOperation("get_nodes_recursive", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) Create(key string, value interface{}) error {
+	startTime := time.Now()
+	err := s.store.Create(key, value)
+	observeEtcdOperation("create", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) CreateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	startTime := time.Now()
+	err := s.store.CreateWithTTL(key, value, ttl)
+	observeEtcdOperation("create", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) CreateDir(key string) error {
+	startTime := time.Now()
+	err := s.store.CreateDir(key)
+	observeEtcdOperation("create_dir", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) AddOrUpdate(key string, value interface{}) error {
+	startTime := time.Now()
+	err := s.store.AddOrUpdate(key, value)
+	observeEtcdOperation("set", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) AddOrUpdateWithTTL(key string, value interface{}, ttl time.Duration) error {
+	startTime := time.Now()
+	err := s.store.AddOrUpdateWithTTL(key, value, ttl)
+	observeEtcdOperation("set", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) AddOrUpdateDir(key string) error {
+	startTime := time.Now()
+	err := s.store.AddOrUpdateDir(key)
+	observeEtcdOperation("set_dir", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) Update(key string, value, prevValue interface{}, prevIndex uint64) error {
+	startTime := time.Now()
+	err := s.store.Update(key, value, prevValue, prevIndex)
+	observeEtcdOperation("update", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) Delete(key string, prevIndex uint64) error {
+	startTime := time.Now()
+	err := s.store.Delete(key, prevIndex)
+	observeEtcdOperation("delete", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) DeleteDir(key string) error {
+	startTime := time.Now()
+	err := s.store.DeleteDir(key)
+	observeEtcdOperation("delete_dir", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) DeleteEmptyDir(key string) error {
+	startTime := time.Now()
+	err := s.store.DeleteEmptyDir(key)
+	observeEtcdOperation("delete_empty_dir", startTime, err)
+	return err
+}
+
+// GetLongPollWatcherForKey only creates watcher - time of waiting for changes is not observed
+func (s *instrumentedEtcdKVStore) GetLongPollWatcherForKey(key string, monitorSubNodes bool, afterIndex uint64) (client.Watcher, error) {
+	watcher, err := s.store.GetLongPollWatcherForKey(key, monitorSubNodes, afterIndex)
+	if err == nil {
+		watchesStarted.Inc()
+	}
+	return watcher, err
+}
+
+func (s *instrumentedEtcdKVStore) Exists(key string, timeout time.Duration) (bool, error) {
+	startTime := time.Now()
+	result, err := s.store.Exists(key, timeout)
+	observeEtcdOperation("exists", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) GetMembersHealth(timeout time.Duration) ([]etcd.MemberHealth, error) {
+	startTime := time.Now()
+	result, err := s.store.GetMembersHealth(timeout)
+	observeEtcdOperation("members_health", startTime, err)
+	return result, err
+}
+
+func (s *instrumentedEtcdKVStore) AddOrUpdateWithTimeout(key string, value interface{}, ttl, timeout time.Duration) error {
+	startTime := time.Now()
+	err := s.store.AddOrUpdateWithTimeout(key, value, ttl, timeout)
+	observeEtcdOperation("set", startTime, err)
+	return err
+}
+
+func (s *instrumentedEtcdKVStore) GetKeyIntoStructWithTimeout(key string, result interface{}, timeout time.Duration) error {
+	startTime := time.Now()
+	err := s.store.GetKeyIntoStructWithTimeout(key, result, timeout)
+	observeEtcdOperation("get", startTime, err)
+	return err
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gocraft/web"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const unmatchedRoute = "unmatched"
+
+var httpRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route and response status",
+	}, []string{"method", "route", "status"})
+
+// HttpMiddleware observes duration and status of requests. Route is the registered path (e.g. /api/v1/services/:serviceId),
+// so label values do not grow with ids of entities.
+func HttpMiddleware(rw web.ResponseWriter, req *web.Request, next web.NextMiddlewareFunc) {
+	startTime := time.Now()
+
+	next(rw, req)
+
+	route := req.RoutePath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	status := rw.StatusCode()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	httpRequestDuration.WithLabelValues(req.Method, route, strconv.Itoa(status)).Observe(time.Since(startTime).Seconds())
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -47,27 +48,140 @@ var tapCounts = prometheus.NewGaugeVec(
 		Help:      "Count of various TAP components",
 	}, []string{"component", "organization"})
 
+var instanceCounts = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "instances",
+		Help:      "Count of instances by type, state and offering (ClassId)",
+	}, []string{"organization", "type", "state", "class_id"})
+
+var imageCounts = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "images",
+		Help:      "Count of images by type and state",
+	}, []string{"organization", "type", "state"})
+
+var templateCounts = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "templates",
+		Help:      "Count of templates by state",
+	}, []string{"organization", "state"})
+
+var serviceCounts = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "services",
+		Help:      "Count of services (offerings) by state",
+	}, []string{"organization", "state"})
+
 var repository data.RepositoryApi
 
-func collectInstancesCount(org string) (runningApplications float64, downApplications float64,
-	runningServiceInstances float64, downServiceInstances float64, err error) {
-	runningApplications = float64(0)
-	downApplications = float64(0)
-	runningServiceInstances = float64(0)
-	downServiceInstances = float64(0)
+// orgEntities are entities of organization read in single collecting round
+type orgEntities struct {
+	org       string
+	instances []models.Instance
+	images    []models.Image
+	templates []models.Template
+	services  []models.Service
+}
 
+func getInstances(org string) ([]models.Instance, error) {
 	result, err := repository.GetListOfData(data.GetEntityKey(org, data.Instances), models.Instance{})
 	if err != nil {
-		return
+		return nil, err
 	}
+
+	instances := []models.Instance{}
 	for _, el := range result {
 		instance, ok := el.(models.Instance)
 		if !ok {
 			elementType := reflect.TypeOf(el).String()
-			err = fmt.Errorf("Cannot convert element to models.Instance, element type was: %v", elementType)
-			return
+			return nil, fmt.Errorf("Cannot convert element to models.Instance, element type was: %v", elementType)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func getImages(org string) ([]models.Image, error) {
+	result, err := repository.GetListOfData(data.GetEntityKey(org, data.Images), models.Image{})
+	if err != nil {
+		return nil, err
+	}
+
+	images := []models.Image{}
+	for _, el := range result {
+		image, ok := el.(models.Image)
+		if !ok {
+			elementType := reflect.TypeOf(el).String()
+			return nil, fmt.Errorf("Cannot convert element to models.Image, element type was: %v", elementType)
 		}
+		images = append(images, image)
+	}
+	return images, nil
+}
 
+func getTemplates(org string) ([]models.Template, error) {
+	result, err := repository.GetListOfData(data.GetEntityKey(org, data.Templates), models.Template{})
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []models.Template{}
+	for _, el := range result {
+		template, ok := el.(models.Template)
+		if !ok {
+			elementType := reflect.TypeOf(el).String()
+			return nil, fmt.Errorf("Cannot convert element to models.Template, element type was: %v", elementType)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func getServices(org string) ([]models.Service, error) {
+	result, err := repository.GetListOfData(data.GetEntityKey(org, data.Services), models.Service{})
+	if err != nil {
+		return nil, err
+	}
+
+	services := []models.Service{}
+	for _, el := range result {
+		service, ok := el.(models.Service)
+		if !ok {
+			elementType := reflect.TypeOf(el).String()
+			return nil, fmt.Errorf("Cannot convert element to models.Service, element type was: %v", elementType)
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+func getOrgEntities(org string) (orgEntities, error) {
+	var err error
+	entities := orgEntities{org: org}
+	if entities.instances, err = getInstances(org); err != nil {
+		return entities, err
+	}
+	if entities.images, err = getImages(org); err != nil {
+		return entities, err
+	}
+	if entities.templates, err = getTemplates(org); err != nil {
+		return entities, err
+	}
+	entities.services, err = getServices(org)
+	return entities, err
+}
+
+func collectInstancesCount(instances []models.Instance) (runningApplications float64, downApplications float64,
+	runningServiceInstances float64, downServiceInstances float64) {
+	for _, instance := range instances {
 		if data.IsInstanceTypeOf(instance, models.InstanceTypeApplication) {
 			if data.IsRunningInstance(instance) {
 				runningApplications = runningApplications + 1
@@ -82,7 +196,6 @@ func collectInstancesCount(org string) (runningApplications float64, downApplica
 			}
 		}
 	}
-
 	return
 }
 
@@ -94,16 +207,71 @@ func collectServicesCount(org string) (string, float64, error) {
 	return servicesMetricName, float64(servicesCount), nil
 }
 
+// labelCounts counts entities by values of gauge labels
+type labelCounts map[string]*labelCount
+
+type labelCount struct {
+	labelValues []string
+	value       float64
+}
+
+func (c labelCounts) inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\x00")
+	if count, ok := c[key]; ok {
+		count.value++
+		return
+	}
+	c[key] = &labelCount{labelValues: labelValues, value: 1}
+}
+
+// replace sets gauge to the counts, dropping label values which are not counted anymore
+func (c labelCounts) replace(gauge *prometheus.GaugeVec) {
+	gauge.Reset()
+	for _, count := range c {
+		gauge.WithLabelValues(count.labelValues...).Set(count.value)
+	}
+}
+
+// setStateCounts replaces state gauges of all organizations, so that states no entity is in anymore are not reported.
+// Counts are computed before gauges are touched, so a scrape can not see them partially counted.
+func setStateCounts(organizations []orgEntities) {
+	instances, images, templates, services := labelCounts{}, labelCounts{}, labelCounts{}, labelCounts{}
+	for _, entities := range organizations {
+		for _, instance := range entities.instances {
+			instances.inc(entities.org, string(instance.Type), instance.State.String(), instance.ClassId)
+		}
+		for _, image := range entities.images {
+			images.inc(entities.org, string(image.Type), string(image.State))
+		}
+		for _, template := range entities.templates {
+			templates.inc(entities.org, string(template.State))
+		}
+		for _, service := range entities.services {
+			services.inc(entities.org, string(service.State))
+		}
+	}
+
+	instances.replace(instanceCounts)
+	images.replace(imageCounts)
+	templates.replace(templateCounts)
+	services.replace(serviceCounts)
+}
+
 func collectCount() error {
 	organizations, err := getAllOrgs()
 	if err != nil {
 		return err
 	}
+
+	allEntities := []orgEntities{}
 	for _, org := range organizations {
-		runningApplications, downApplications, runningServiceInstances, downServiceInstances, err := collectInstancesCount(org)
+		entities, err := getOrgEntities(org)
 		if err != nil {
 			return err
 		}
+		allEntities = append(allEntities, entities)
+
+		runningApplications, downApplications, runningServiceInstances, downServiceInstances := collectInstancesCount(entities.instances)
 		tapCounts.WithLabelValues(applicationsMetricName, org).Set(float64(runningApplications + downApplications))
 		tapCounts.WithLabelValues(applicationsRunningMetricName, org).Set(runningApplications)
 		tapCounts.WithLabelValues(applicationsDownMetricName, org).Set(downApplications)
@@ -118,12 +286,35 @@ func collectCount() error {
 		}
 		tapCounts.WithLabelValues(metricName, org).Set(metricValue)
 	}
+	setStateCounts(allEntities)
 	return nil
 }
 
 func EnableCollection(repo data.RepositoryApi, delay time.Duration) chan<- struct{} {
 	repository = repo
-	mutils.RegisterMetrics("catalog", tapCounts)
+	activeWatches := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "tap",
+			Subsystem: "catalog",
+			Name:      "watches_active",
+			Help:      "Count of long-poll watches currently waiting for changes in etcd",
+		}, func() float64 {
+			return float64(repo.GetOpenWatchesCount())
+		})
+
+	mutils.RegisterMetrics("catalog",
+		tapCounts,
+		instanceCounts,
+		imageCounts,
+		templateCounts,
+		serviceCounts,
+		httpRequestDuration,
+		etcdOperationDuration,
+		etcdOperationErrors,
+		watchesStarted,
+		activeWatches,
+		stateTransitions,
+	)
 	return mutils.EnableMetricsCollecting(delay,
 		collectCount,
 	)
@@ -16,13 +16,166 @@
 package metrics
 
 import (
-	. "github.com/smartystreets/goconvey/convey"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
+
+	"github.com/coreos/etcd/client"
+	"github.com/gocraft/web"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-catalog/data"
+	"github.com/trustedanalytics-ng/tap-catalog/etcd"
+	"github.com/trustedanalytics-ng/tap-catalog/models"
 )
 
+const testOrg = "org"
+
+type testContext struct{}
+
 func TestGetAllOrgs(t *testing.T) {
 	Convey("Test getAllOrgs should return error for not defined env value", t, func() {
 		_, err := getAllOrgs()
 		So(err.Error(), ShouldEqual, "CORE_ORGANIZATION env is empty")
 	})
 }
+
+func TestCollectCount(t *testing.T) {
+	Convey("Testing collectCount", t, func() {
+		mockCtrl := gomock.NewController(t)
+		repositoryMock := data.NewMockRepositoryApi(mockCtrl)
+		repository = repositoryMock
+		os.Setenv("CORE_ORGANIZATION", testOrg)
+
+		instances := []interface{}{
+			models.Instance{Type: models.InstanceTypeService, ClassId: "offering", State: models.InstanceStateRunning},
+			models.Instance{Type: models.InstanceTypeService, ClassId: "offering", State: models.InstanceStateRunning},
+			models.Instance{Type: models.InstanceTypeApplication, ClassId: "app", State: models.InstanceStateDeploying},
+		}
+		images := []interface{}{models.Image{Type: models.ImageTypeJava, State: models.ImageStateReady}}
+		templates := []interface{}{models.Template{State: models.TemplateStateInProgress}}
+		services := []interface{}{models.Service{State: models.ServiceStateReady}}
+
+		repositoryMock.EXPECT().GetListOfData(data.GetEntityKey(testOrg, data.Instances), models.Instance{}).Return(instances, nil)
+		repositoryMock.EXPECT().GetListOfData(data.GetEntityKey(testOrg, data.Images), models.Image{}).Return(images, nil)
+		repositoryMock.EXPECT().GetListOfData(data.GetEntityKey(testOrg, data.Templates), models.Template{}).Return(templates, nil)
+		repositoryMock.EXPECT().GetListOfData(data.GetEntityKey(testOrg, data.Services), models.Service{}).Return(services, nil)
+		repositoryMock.EXPECT().GetDataCounter(data.GetEntityKey(testOrg, data.Services), models.Service{}).Return(4, nil)
+
+		err := collectCount()
+
+		Convey("instances should be counted by type, state and offering", func() {
+			So(err, ShouldBeNil)
+			So(getGaugeValue(instanceCounts.WithLabelValues(testOrg, "SERVICE", "RUNNING", "offering")), ShouldEqual, 2)
+			So(getGaugeValue(instanceCounts.WithLabelValues(testOrg, "APPLICATION", "DEPLOYING", "app")), ShouldEqual, 1)
+		})
+		Convey("images, templates and services should be counted by state", func() {
+			So(getGaugeValue(imageCounts.WithLabelValues(testOrg, string(models.ImageTypeJava), "READY")), ShouldEqual, 1)
+			So(getGaugeValue(templateCounts.WithLabelValues(testOrg, "IN_PROGRESS")), ShouldEqual, 1)
+			So(getGaugeValue(serviceCounts.WithLabelValues(testOrg, "READY")), ShouldEqual, 1)
+		})
+		Convey("legacy counts should be kept", func() {
+			So(getGaugeValue(tapCounts.WithLabelValues(servicesInstancesRunningMetricName, testOrg)), ShouldEqual, 2)
+			So(getGaugeValue(tapCounts.WithLabelValues(applicationsDownMetricName, testOrg)), ShouldEqual, 1)
+			So(getGaugeValue(tapCounts.WithLabelValues(servicesMetricName, testOrg)), ShouldEqual, 4)
+		})
+
+		Reset(func() {
+			os.Unsetenv("CORE_ORGANIZATION")
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestSetStateCounts(t *testing.T) {
+	Convey("Testing setStateCounts", t, func() {
+		stopped := models.Instance{Type: models.InstanceTypeService, ClassId: "offering", State: models.InstanceStateStopped}
+		running := stopped
+		running.State = models.InstanceStateRunning
+		setStateCounts([]orgEntities{{org: testOrg, instances: []models.Instance{stopped, stopped}}})
+
+		Convey("When state is left by all entities, its count should be dropped", func() {
+			setStateCounts([]orgEntities{{org: testOrg, instances: []models.Instance{running}}})
+
+			So(getMetricsCount(instanceCounts), ShouldEqual, 1)
+			So(getGaugeValue(instanceCounts.WithLabelValues(testOrg, "SERVICE", "RUNNING", "offering")), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestHttpMiddleware(t *testing.T) {
+	Convey("Testing HttpMiddleware", t, func() {
+		router := web.New(testContext{})
+		router.Middleware(HttpMiddleware)
+		router.Get("/things/:thingId", func(rw web.ResponseWriter, req *web.Request) {
+			rw.WriteHeader(http.StatusNoContent)
+		})
+
+		Convey("Request should be observed with route and status", func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/things/1", nil))
+
+			histogram := httpRequestDuration.WithLabelValues("GET", "/things/:thingId", "204")
+			So(getHistogramCount(histogram), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestInstrumentedEtcdKVStore(t *testing.T) {
+	Convey("Testing instrumented etcd store", t, func() {
+		mockCtrl := gomock.NewController(t)
+		etcdMock := etcd.NewMockEtcdKVStore(mockCtrl)
+		store := InstrumentEtcdKVStore(etcdMock)
+
+		Convey("Missing key should not be counted as error", func() {
+			before := getCounterValue(etcdOperationErrors.WithLabelValues("get_nodes"))
+			etcdMock.EXPECT().GetKeyNodes("key").Return(client.Node{}, client.Error{Code: client.ErrorCodeKeyNotFound})
+
+			store.GetKeyNodes("key")
+
+			So(getCounterValue(etcdOperationErrors.WithLabelValues("get_nodes")), ShouldEqual, before)
+		})
+
+		Convey("Failed operation should be counted as error", func() {
+			before := getCounterValue(etcdOperationErrors.WithLabelValues("delete"))
+			etcdMock.EXPECT().Delete("key", uint64(0)).Return(errors.New("connection refused"))
+
+			store.Delete("key", 0)
+
+			So(getCounterValue(etcdOperationErrors.WithLabelValues("delete")), ShouldEqual, before+1)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func getGaugeValue(gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	gauge.Write(metric)
+	return metric.GetGauge().GetValue()
+}
+
+func getMetricsCount(collector prometheus.Collector) int {
+	metrics := make(chan prometheus.Metric, 100)
+	collector.Collect(metrics)
+	close(metrics)
+	return len(metrics)
+}
+
+func getCounterValue(counter prometheus.Counter) float64 {
+	metric := &dto.Metric{}
+	counter.Write(metric)
+	return metric.GetCounter().GetValue()
+}
+
+func getHistogramCount(histogram prometheus.Histogram) uint64 {
+	metric := &dto.Metric{}
+	histogram.Write(metric)
+	return metric.GetHistogram().GetSampleCount()
+}
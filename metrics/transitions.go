@@ -0,0 +1,34 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+var stateTransitions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "tap",
+		Subsystem: "catalog",
+		Name:      "state_transitions_total",
+		Help:      "Count of accepted state changes of entities",
+	}, []string{"entity_type", "from", "to"})
+
+func RecordStateTransition(entityType models.EntityType, from, to string) {
+	stateTransitions.WithLabelValues(string(entityType), from, to).Inc()
+}
@@ -0,0 +1,188 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	RedactedValue = "[REDACTED]"
+
+	// ActingUserHeader gives user on whose behalf the request is sent, e.g. by a gateway. It is not verified,
+	// so it is recorded in audit log and trash next to the user of basic authorization, never instead of it.
+	ActingUserHeader = "X-Acting-User"
+)
+
+// secretNameParts are parts of field, metadata and binding data names which values are not kept in audit log,
+// e.g. "pass" covers DB_PASS and PASSWORD, "key" covers ACCESS_KEY and API_KEY
+var secretNameParts = []string{"pass", "pwd", "secret", "token", "credential", "key"}
+
+// AuditEntry is appended to audit log of organization on every create, update and delete.
+// ParentId is set only for nested entities (service id for plans).
+type AuditEntry struct {
+	Timestamp  int64           `json:"timestamp"`
+	User       string          `json:"user"`
+	OnBehalfOf string          `json:"onBehalfOf,omitempty"`
+	Action     ChangeEventType `json:"action"`
+	EntityType EntityType      `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	ParentId   string          `json:"parentId,omitempty"`
+	Changes    []FieldChange   `json:"changes"`
+}
+
+// FieldChange describes change of top-level field - OldValue is empty for created and NewValue for removed ones
+type FieldChange struct {
+	Field    string          `json:"field"`
+	OldValue json.RawMessage `json:"oldValue,omitempty"`
+	NewValue json.RawMessage `json:"newValue,omitempty"`
+}
+
+// AuditFilter selects audit entries, Since and Until are unix timestamps in nanoseconds and zero means no limit.
+// Limit is the maximal number of returned entries, zero means all of them.
+type AuditFilter struct {
+	EntityType EntityType
+	EntityId   string
+	User       string
+	Since      int64
+	Until      int64
+	Limit      int
+}
+
+func (filter AuditFilter) Matches(entry AuditEntry) bool {
+	switch {
+	case filter.EntityType != "" && filter.EntityType != entry.EntityType:
+		return false
+	case filter.EntityId != "" && filter.EntityId != entry.EntityId:
+		return false
+	case filter.User != "" && filter.User != entry.User:
+		return false
+	case filter.Since != 0 && entry.Timestamp < filter.Since:
+		return false
+	case filter.Until != 0 && entry.Timestamp > filter.Until:
+		return false
+	}
+	return true
+}
+
+// DiffEntities returns changed top-level fields of the entity with secrets redacted, before or after can be nil
+// for created or removed entity. AuditTrail is not compared as it changes on every update.
+func DiffEntities(before, after interface{}) ([]FieldChange, error) {
+	beforeFields, err := toJsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toJsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		if name == "auditTrail" || bytes.Equal(beforeFields[name], afterFields[name]) {
+			continue
+		}
+
+		change := FieldChange{Field: name}
+		if change.OldValue, err = redactJsonValue(name, beforeFields[name]); err != nil {
+			return nil, err
+		}
+		if change.NewValue, err = redactJsonValue(name, afterFields[name]); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func toJsonFields(entity interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if entity == nil {
+		return fields, nil
+	}
+
+	entityBytes, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(entityBytes, &fields)
+	return fields, err
+}
+
+func redactJsonValue(name string, value json.RawMessage) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactSecrets(name, decoded))
+}
+
+// redactSecrets replaces values of secret names, including metadata value of secret key ({"key": ..., "value": ...})
+func redactSecrets(name string, value interface{}) interface{} {
+	if IsSecretName(name) {
+		return RedactedValue
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		key, isMetadata := typed["key"].(string)
+		for fieldName, fieldValue := range typed {
+			if isMetadata && fieldName == "key" {
+				// name of metadata is kept, it is the value which can be secret
+				continue
+			}
+			if isMetadata && fieldName == "value" && IsSecretName(key) {
+				typed[fieldName] = RedactedValue
+				continue
+			}
+			typed[fieldName] = redactSecrets(fieldName, fieldValue)
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = redactSecrets("", item)
+		}
+	}
+	return value
+}
+
+func IsSecretName(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(lowerName, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffEntities(t *testing.T) {
+	Convey("Testing DiffEntities", t, func() {
+		before := Service{Id: "1", Name: "name", Description: "old", AuditTrail: AuditTrail{LastUpdatedOn: 1}}
+
+		Convey("only changed fields should be returned, AuditTrail should be skipped", func() {
+			after := before
+			after.Description = "new"
+			after.AuditTrail.LastUpdatedOn = 2
+
+			changes, err := DiffEntities(before, after)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 1)
+			So(changes[0].Field, ShouldEqual, "description")
+			So(string(changes[0].OldValue), ShouldEqual, `"old"`)
+			So(string(changes[0].NewValue), ShouldEqual, `"new"`)
+		})
+
+		Convey("all fields of created entity should be returned without old values", func() {
+			changes, err := DiffEntities(nil, before)
+			So(err, ShouldBeNil)
+			So(changes, ShouldNotBeEmpty)
+			for _, change := range changes {
+				So(change.Field, ShouldNotEqual, "auditTrail")
+				So(change.OldValue, ShouldBeNil)
+			}
+		})
+
+		Convey("secret metadata values and binding data should be redacted", func() {
+			instance := Instance{
+				Metadata: []Metadata{{Id: "DB_PASSWORD", Value: "secret-value"}, {Id: "PLAN_ID", Value: "plan"}},
+				Bindings: []InstanceBindings{{Id: "2", Data: map[string]string{"access_token": "token-value", "host": "localhost"}}},
+			}
+
+			changes, err := DiffEntities(Instance{}, instance)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 2)
+			So(changes[0].Field, ShouldEqual, "bindings")
+			So(string(changes[0].NewValue), ShouldEqual, `[{"data":{"access_token":"[REDACTED]","host":"localhost"},"id":"2"}]`)
+			So(changes[1].Field, ShouldEqual, "metadata")
+			So(string(changes[1].NewValue), ShouldEqual, `[{"key":"DB_PASSWORD","value":"[REDACTED]"},{"key":"PLAN_ID","value":"plan"}]`)
+		})
+
+		Convey("short secret names should be redacted as well", func() {
+			instance := Instance{Metadata: []Metadata{{Id: "DB_PASS", Value: "pass-value"}, {Id: "ACCESS_KEY", Value: "key-value"}}}
+
+			changes, err := DiffEntities(Instance{}, instance)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 1)
+			So(string(changes[0].NewValue), ShouldEqual, `[{"key":"DB_PASS","value":"[REDACTED]"},{"key":"ACCESS_KEY","value":"[REDACTED]"}]`)
+		})
+	})
+}
+
+func TestIsSecretName(t *testing.T) {
+	Convey("Testing IsSecretName", t, func() {
+		for _, name := range []string{"DB_PASS", "ACCESS_KEY", "password", "DB_PWD", "api_key", "client_secret", "access_token"} {
+			So(IsSecretName(name), ShouldBeTrue)
+		}
+		for _, name := range []string{"host", "PLAN_ID", "name"} {
+			So(IsSecretName(name), ShouldBeFalse)
+		}
+	})
+}
+
+func TestAuditFilterMatches(t *testing.T) {
+	Convey("Testing AuditFilter Matches", t, func() {
+		entry := AuditEntry{Timestamp: 10, User: "admin", EntityType: EntityTypeService, EntityId: "1"}
+
+		Convey("empty filter should match every entry", func() {
+			So(AuditFilter{}.Matches(entry), ShouldBeTrue)
+		})
+
+		Convey("entry should match time range including its bounds", func() {
+			So(AuditFilter{Since: 10, Until: 10}.Matches(entry), ShouldBeTrue)
+			So(AuditFilter{Since: 11}.Matches(entry), ShouldBeFalse)
+			So(AuditFilter{Until: 9}.Matches(entry), ShouldBeFalse)
+		})
+
+		Convey("entry of other entity or user should not match", func() {
+			So(AuditFilter{EntityType: EntityTypeInstance}.Matches(entry), ShouldBeFalse)
+			So(AuditFilter{EntityId: "2"}.Matches(entry), ShouldBeFalse)
+			So(AuditFilter{User: "other"}.Matches(entry), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,99 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type BatchMode string
+
+const (
+	BatchModeAllOrNothing BatchMode = "ALL_OR_NOTHING"
+	BatchModeBestEffort   BatchMode = "BEST_EFFORT"
+)
+
+type BatchOperationType string
+
+const (
+	BatchOperationCreate BatchOperationType = "CREATE"
+	BatchOperationPatch  BatchOperationType = "PATCH"
+	BatchOperationDelete BatchOperationType = "DELETE"
+)
+
+const MaxBatchOperations = 100
+
+// BatchOperation Path is relative to api version prefix, e.g. /applications/${app}/instances.
+// Ids created by earlier operations can be used in Path and Body as ${ref}, literal "${" is escaped as "$${".
+type BatchOperation struct {
+	Ref  string             `json:"ref,omitempty"`
+	Op   BatchOperationType `json:"op"`
+	Path string             `json:"path"`
+	Body json.RawMessage    `json:"body,omitempty"`
+}
+
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchOperationResult struct {
+	Ref        string          `json:"ref,omitempty"`
+	Status     int             `json:"status"`
+	Id         string          `json:"id,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	RolledBack bool            `json:"rolledBack,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode    BatchMode              `json:"mode"`
+	Success bool                   `json:"success"`
+	Results []BatchOperationResult `json:"results"`
+}
+
+func (batch *BatchRequest) ValidateBatchRequest() error {
+	if batch.Mode == "" {
+		batch.Mode = BatchModeAllOrNothing
+	} else if batch.Mode != BatchModeAllOrNothing && batch.Mode != BatchModeBestEffort {
+		return fmt.Errorf("batch mode %q must match one of: %v", batch.Mode, []BatchMode{BatchModeAllOrNothing, BatchModeBestEffort})
+	}
+
+	if len(batch.Operations) == 0 {
+		return fmt.Errorf("batch operations list is empty!")
+	}
+	if len(batch.Operations) > MaxBatchOperations {
+		return fmt.Errorf("batch can not contain more than %d operations", MaxBatchOperations)
+	}
+
+	refs := map[string]bool{}
+	for i, operation := range batch.Operations {
+		switch operation.Op {
+		case BatchOperationCreate, BatchOperationPatch, BatchOperationDelete:
+		default:
+			return fmt.Errorf("operation %d: op %q must match one of: %v", i, operation.Op,
+				[]BatchOperationType{BatchOperationCreate, BatchOperationPatch, BatchOperationDelete})
+		}
+		if operation.Ref != "" {
+			if refs[operation.Ref] {
+				return fmt.Errorf("operation %d: ref %q must match unique name", i, operation.Ref)
+			}
+			refs[operation.Ref] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+type CascadeAction string
+
+const (
+	CascadeActionDelete CascadeAction = "DELETE"
+	CascadeActionUnbind CascadeAction = "UNBIND"
+)
+
+// CascadeDeleteItem is a single step of cascade delete. For UNBIND action Id points to instance
+// which keeps the binding and BindingId to removed instance it is bound to. Purge tells if object
+// is removed permanently instead of being moved to trash
+type CascadeDeleteItem struct {
+	Action     CascadeAction `json:"action"`
+	EntityType EntityType    `json:"entityType"`
+	Id         string        `json:"id"`
+	Name       string        `json:"name,omitempty"`
+	BindingId  string        `json:"bindingId,omitempty"`
+	Purge      bool          `json:"purge,omitempty"`
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Enum is implemented by typed strings which accept only values they declare
+type Enum interface {
+	EnumValues() []string
+}
+
+// unmarshalEnum decodes JSON string and checks it against values declared by enum - empty string means not set value
+func unmarshalEnum(data []byte, name string, enum Enum) (string, error) {
+	value := ""
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	if value == "" {
+		return value, nil
+	}
+	return value, checkEnumValue(name, value, enum)
+}
+
+// ValidateEnum checks that value of typed string is one of values it declares
+func ValidateEnum(name string, enum Enum) error {
+	return checkEnumValue(name, reflect.ValueOf(enum).String(), enum)
+}
+
+func checkEnumValue(name, value string, enum Enum) error {
+	for _, allowed := range enum.EnumValues() {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q must match one of: %v", name, value, enum.EnumValues())
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEnumUnmarshal(t *testing.T) {
+	Convey("Testing unmarshal of enum types", t, func() {
+		Convey("declared value should be accepted", func() {
+			service := Service{}
+			So(json.Unmarshal([]byte(`{"state":"OFFLINE"}`), &service), ShouldBeNil)
+			So(service.State, ShouldEqual, ServiceStateOffline)
+		})
+
+		Convey("missing and empty value should be accepted as not set", func() {
+			image := Image{}
+			So(json.Unmarshal([]byte(`{"type":""}`), &image), ShouldBeNil)
+			So(image.Type, ShouldBeEmpty)
+			So(image.BlobType, ShouldBeEmpty)
+		})
+
+		Convey("unknown value should be rejected with list of allowed ones", func() {
+			template := Template{}
+
+			err := json.Unmarshal([]byte(`{"state":"DONE"}`), &template)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `template state "DONE" must match one of`)
+			So(err.Error(), ShouldContainSubstring, string(TemplateStateUnavailable))
+		})
+
+		Convey("value of other JSON type should be rejected", func() {
+			instance := Instance{}
+			So(json.Unmarshal([]byte(`{"type":1}`), &instance), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Testing ValidateEnum", t, func() {
+		So(ValidateEnum("blob type", BlobTypeJar), ShouldBeNil)
+		So(ValidateEnum("blob type", BlobType("")), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,101 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type EntityType string
+
+const (
+	EntityTypeApplication EntityType = "APPLICATION"
+	EntityTypeImage       EntityType = "IMAGE"
+	EntityTypeInstance    EntityType = "INSTANCE"
+	EntityTypePlan        EntityType = "PLAN"
+	EntityTypeService     EntityType = "SERVICE"
+	EntityTypeTemplate    EntityType = "TEMPLATE"
+)
+
+var EntityTypes = []EntityType{
+	EntityTypeApplication,
+	EntityTypeImage,
+	EntityTypeInstance,
+	EntityTypePlan,
+	EntityTypeService,
+	EntityTypeTemplate,
+}
+
+func ParseEntityType(value string) (EntityType, error) {
+	for _, entityType := range EntityTypes {
+		if strings.EqualFold(value, string(entityType)) {
+			return entityType, nil
+		}
+	}
+	return "", fmt.Errorf("entity type %q must match one of: %v", value, EntityTypes)
+}
+
+const (
+	EventTypeState = "state"
+	EventTypeError = "error"
+)
+
+type StateChangeEvent struct {
+	EntityType EntityType `json:"entityType"`
+	Id         string     `json:"id"`
+	State      string     `json:"state"`
+	Index      uint64     `json:"index"`
+}
+
+type EventsFilter struct {
+	EntityTypes []EntityType
+	Id          string
+}
+
+func (filter EventsFilter) Matches(entityType EntityType, id string) bool {
+	if filter.Id != "" && filter.Id != id {
+		return false
+	}
+	if len(filter.EntityTypes) == 0 {
+		return true
+	}
+	for _, expectedType := range filter.EntityTypes {
+		if expectedType == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+type ChangeEventType string
+
+const (
+	ChangeEventTypeCreated ChangeEventType = "CREATED"
+	ChangeEventTypeUpdated ChangeEventType = "UPDATED"
+	ChangeEventTypeDeleted ChangeEventType = "DELETED"
+)
+
+// ChangeEvent describes single change of an entity. ParentId is set only for nested entities (service id for plans).
+// Fields contains names of changed top-level fields and is empty for CREATED and DELETED events.
+type ChangeEvent struct {
+	Type       ChangeEventType `json:"type"`
+	EntityType EntityType      `json:"entityType"`
+	Id         string          `json:"id"`
+	ParentId   string          `json:"parentId,omitempty"`
+	Fields     []string        `json:"fields"`
+	Index      uint64          `json:"index"`
+}
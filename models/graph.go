@@ -0,0 +1,259 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type GraphEdgeType string
+
+const (
+	GraphEdgeInstanceOf         GraphEdgeType = "INSTANCE_OF"
+	GraphEdgeBinding            GraphEdgeType = "BINDING"
+	GraphEdgeInstanceDependency GraphEdgeType = "INSTANCE_DEPENDENCY"
+	GraphEdgePlanDependency     GraphEdgeType = "PLAN_DEPENDENCY"
+)
+
+// GraphNodeRef identifies graph node - ids are unique only within single entity type
+type GraphNodeRef struct {
+	Type EntityType `json:"type"`
+	Id   string     `json:"id"`
+}
+
+func (ref GraphNodeRef) String() string {
+	return fmt.Sprintf("%s %q", strings.ToLower(string(ref.Type)), ref.Id)
+}
+
+type GraphNode struct {
+	GraphNodeRef
+	Name string `json:"name"`
+}
+
+// GraphEdge means that From depends on To
+type GraphEdge struct {
+	From GraphNodeRef  `json:"from"`
+	To   GraphNodeRef  `json:"to"`
+	Type GraphEdgeType `json:"type"`
+}
+
+// DependencyGraph is built from Instance.Bindings, Application.InstanceDependencies and ServicePlan.Dependencies.
+// StartOrder lists nodes which are not part of any cycle, dependencies before objects depending on them
+type DependencyGraph struct {
+	Nodes      []GraphNode      `json:"nodes"`
+	Edges      []GraphEdge      `json:"edges"`
+	StartOrder []GraphNodeRef   `json:"startOrder"`
+	Cycles     [][]GraphNodeRef `json:"cycles"`
+}
+
+func NewDependencyGraph(applications []Application, instances []Instance, services []Service) *DependencyGraph {
+	graph := &DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, application := range applications {
+		graph.Nodes = append(graph.Nodes, GraphNode{GraphNodeRef{EntityTypeApplication, application.Id}, application.Name})
+	}
+	for _, service := range services {
+		graph.Nodes = append(graph.Nodes, GraphNode{GraphNodeRef{EntityTypeService, service.Id}, service.Name})
+	}
+	for _, instance := range instances {
+		graph.Nodes = append(graph.Nodes, GraphNode{GraphNodeRef{EntityTypeInstance, instance.Id}, instance.Name})
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].GraphNodeRef.less(graph.Nodes[j].GraphNodeRef)
+	})
+
+	exists := make(map[GraphNodeRef]bool)
+	for _, node := range graph.Nodes {
+		exists[node.GraphNodeRef] = true
+	}
+	addEdge := func(from, to GraphNodeRef, edgeType GraphEdgeType) {
+		if !exists[to] {
+			return
+		}
+		edge := GraphEdge{From: from, To: to, Type: edgeType}
+		for _, existing := range graph.Edges {
+			if existing == edge {
+				return
+			}
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	for _, instance := range instances {
+		from := GraphNodeRef{EntityTypeInstance, instance.Id}
+		if instance.Type == InstanceTypeApplication {
+			addEdge(from, GraphNodeRef{EntityTypeApplication, instance.ClassId}, GraphEdgeInstanceOf)
+		} else {
+			addEdge(from, GraphNodeRef{EntityTypeService, instance.ClassId}, GraphEdgeInstanceOf)
+		}
+		for _, binding := range instance.Bindings {
+			addEdge(from, GraphNodeRef{EntityTypeInstance, binding.Id}, GraphEdgeBinding)
+		}
+	}
+	for _, application := range applications {
+		from := GraphNodeRef{EntityTypeApplication, application.Id}
+		for _, dependency := range application.InstanceDependencies {
+			addEdge(from, GraphNodeRef{EntityTypeInstance, dependency.Id}, GraphEdgeInstanceDependency)
+		}
+	}
+	for _, service := range services {
+		from := GraphNodeRef{EntityTypeService, service.Id}
+		for _, plan := range service.Plans {
+			for _, dependency := range plan.Dependencies {
+				addEdge(from, GraphNodeRef{EntityTypeService, dependency.ServiceId}, GraphEdgePlanDependency)
+			}
+		}
+	}
+
+	graph.analyze()
+	return graph
+}
+
+func (ref GraphNodeRef) less(other GraphNodeRef) bool {
+	if ref.Type != other.Type {
+		return ref.Type < other.Type
+	}
+	return ref.Id < other.Id
+}
+
+func (graph *DependencyGraph) HasNode(ref GraphNodeRef) bool {
+	for _, node := range graph.Nodes {
+		if node.GraphNodeRef == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// Rooted returns part of the graph which given node depends on, directly or indirectly
+func (graph *DependencyGraph) Rooted(root GraphNodeRef) *DependencyGraph {
+	dependencies := graph.dependencies()
+	reachable := map[GraphNodeRef]bool{root: true}
+	queue := []GraphNodeRef{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range dependencies[current] {
+			if !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	rooted := &DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for _, node := range graph.Nodes {
+		if reachable[node.GraphNodeRef] {
+			rooted.Nodes = append(rooted.Nodes, node)
+		}
+	}
+	for _, edge := range graph.Edges {
+		if reachable[edge.From] {
+			rooted.Edges = append(rooted.Edges, edge)
+		}
+	}
+	rooted.analyze()
+	return rooted
+}
+
+// CycleOf returns cycle which given node is part of or nil if there is none
+func (graph *DependencyGraph) CycleOf(ref GraphNodeRef) []GraphNodeRef {
+	for _, cycle := range graph.Cycles {
+		for _, node := range cycle {
+			if node == ref {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+func (graph *DependencyGraph) dependencies() map[GraphNodeRef][]GraphNodeRef {
+	result := make(map[GraphNodeRef][]GraphNodeRef)
+	for _, edge := range graph.Edges {
+		result[edge.From] = append(result[edge.From], edge.To)
+	}
+	return result
+}
+
+// analyze finds strongly connected components with Tarjan's algorithm. Components are found
+// dependencies first, so nodes which are not part of any cycle are collected in start order
+func (graph *DependencyGraph) analyze() {
+	dependencies := graph.dependencies()
+	index := 0
+	indexes := make(map[GraphNodeRef]int)
+	lowLinks := make(map[GraphNodeRef]int)
+	onStack := make(map[GraphNodeRef]bool)
+	stack := []GraphNodeRef{}
+
+	graph.StartOrder = []GraphNodeRef{}
+	graph.Cycles = [][]GraphNodeRef{}
+
+	var connect func(node GraphNodeRef)
+	connect = func(node GraphNodeRef) {
+		indexes[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		selfDependent := false
+		for _, next := range dependencies[node] {
+			if next == node {
+				selfDependent = true
+			}
+			if _, visited := indexes[next]; !visited {
+				connect(next)
+				if lowLinks[next] < lowLinks[node] {
+					lowLinks[node] = lowLinks[next]
+				}
+			} else if onStack[next] && indexes[next] < lowLinks[node] {
+				lowLinks[node] = indexes[next]
+			}
+		}
+
+		if lowLinks[node] != indexes[node] {
+			return
+		}
+
+		component := []GraphNodeRef{}
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+
+		if len(component) > 1 || selfDependent {
+			for i, j := 0, len(component)-1; i < j; i, j = i+1, j-1 {
+				component[i], component[j] = component[j], component[i]
+			}
+			graph.Cycles = append(graph.Cycles, component)
+		} else {
+			graph.StartOrder = append(graph.StartOrder, node)
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		if _, visited := indexes[node.GraphNodeRef]; !visited {
+			connect(node.GraphNodeRef)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	Convey("Test NewDependencyGraph", t, func() {
+		application := Application{Id: "app", Name: "app", InstanceDependencies: []InstanceDependency{{Id: "db"}}}
+		service := Service{Id: "postgres", Name: "postgres"}
+		dbInstance := Instance{Id: "db", Name: "db", Type: InstanceTypeService, ClassId: service.Id}
+		appInstance := Instance{Id: "app-1", Name: "app-1", Type: InstanceTypeApplication, ClassId: application.Id,
+			Bindings: []InstanceBindings{{Id: dbInstance.Id}}}
+
+		appRef := GraphNodeRef{EntityTypeApplication, application.Id}
+		serviceRef := GraphNodeRef{EntityTypeService, service.Id}
+		dbRef := GraphNodeRef{EntityTypeInstance, dbInstance.Id}
+		appInstanceRef := GraphNodeRef{EntityTypeInstance, appInstance.Id}
+
+		Convey("should order dependencies before objects depending on them", func() {
+			graph := NewDependencyGraph([]Application{application}, []Instance{appInstance, dbInstance}, []Service{service})
+
+			So(graph.Nodes, ShouldHaveLength, 4)
+			So(graph.Edges, ShouldHaveLength, 4)
+			So(graph.Cycles, ShouldBeEmpty)
+			So(graph.StartOrder, ShouldResemble, []GraphNodeRef{serviceRef, dbRef, appRef, appInstanceRef})
+		})
+
+		Convey("should detect cycle and leave its nodes out of start order", func() {
+			dbInstance.Bindings = []InstanceBindings{{Id: appInstance.Id}}
+			graph := NewDependencyGraph([]Application{application}, []Instance{appInstance, dbInstance}, []Service{service})
+
+			So(graph.Cycles, ShouldHaveLength, 1)
+			So(graph.Cycles[0], ShouldHaveLength, 3)
+			So(graph.CycleOf(appRef), ShouldNotBeNil)
+			So(graph.StartOrder, ShouldResemble, []GraphNodeRef{serviceRef})
+		})
+
+		Convey("should detect offering depending on itself", func() {
+			service.Plans = []ServicePlan{{Dependencies: []ServiceDependency{{ServiceId: service.Id}}}}
+			graph := NewDependencyGraph(nil, nil, []Service{service})
+
+			So(graph.Cycles, ShouldResemble, [][]GraphNodeRef{{serviceRef}})
+		})
+
+		Convey("rooted graph should contain only dependencies of the root", func() {
+			graph := NewDependencyGraph([]Application{application}, []Instance{appInstance, dbInstance}, []Service{service}).Rooted(dbRef)
+
+			So(graph.Nodes, ShouldHaveLength, 2)
+			So(graph.StartOrder, ShouldResemble, []GraphNodeRef{serviceRef, dbRef})
+		})
+	})
+}
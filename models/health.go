@@ -0,0 +1,53 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+// Readiness tells if catalog can serve requests - etcd is reachable and directory layout of organization exists
+type Readiness struct {
+	Ready       bool     `json:"ready"`
+	Message     string   `json:"message,omitempty"`
+	MissingDirs []string `json:"missingDirs,omitempty"`
+}
+
+type HealthDetails struct {
+	Healthy     bool               `json:"healthy"`
+	Members     []EtcdMemberHealth `json:"members"`
+	RoundTrip   EtcdRoundTrip      `json:"roundTrip"`
+	OpenWatches int64              `json:"openWatches"`
+	Build       BuildInfo          `json:"build"`
+	Errors      []string           `json:"errors,omitempty"`
+}
+
+type EtcdMemberHealth struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	ClientURLs []string `json:"clientURLs"`
+	Healthy    bool     `json:"healthy"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// EtcdRoundTrip is latency in milliseconds of writing probe key to etcd and reading it back
+type EtcdRoundTrip struct {
+	WriteMs float64 `json:"writeMs"`
+	ReadMs  float64 `json:"readMs"`
+}
+
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
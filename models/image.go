@@ -15,7 +15,11 @@
  */
 package models
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 const USER_DEFINED_APPLICATION_IMAGE_PREFIX = "app_"
 const USER_DEFINED_OFFERING_IMAGE_PREFIX = "svc_"
@@ -44,6 +48,25 @@ const (
 	ImageTypePython34 ImageType = "PYTHON3.4"
 )
 
+func (ImageType) EnumValues() []string {
+	return []string{
+		string(ImageTypeJava),
+		string(ImageTypeGo),
+		string(ImageTypeNodeJs),
+		string(ImageTypePython27),
+		string(ImageTypePython34),
+	}
+}
+
+func (imageType *ImageType) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "image type", *imageType)
+	if err != nil {
+		return err
+	}
+	*imageType = ImageType(value)
+	return nil
+}
+
 type BlobType string
 
 const (
@@ -52,6 +75,23 @@ const (
 	BlobTypeExec  BlobType = "EXEC"
 )
 
+func (BlobType) EnumValues() []string {
+	return []string{
+		string(BlobTypeTarGz),
+		string(BlobTypeJar),
+		string(BlobTypeExec),
+	}
+}
+
+func (blobType *BlobType) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "blob type", *blobType)
+	if err != nil {
+		return err
+	}
+	*blobType = BlobType(value)
+	return nil
+}
+
 type ImageState string
 
 const (
@@ -63,6 +103,45 @@ const (
 	ImageStateRemoving  ImageState = "REMOVING"
 )
 
+func (ImageState) EnumValues() []string {
+	return []string{
+		string(ImageStateBuilding),
+		string(ImageStateError),
+		string(ImageStatePending),
+		string(ImageStateReady),
+		string(ImageStateRequested),
+		string(ImageStateRemoving),
+	}
+}
+
+func (state *ImageState) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "image state", *state)
+	if err != nil {
+		return err
+	}
+	*state = ImageState(value)
+	return nil
+}
+
+// ValidateImageStructCreate checks image of application (id app_<applicationId>) or user defined offering (id svc_<offeringId>)
+func (image *Image) ValidateImageStructCreate() error {
+	if image.Id == "" {
+		return errors.New("image id is required")
+	}
+	if !IsApplicationInstance(image.Id) && !IsUserDefinedOffering(image.Id) {
+		return GetInvalidValueError("Id", image.Id, fmt.Errorf("image id has to start with %q or %q",
+			USER_DEFINED_APPLICATION_IMAGE_PREFIX, USER_DEFINED_OFFERING_IMAGE_PREFIX))
+	}
+	if image.Id == USER_DEFINED_APPLICATION_IMAGE_PREFIX || image.Id == USER_DEFINED_OFFERING_IMAGE_PREFIX {
+		return GetInvalidValueError("Id", image.Id, errors.New("image id has to contain application or offering id after prefix"))
+	}
+
+	if err := ValidateEnum("image type", image.Type); err != nil {
+		return err
+	}
+	return ValidateEnum("blob type", image.BlobType)
+}
+
 func IsApplicationInstance(imageId string) bool {
 	return strings.HasPrefix(imageId, USER_DEFINED_APPLICATION_IMAGE_PREFIX)
 }
@@ -0,0 +1,64 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateImageCreate(t *testing.T) {
+	Convey("Test ValidateImageStructCreate", t, func() {
+		image := &Image{Id: GenerateImageId("1"), Type: ImageTypeGo, BlobType: BlobTypeTarGz}
+
+		Convey("shouldn't return error for proper application image", func() {
+			So(image.ValidateImageStructCreate(), ShouldBeNil)
+		})
+
+		Convey("shouldn't return error for proper offering image", func() {
+			image.Id = ConstructImageIdForUserOffering("1")
+			So(image.ValidateImageStructCreate(), ShouldBeNil)
+		})
+
+		Convey("should return error when ID is not provided", func() {
+			image.Id = ""
+			So(image.ValidateImageStructCreate(), ShouldNotBeNil)
+		})
+
+		Convey("should return error when ID has no known prefix", func() {
+			image.Id = "1"
+			So(image.ValidateImageStructCreate(), ShouldNotBeNil)
+		})
+
+		Convey("should return error when ID has only prefix", func() {
+			image.Id = USER_DEFINED_OFFERING_IMAGE_PREFIX
+			So(image.ValidateImageStructCreate(), ShouldNotBeNil)
+		})
+
+		Convey("should return error for unknown type", func() {
+			image.Type = "RUBY"
+			err := image.ValidateImageStructCreate()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, string(ImageTypeJava))
+		})
+
+		Convey("should return error for unknown blob type", func() {
+			image.BlobType = "ZIP"
+			So(image.ValidateImageStructCreate(), ShouldNotBeNil)
+		})
+	})
+}
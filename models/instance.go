@@ -16,6 +16,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -65,6 +66,33 @@ const (
 	InstanceStateUnavailable     InstanceState = "UNAVAILABLE"
 )
 
+func (InstanceState) EnumValues() []string {
+	return []string{
+		string(InstanceStateRequested),
+		string(InstanceStateDeploying),
+		string(InstanceStateFailure),
+		string(InstanceStateStopped),
+		string(InstanceStateStartReq),
+		string(InstanceStateStarting),
+		string(InstanceStateRunning),
+		string(InstanceStateReconfiguration),
+		string(InstanceStateStopReq),
+		string(InstanceStateStopping),
+		string(InstanceStateDestroyReq),
+		string(InstanceStateDestroying),
+		string(InstanceStateUnavailable),
+	}
+}
+
+func (state *InstanceState) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "instance state", *state)
+	if err != nil {
+		return err
+	}
+	*state = InstanceState(value)
+	return nil
+}
+
 func (state InstanceState) String() string {
 	return string(state)
 }
@@ -87,6 +115,23 @@ const (
 	InstanceTypeServiceBroker InstanceType = "SERVICE_BROKER"
 )
 
+func (InstanceType) EnumValues() []string {
+	return []string{
+		string(InstanceTypeApplication),
+		string(InstanceTypeService),
+		string(InstanceTypeServiceBroker),
+	}
+}
+
+func (instanceType *InstanceType) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "instance type", *instanceType)
+	if err != nil {
+		return err
+	}
+	*instanceType = InstanceType(value)
+	return nil
+}
+
 func GetValueFromMetadata(metadatas []Metadata, key string) string {
 	for _, metadata := range metadatas {
 		if metadata.Id == key {
@@ -120,12 +165,23 @@ func (instance *Instance) ValidateInstanceStructCreate(instanceType InstanceType
 	//although it copies for loop from instances.go, in this case we don't query etcd before being sure request is proper
 	//in most cases bindings array will be small so no issue with performance should happen here
 	for _, binding := range instance.Bindings {
-		for k := range binding.Data {
-			if err = CheckIfMatchingRegexp(k, RegexpProperSystemEnvName); err != nil {
-				return GetInvalidValueError("Data", k, err)
-			}
+		if err = binding.ValidateInstanceBindingStruct(); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+func (binding *InstanceBindings) ValidateInstanceBindingStruct() error {
+	if binding.Id == "" {
+		return errors.New("binding id can not be empty")
+	}
+
+	for k := range binding.Data {
+		if err := CheckIfMatchingRegexp(k, RegexpProperSystemEnvName); err != nil {
+			return GetInvalidValueError("Data", k, err)
+		}
+	}
+	return nil
+}
@@ -41,6 +41,7 @@ type Patch struct {
 	Value     *json.RawMessage `json:"value"`
 	PrevValue json.RawMessage  `json:"prevValue,omitempty"`
 	Username  string           `json:"username"`
+	Reason    string           `json:"reason,omitempty"`
 }
 
 func ValidatePatchStructure(patch Patch) error {
@@ -61,3 +62,48 @@ type StateChange struct {
 	State string
 	Index uint64
 }
+
+const (
+	JsonPatchContentType  = "application/json-patch+json"
+	MergePatchContentType = "application/merge-patch+json"
+)
+
+type JsonPatchOperationType string
+
+const (
+	JsonPatchAdd     JsonPatchOperationType = "add"
+	JsonPatchRemove  JsonPatchOperationType = "remove"
+	JsonPatchReplace JsonPatchOperationType = "replace"
+	JsonPatchMove    JsonPatchOperationType = "move"
+	JsonPatchCopy    JsonPatchOperationType = "copy"
+	JsonPatchTest    JsonPatchOperationType = "test"
+)
+
+// JsonPatchOperation is a single operation of RFC 6902 JSON Patch document
+type JsonPatchOperation struct {
+	Op    JsonPatchOperationType `json:"op"`
+	Path  string                 `json:"path"`
+	From  string                 `json:"from,omitempty"`
+	Value *json.RawMessage       `json:"value,omitempty"`
+}
+
+// UnmarshalJSON keeps explicit null value (e.g. add of null clears the field), it can not be told apart
+// from missing value once it is decoded into nil pointer
+func (operation *JsonPatchOperation) UnmarshalJSON(data []byte) error {
+	type jsonPatchOperation JsonPatchOperation
+	if err := json.Unmarshal(data, (*jsonPatchOperation)(operation)); err != nil {
+		return err
+	}
+	if operation.Value != nil {
+		return nil
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	if value, ok := members["value"]; ok {
+		operation.Value = &value
+	}
+	return nil
+}
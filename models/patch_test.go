@@ -53,3 +53,21 @@ func TestValidatePatch(t *testing.T) {
 	})
 
 }
+
+func TestUnmarshalJsonPatchOperation(t *testing.T) {
+	Convey("Null value should be kept", t, func() {
+		operation := JsonPatchOperation{}
+		err := json.Unmarshal([]byte(`{"op": "add", "path": "/name", "value": null}`), &operation)
+
+		So(err, ShouldBeNil)
+		So(operation.Value, ShouldNotBeNil)
+		So(string(*operation.Value), ShouldEqual, "null")
+	})
+	Convey("Missing value should be nil", t, func() {
+		operation := JsonPatchOperation{}
+		err := json.Unmarshal([]byte(`{"op": "remove", "path": "/name"}`), &operation)
+
+		So(err, ShouldBeNil)
+		So(operation.Value, ShouldBeNil)
+	})
+}
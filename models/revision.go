@@ -0,0 +1,27 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import "encoding/json"
+
+// Revision is a snapshot of service, plan or application stored after every change - Entity is JSON representation
+// of the object. Revision numbers of an object are increasing, the oldest revisions are removed above limit.
+type Revision struct {
+	Revision  uint64          `json:"revision"`
+	CreatedOn int64           `json:"createdOn"`
+	Entity    json.RawMessage `json:"entity"`
+}
@@ -64,6 +64,23 @@ const (
 	ServiceStateOffline   ServiceState = "OFFLINE"
 )
 
+func (ServiceState) EnumValues() []string {
+	return []string{
+		string(ServiceStateDeploying),
+		string(ServiceStateReady),
+		string(ServiceStateOffline),
+	}
+}
+
+func (state *ServiceState) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "service state", *state)
+	if err != nil {
+		return err
+	}
+	*state = ServiceState(value)
+	return nil
+}
+
 func (servicePlan *ServicePlan) ValidateServicePlanStructCreate() error {
 	if servicePlan.Id != "" {
 		return GetIdFieldHasToBeEmptyError()
@@ -85,3 +102,12 @@ func (service *Service) ValidateServiceStructCreate() error {
 
 	return nil
 }
+
+// PlanDependencyReport lists problems found in dependencies of single plan
+type PlanDependencyReport struct {
+	ServiceId   string   `json:"serviceId"`
+	ServiceName string   `json:"serviceName"`
+	PlanId      string   `json:"planId"`
+	PlanName    string   `json:"planName"`
+	Issues      []string `json:"issues"`
+}
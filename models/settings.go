@@ -0,0 +1,48 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+type TemplateValidationMode string
+
+const (
+	TemplateValidationWarn    TemplateValidationMode = "WARN"
+	TemplateValidationEnforce TemplateValidationMode = "ENFORCE"
+)
+
+func (TemplateValidationMode) EnumValues() []string {
+	return []string{
+		string(TemplateValidationWarn),
+		string(TemplateValidationEnforce),
+	}
+}
+
+func (mode *TemplateValidationMode) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "template validation mode", *mode)
+	if err != nil {
+		return err
+	}
+	*mode = TemplateValidationMode(value)
+	return nil
+}
+
+// OrganizationSettings configures behaviour of catalog for single organization
+type OrganizationSettings struct {
+	TemplateValidation TemplateValidationMode `json:"templateValidation"`
+}
+
+func (settings *OrganizationSettings) ValidateOrganizationSettings() error {
+	return ValidateEnum("template validation mode", settings.TemplateValidation)
+}
@@ -16,6 +16,49 @@
 package models
 
 type StateStability struct {
-	Stable  bool   `json:"stable"`
-	Message string `json:"message"`
+	Stable   bool             `json:"stable"`
+	Message  string           `json:"message"`
+	Unstable []UnstableEntity `json:"unstable"`
+}
+
+// UnstableEntity is entity in transitional state together with time it entered that state
+type UnstableEntity struct {
+	EntityType      EntityType `json:"entityType"`
+	Id              string     `json:"id"`
+	Name            string     `json:"name,omitempty"`
+	Type            string     `json:"type,omitempty"`
+	ClassId         string     `json:"classId,omitempty"`
+	State           string     `json:"state"`
+	Since           int64      `json:"since"`
+	DurationSeconds int64      `json:"durationSeconds"`
+}
+
+// StabilityFilter narrows stability check. Type is compared with type of instances and images, ClassId with class
+// of instances - entities without given attribute never match.
+type StabilityFilter struct {
+	EntityTypes []EntityType
+	Type        string
+	ClassId     string
+}
+
+func (filter StabilityFilter) Includes(entityType EntityType) bool {
+	if len(filter.EntityTypes) == 0 {
+		return true
+	}
+	for _, expectedType := range filter.EntityTypes {
+		if expectedType == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter StabilityFilter) Matches(entityType EntityType, entityClassType, classId string) bool {
+	if !filter.Includes(entityType) {
+		return false
+	}
+	if filter.Type != "" && filter.Type != entityClassType {
+		return false
+	}
+	return filter.ClassId == "" || filter.ClassId == classId
 }
@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import "fmt"
+
+// StateMachine describes state transitions allowed for entities of given type
+type StateMachine struct {
+	EntityType  EntityType        `json:"entityType"`
+	States      []string          `json:"states"`
+	Transitions []StateTransition `json:"transitions"`
+}
+
+// StateTransition allows to change state to To from any of From states
+type StateTransition struct {
+	To   string   `json:"to"`
+	From []string `json:"from"`
+}
+
+// NextStates returns states to which entity in current state can be moved
+func (machine *StateMachine) NextStates(current string) []string {
+	result := []string{}
+	for _, transition := range machine.Transitions {
+		for _, source := range transition.From {
+			if source == current && !isStringInSlice(transition.To, result) {
+				result = append(result, transition.To)
+			}
+		}
+	}
+	return result
+}
+
+// ValidateStateMachine checks that transitions use only declared States
+func (machine *StateMachine) ValidateStateMachine() error {
+	for _, transition := range machine.Transitions {
+		if !isStringInSlice(transition.To, machine.States) {
+			return fmt.Errorf("%s state machine: target state %q must match one of: %v", machine.EntityType, transition.To, machine.States)
+		}
+		if len(transition.From) == 0 {
+			return fmt.Errorf("%s state machine: transition to %s has no source states", machine.EntityType, transition.To)
+		}
+		for _, source := range transition.From {
+			if !isStringInSlice(source, machine.States) {
+				return fmt.Errorf("%s state machine: source state %q must match one of: %v", machine.EntityType, source, machine.States)
+			}
+		}
+	}
+	return nil
+}
+
+func isStringInSlice(value string, slice []string) bool {
+	for _, element := range slice {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
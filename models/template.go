@@ -29,9 +29,42 @@ const (
 	TemplateStateUnavailable TemplateState = "UNAVAILABLE"
 )
 
+func (TemplateState) EnumValues() []string {
+	return []string{
+		string(TemplateStateInProgress),
+		string(TemplateStateReady),
+		string(TemplateStateUnavailable),
+	}
+}
+
+func (state *TemplateState) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalEnum(data, "template state", *state)
+	if err != nil {
+		return err
+	}
+	*state = TemplateState(value)
+	return nil
+}
+
 func (template *Template) ValidateTemplateStructCreate() error {
 	if template.Id != "" {
 		return GetIdFieldHasToBeEmptyError()
 	}
 	return nil
 }
+
+// TemplateWithUsage is template listed together with services and applications using it
+type TemplateWithUsage struct {
+	Template
+	UsedBy TemplateUsage `json:"usedBy"`
+}
+
+type TemplateUsage struct {
+	Services     []EntityReference `json:"services"`
+	Applications []EntityReference `json:"applications"`
+}
+
+type EntityReference struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,29 @@
+/**
+ * Copyright (c) 2017 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import "encoding/json"
+
+// TrashEntry keeps deleted object until it is restored or purged - Entity is JSON representation of the object
+type TrashEntry struct {
+	Id                string          `json:"id"`
+	EntityType        EntityType      `json:"entityType"`
+	DeletedOn         int64           `json:"deletedOn"`
+	DeletedBy         string          `json:"deletedBy"`
+	DeletedOnBehalfOf string          `json:"deletedOnBehalfOf,omitempty"`
+	PurgeOn           int64           `json:"purgeOn"`
+	Entity            json.RawMessage `json:"entity"`
+}